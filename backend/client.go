@@ -2,37 +2,80 @@ package main
 
 import (
 	"backend/gol"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
 	"go.uber.org/zap"
 )
 
+// Id of the game used when a request doesn't name one
 var GameOfLifeId = "gol"
 
+// Visibility query matching every running game
+const runningGamesQuery = "WorkflowType = 'GameOfLife' AND ExecutionStatus = 'Running'"
+
+// How long a started game counts against the cap before it shows up in visibility
+var startVisibilityGrace = 10 * time.Second
+
+// How long a query keeps retrying while a game rolls over to its next run
+var queryRolloverTimeout = 3 * time.Second
+
 type TemporalClientInterface interface {
 	Close() error
 	RunWorker() error
+	Health(w http.ResponseWriter, r *http.Request)
 	GetState(w http.ResponseWriter, r *http.Request)
+	WebSocket(limiter *RateLimiter) http.HandlerFunc
 	SendSignal(w http.ResponseWriter, r *http.Request)
+	ListSignals(w http.ResponseWriter, r *http.Request)
 	StartGameOfLife(w http.ResponseWriter, r *http.Request)
+	DescribeGame(w http.ResponseWriter, r *http.Request)
+	Metrics(w http.ResponseWriter, r *http.Request)
+	HistorySize(w http.ResponseWriter, r *http.Request)
+	Render(w http.ResponseWriter, r *http.Request)
+	Scrollback(w http.ResponseWriter, r *http.Request)
+	GetArchive(w http.ResponseWriter, r *http.Request)
+	GetStats(w http.ResponseWriter, r *http.Request)
+	Snapshot(w http.ResponseWriter, r *http.Request)
+	Debug(w http.ResponseWriter, r *http.Request)
+	Resync(w http.ResponseWriter, r *http.Request)
+	GamesHistory(w http.ResponseWriter, r *http.Request)
+	Restore(w http.ResponseWriter, r *http.Request)
+	Scenario(w http.ResponseWriter, r *http.Request)
+	Pause(w http.ResponseWriter, r *http.Request)
+	Resume(w http.ResponseWriter, r *http.Request)
+	Record(w http.ResponseWriter, r *http.Request)
 }
 
 type TemporalClient struct {
 	client.Client
-	temporalHost string
-	taskQueue    string
-	worker       *worker.Worker
-	done         chan any
+	temporalHost  string
+	taskQueue     string
+	maxActivities int
+	worker        *worker.Worker
+	done          chan any
+
+	// Serializes the running games check with the start so concurrent starts can't both slip past the cap
+	startMu      sync.Mutex
+	recentStarts map[string]time.Time
 }
 
 type TemporalLogger struct {
@@ -46,41 +89,30 @@ func (l TemporalLogger) Error(msg string, keyvals ...any) {
 	l.Logger.Error(msg, zap.Any("keyvals", keyvals))
 }
 
-func NewTemporalClient(hostPort string, taskQueue string) (TemporalClientInterface, error) {
+func NewTemporalClient(hostPort string, taskQueue string, maxActivities int) (TemporalClientInterface, error) {
 	temporalClient, err := client.Dial(client.Options{
 		HostPort: hostPort,
 		Logger:   TemporalLogger{zap.NewNop()},
+		// The worker shares the client, so its workflows and activities see the trace ids too
+		ContextPropagators: []workflow.ContextPropagator{gol.TracePropagator{}},
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Populate the state streams map with a channel for each workflow Id currently running
-	workflows, err := temporalClient.ListWorkflow(context.Background(), &workflowservice.ListWorkflowExecutionsRequest{
-		Query: "WorkflowType = 'GameOfLife' AND ExecutionStatus = 'Running'",
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if len(workflows.Executions) > 0 {
-		gol.StateStream = make(chan gol.StateChange)
-	} else {
-		gol.StateStream = nil
-	}
-
 	return &TemporalClient{
-		Client:       temporalClient,
-		temporalHost: hostPort,
-		taskQueue:    taskQueue,
-		worker:       nil,
-		done:         make(chan any),
+		Client:        temporalClient,
+		temporalHost:  hostPort,
+		taskQueue:     taskQueue,
+		maxActivities: maxActivities,
+		worker:        nil,
+		done:          make(chan any),
+		recentStarts:  make(map[string]time.Time),
 	}, nil
 }
 
 // Close closes the temporal client by stopping the worker and closing the client
 func (c *TemporalClient) Close() error {
-	c.Client.Close()
 	if c.worker != nil {
 		(*c.worker).Stop()
 	}
@@ -92,11 +124,14 @@ func (c *TemporalClient) Close() error {
 func (c *TemporalClient) RunWorker() error {
 	// Create a new worker
 	w := worker.New(c.Client, c.taskQueue, worker.Options{
-		MaxConcurrentActivityExecutionSize: 1000,
+		MaxConcurrentActivityExecutionSize: c.maxActivities,
 	})
 
+	c.worker = &w
+
 	// Register the workflows
 	w.RegisterWorkflow(gol.GameOfLife)
+	w.RegisterWorkflow(gol.RecordRunWorkflow)
 
 	// Register the activities
 	w.RegisterActivity(gol.AmInstance)
@@ -111,139 +146,949 @@ func (c *TemporalClient) RunWorker() error {
 	return nil
 }
 
+// gameId returns the game named in the path, or the shared game when there is none
+func gameId(r *http.Request) string {
+	if id := r.PathValue("id"); id != "" {
+		return id
+	}
+	return GameOfLifeId
+}
+
+// startWithinCap starts the game unless the running games are already at the cap
+// Restarting a running id replaces that game so it doesn't count as a new one
+// Returns the http status to respond with when starting fails
+func (c *TemporalClient) startWithinCap(ctx context.Context, options client.StartWorkflowOptions, input gol.GameOfLifeInput) (client.WorkflowRun, int, error) {
+	c.startMu.Lock()
+	defer c.startMu.Unlock()
+
+	workflows, err := c.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+		PageSize: int32(config.MaxGames + 1),
+		Query:    runningGamesQuery,
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	// Visibility lags behind starts, so include games this client started recently
+	running := make(map[string]bool)
+	for _, execution := range workflows.Executions {
+		running[execution.Execution.WorkflowId] = true
+	}
+	for id, startedAt := range c.recentStarts {
+		if time.Since(startedAt) > startVisibilityGrace {
+			delete(c.recentStarts, id)
+			continue
+		}
+		running[id] = true
+	}
+
+	if !running[options.ID] && len(running) >= config.MaxGames {
+		return nil, http.StatusTooManyRequests, fmt.Errorf("too many games running, the limit is %d", config.MaxGames)
+	}
+
+	run, err := c.ExecuteWorkflow(ctx, options, gol.GameOfLife, input)
+	if err != nil {
+		var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+		if errors.As(err, &alreadyStarted) {
+			return nil, http.StatusConflict, fmt.Errorf("game %s is already running", options.ID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	c.recentStarts[options.ID] = time.Now()
+	return run, http.StatusOK, nil
+}
+
 /* --------------------------- Frontend Endpoints --------------------------- */
 
-// GetState subscribes to the state stream and sends the state to the client via SSE
-func (c *TemporalClient) GetState(w http.ResponseWriter, r *http.Request) {
+// Health reports whether the backend can reach the temporal server
+func (c *TemporalClient) Health(w http.ResponseWriter, r *http.Request) {
+
+	// Keep the check short so load balancers don't hang on an unreachable server
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
 
-	if gol.StateStream == nil || gol.GolBoard == nil {
-		http.Error(w, "Game not ready", http.StatusNotFound)
+	if _, err := c.CheckHealth(ctx, &client.CheckHealthRequest{}); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"temporal": "unavailable", "error": err.Error()})
 		return
 	}
 
-	// Set SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
+	writeJSON(w, http.StatusOK, map[string]string{"temporal": "ok"})
+}
+
+// queryAcrossRollover queries the game's current run, retrying with backoff while it continues as new
+// Closing the old run and starting the next isn't atomic, so a query in between can fail or find nothing
+// Games that don't exist at all fail straight away with the serviceerror.NotFound
+func (c *TemporalClient) queryAcrossRollover(ctx context.Context, id string, queryType string) (converter.EncodedValue, error) {
+	deadline := time.Now().Add(queryRolloverTimeout)
+	backoff := 50 * time.Millisecond
+	for {
+		value, err := c.QueryWorkflow(ctx, id, "", queryType)
+		if err == nil || time.Now().Add(backoff).After(deadline) || !c.rollingOver(ctx, id, err) {
+			return value, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Second)
+	}
+}
+
+// rollingOver reports whether a failed query is worth retrying because the game is between runs
+func (c *TemporalClient) rollingOver(ctx context.Context, id string, err error) bool {
+	var notFound *serviceerror.NotFound
+	var notReady *serviceerror.WorkflowNotReady
+	var queryFailed *serviceerror.QueryFailed
+	if !errors.As(err, &notFound) && !errors.As(err, &notReady) && !errors.As(err, &queryFailed) {
+		return false
+	}
+
+	// A game that can't be described is genuinely missing rather than rolling over
+	description, err := c.DescribeWorkflowExecution(ctx, id, "")
+	if err != nil {
+		return false
+	}
+	switch description.WorkflowExecutionInfo.Status {
+	case enums.WORKFLOW_EXECUTION_STATUS_RUNNING, enums.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW:
+		return true
+	}
+	return false
+}
+
+// subscribeToGame subscribes to the game's state stream and returns its current board
+// Subscribing happens before querying the board so no frames are missed in between
+// A fresh board published by this process saves the query (and the replay it can cause)
+func (c *TemporalClient) subscribeToGame(ctx context.Context, id string) (gol.StateChange, <-chan gol.StateChange, func(), error) {
+	stateChange, cached, states, unsubscribe := gol.StateStream.SubscribeWithSnapshot(id)
+	if cached {
+		return stateChange, states, unsubscribe, nil
+	}
+
+	// Get the board from the workflow
+	stateChangeEnvelope, err := c.queryAcrossRollover(ctx, id, "board")
+	if err == nil {
+		err = stateChangeEnvelope.Get(&stateChange)
+	}
+	if err != nil {
+		unsubscribe()
+		return gol.StateChange{}, nil, nil, err
+	}
+	return stateChange, states, unsubscribe, nil
+}
+
+// frameAfter returns the frame to send a client whose board includes the frames up to seq, false when it
+// has nothing new. A frame merged with frames the board already includes (the hub merges the frames a
+// subscriber hasn't taken yet, e.g. while the initial board was queried) can't be applied on top of it,
+// the client gets the current board as a full frame instead.
+func (c *TemporalClient) frameAfter(ctx context.Context, id string, seq int, state gol.StateChange) (gol.StateChange, bool, error) {
+	if state.Seq <= seq {
+		return gol.StateChange{}, false, nil
+	}
+	if state.Full || state.FirstSeq() > seq {
+		return state, true, nil
+	}
+
+	var board gol.StateChange
+	boardEnvelope, err := c.queryAcrossRollover(ctx, id, "board")
+	if err == nil {
+		err = boardEnvelope.Get(&board)
+	}
+	if err != nil {
+		return gol.StateChange{}, false, err
+	}
+	board.Ended = state.Ended
+	return board, true, nil
+}
+
+// GetState subscribes to the state stream and sends the state to the client via SSE
+// Frames are JSON StateChanges by default, ?encoding=compact sends gol.CompactStateChange frames instead
+// With Accept: application/x-ndjson the frames are sent one per line without the SSE framing
+func (c *TemporalClient) GetState(w http.ResponseWriter, r *http.Request) {
 
 	// Make sure the writer can flush
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+	if _, ok := w.(http.Flusher); !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming unsupported!")
 		return
 	}
 
 	ctx := r.Context()
 
-	// Get the board from the workflow
-	stateChangeEnvelope, err := c.QueryWorkflow(ctx, GameOfLifeId, "", "board")
+	marshalState := func(state gol.StateChange) ([]byte, error) {
+		return json.Marshal(state)
+	}
+	width := gol.DefaultBoardWidth
+	if r.URL.Query().Get("encoding") == "compact" {
+		marshalState = func(state gol.StateChange) ([]byte, error) {
+			return json.Marshal(state.Compact(width))
+		}
+	}
+
+	id := gameId(r)
+	stateChange, states, unsubscribe, err := c.subscribeToGame(ctx, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	defer unsubscribe()
 
-	// Decode the result into your StateChange struct
-	var stateChange gol.StateChange
-	if err := stateChangeEnvelope.Get(&stateChange); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	// The initial board is a full frame, its size doesn't change for the rest of the game
+	if stateChange.Cols > 0 {
+		width = stateChange.Cols
 	}
-	stateChangeJson, err := json.Marshal(stateChange)
+
+	stateChangeJson, err := marshalState(stateChange)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	ticker := time.NewTicker(10 * time.Second)
+	// Set SSE headers (only once nothing can fail with a JSON error)
+	stream, done := newSSEStream(w, acceptsNDJSON(r))
+	defer done()
+
+	ticker := time.NewTicker(config.SSEPingInterval)
 	defer ticker.Stop()
 
 	// Send the connection established event
-	_, err = fmt.Fprintf(w, "event: connection_established\n\n")
-	if err != nil {
+	if err := stream.Event("connection_established"); err != nil {
 		return
 	}
-	flusher.Flush()
 
-	// Send the initial state because on initial connection we need the full object.
-	fmt.Fprintf(w, "data: %s\n\n", stateChangeJson)
-	flusher.Flush()
+	// Every frame is tagged with its Seq so a reconnecting client reports the last frame it applied
+	// Steps can't tell, the frames of signals (e.g. toggles) go out without advancing the step
+	lastSeq := -1
+	if lastEventId := r.Header.Get("Last-Event-ID"); lastEventId != "" {
+		if seq, err := strconv.Atoi(lastEventId); err == nil {
+			lastSeq = seq
+		}
+	}
+
+	switch {
+	case lastSeq < 0:
+		// Send the initial state because on initial connection we need the full object.
+		err = stream.Frame("", stateChange.Seq, stateChangeJson)
+	case lastSeq != stateChange.Seq:
+		// The client missed frames while disconnected so it has to reset to the full board
+		err = stream.Frame("snapshot", stateChange.Seq, stateChangeJson)
+	}
+	if err != nil {
+		return
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			_, err := fmt.Fprintf(w, "event: ping\n\n")
-			if err != nil {
+			if err := stream.Event("ping"); err != nil {
 				return
 			}
-			flusher.Flush()
 
-		case state := <-gol.StateStream:
+		case state := <-states:
 
-			json, err := json.Marshal(state)
+			// Skip what the client's board already includes
+			state, ok, err := c.frameAfter(ctx, id, stateChange.Seq, state)
 			if err != nil {
-				log.Printf("Error marshalling state: %v", err)
+				log.Printf("Error querying board of game %s: %v", id, err)
+				return
+			}
+			if !ok {
+				continue
+			}
+			stateChange = state
+
+			if !state.OnlyEnded() {
+				json, err := marshalState(state)
+				if err != nil {
+					log.Printf("Error marshalling state: %v", err)
+					continue
+				}
+
+				// Send event to client, a slow connection makes the hub fall back to keyframes for it
+				sent := time.Now()
+				if err := stream.Frame("", state.Seq, json); err != nil {
+					return
+				}
+				gol.StateStream.ObserveSend(id, states, time.Since(sent))
+			}
+
+			// The game's last message, tell the client why and close the stream
+			if state.Ended != "" {
+				sendGameEnded(stream, state, state.Seq)
+				return
+			}
+		}
+	}
+}
+
+// Payload of the game_ended event, the last event of a state stream
+type GameEndedEvent struct {
+	Reason string `json:"reason"` // One of gol.EndedMaxSteps, gol.EndedTerminated or gol.EndedExtinct
+	Step   int    `json:"step"`
+}
+
+// sendGameEnded sends the game_ended event for the game's last message, tagged with the stream's id for it
+func sendGameEnded(stream *sseStream, state gol.StateChange, id int) {
+	payload, err := json.Marshal(GameEndedEvent{Reason: state.Ended, Step: state.Step})
+	if err != nil {
+		log.Printf("Error marshalling game ended: %v", err)
+		return
+	}
+	stream.Frame("game_ended", id, payload)
+}
+
+// Frame of the stats stream, much lighter than a state change for charting a game
+type StatsFrame struct {
+	Step       int `json:"step"`
+	Population int `json:"population"`
+	Births     int `json:"births"`
+	Deaths     int `json:"deaths"`
+}
+
+// GetStats streams the population, births and deaths of each generation via SSE
+// Url is like /stats/{id}
+func (c *TemporalClient) GetStats(w http.ResponseWriter, r *http.Request) {
+
+	// Make sure the writer can flush
+	if _, ok := w.(http.Flusher); !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming unsupported!")
+		return
+	}
+
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	// Subscribe before querying the population so no frames are missed in between
+	states, unsubscribe := gol.StateStream.Subscribe(id)
+	defer unsubscribe()
+
+	populationEnvelope, err := c.QueryWorkflow(ctx, id, "", "population")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var population gol.PopulationStats
+	if err := populationEnvelope.Get(&population); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Set SSE headers (only once nothing can fail with a JSON error)
+	stream, done := newSSEStream(w, false)
+	defer done()
+
+	ticker := time.NewTicker(config.SSEPingInterval)
+	defer ticker.Stop()
+
+	// Start the chart from the current population
+	initial, _ := json.Marshal(StatsFrame{Step: population.Step, Population: population.Population})
+	if err := stream.Frame("", population.Step, initial); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := stream.Event("ping"); err != nil {
+				return
+			}
+
+		case state := <-states:
+			if state.Step < population.Step {
 				continue
 			}
+			if state.OnlyEnded() {
+				sendGameEnded(stream, state, state.Step)
+				return
+			}
 
-			// Send event to client
-			fmt.Fprintf(w, "data: %s\n\n", json)
-			flusher.Flush()
+			frame, err := json.Marshal(StatsFrame{
+				Step:       state.Step,
+				Population: state.Population,
+				Births:     state.Births,
+				Deaths:     state.Deaths,
+			})
+			if err != nil {
+				log.Printf("Error marshalling stats: %v", err)
+				continue
+			}
+			if err := stream.Frame("", state.Step, frame); err != nil {
+				return
+			}
+			if state.Ended != "" {
+				sendGameEnded(stream, state, state.Step)
+				return
+			}
 		}
 	}
 }
 
 // SendSignal sends a signal to the workflow
-// Url is like /signal/:signalName with the payload being the signal payload
+// Url is like /signal/{id}/{name} (or /signal/{name} for the default game) with the payload being the signal payload
+// Responds 404 when the game isn't running, so a signal is only reported sent once temporal took it
 func (c *TemporalClient) SendSignal(w http.ResponseWriter, r *http.Request) {
 
-	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(parts) < 2 {
-		http.Error(w, "missing signal name in path", http.StatusBadRequest)
+	signalName := r.PathValue("name")
+	if signalName == "" {
+		writeError(w, http.StatusBadRequest, "missing signal name in path")
 		return
 	}
-	signalName := parts[1]
 
-	var payload map[string]any
-	json.NewDecoder(r.Body).Decode(&payload)
+	// Unknown signals would sit in a buffer nobody reads, so they never reach the workflow
+	spec, ok := gol.Signals[signalName]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown signal %q, valid signals are: %s", signalName, strings.Join(gol.SignalNames(), ", ")))
+		return
+	}
+
+	// Only forward payloads that decode into the signal's expected type
+	payload, err := spec.DecodePayload(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	c.SignalWorkflow(r.Context(), GameOfLifeId, "", signalName, payload)
+	// A finished game can't take signals anymore, it reports not found like one that never existed
+	id := gameId(r)
+	if err := c.SignalWorkflow(r.Context(), id, "", signalName, payload); err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "Event sent"})
+}
+
+// ListSignals describes the signals /signal accepts, their payload fields and what they do
+// Url is like /signals, every game handles the same signals
+func (c *TemporalClient) ListSignals(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, gol.SignalDescriptors())
+}
+
+// Pause pauses the game, pausing a paused game does nothing
+// Url is like /pause/{id}
+func (c *TemporalClient) Pause(w http.ResponseWriter, r *http.Request) {
+	c.setPaused(w, r, true)
+}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Event sent"))
+// Resume resumes the game, resuming a running game does nothing
+// Url is like /resume/{id}
+func (c *TemporalClient) Resume(w http.ResponseWriter, r *http.Request) {
+	c.setPaused(w, r, false)
+}
+
+func (c *TemporalClient) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	id := r.PathValue("id")
+	ctx := r.Context()
+
+	// Skip the signal (and the history it adds) when the game is already in the wanted state
+	populationEnvelope, err := c.QueryWorkflow(ctx, id, "", "population")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var population gol.PopulationStats
+	if err := populationEnvelope.Get(&population); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if population.Paused != paused {
+		// setPaused rather than toggleStatus so a toggle landing in between can't invert it
+		err := c.SignalWorkflow(ctx, id, "", gol.SetPausedSignalName, gol.SetPausedSignal{Paused: paused})
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			// The game ended after the query
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "paused": paused})
+}
+
+// Identifies the game StartGameOfLife started, the client subscribes to /state/{id} with it
+type StartedGame struct {
+	Id    string `json:"id"`
+	RunId string `json:"runId"`
+	Step  int    `json:"step"`
+
+	// Carried by every frame of the game, the X-Trace-Id header of the start request or the run id
+	TraceId string `json:"traceId"`
+}
+
+// Optional body of /start
+type StartRequest struct {
+	InitialBoard gol.Board `json:"initialBoard"`          // Used verbatim instead of a random board, has to be the default board size
+	Wrap         bool      `json:"wrap,omitempty"`        // Wrap the board's edges around so gliders leaving one side come back on the other
+	SplitFlips   bool      `json:"splitFlips,omitempty"`  // Frames also carry the flipped cells split into born and died
+	RevealSteps  int       `json:"revealSteps,omitempty"` // Frames uncovering the board from its center before the game starts running
+}
+
+// Largest /start body, a 512x512 board of JSON booleans is about 1.4MB
+const maxStartBodyBytes = 4 << 20
+
+// decodeStartRequest turns the /start body into the workflow input, an empty body starts a random board
+func decodeStartRequest(r *http.Request) (gol.GameOfLifeInput, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxStartBodyBytes))
+	if err != nil {
+		return gol.GameOfLifeInput{}, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return gol.GameOfLifeInput{}, nil
+	}
+
+	var request StartRequest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		return gol.GameOfLifeInput{}, fmt.Errorf("invalid start request: %w", err)
+	}
+
+	if err := gol.ValidateRevealSteps(request.RevealSteps); err != nil {
+		return gol.GameOfLifeInput{}, err
+	}
+
+	input := gol.GameOfLifeInput{Wrap: request.Wrap, SplitFlips: request.SplitFlips, RevealSteps: request.RevealSteps}
+	if request.InitialBoard != nil {
+		rows, cols, err := request.InitialBoard.Dimensions()
+		if err != nil {
+			return gol.GameOfLifeInput{}, err
+		}
+		if err := gol.ValidateBoardSize(rows, cols); err != nil {
+			return gol.GameOfLifeInput{}, err
+		}
+		// The frontend draws a fixed size board
+		if rows != gol.DefaultBoardLength || cols != gol.DefaultBoardWidth {
+			return gol.GameOfLifeInput{}, fmt.Errorf("initial board must be %dx%d, got %dx%d", gol.DefaultBoardLength, gol.DefaultBoardWidth, rows, cols)
+		}
+		input.Board = request.InitialBoard.Pack()
+	}
+	return input, nil
 }
 
 // StartGameOfLife starts a new game of life workflow
+// Url is like /start?id={id}&wait=false&reuse=terminate, the id defaults to the shared game and wait to true
+// The body optionally carries a StartRequest
+// reuse decides what happens to a running game with the same id: reject (default, 409), terminate or allow-duplicate (join it)
 func (c *TemporalClient) StartGameOfLife(w http.ResponseWriter, r *http.Request) {
+	id := GameOfLifeId
+	if queryId := r.URL.Query().Get("id"); queryId != "" {
+		id = queryId
+	}
+
+	input, err := decodeStartRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.startGame(w, r, id, input)
+}
+
+// Snapshot returns everything needed to restore the game later (see gol.GameSnapshot)
+// Url is like /snapshot/{id}
+func (c *TemporalClient) Snapshot(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	snapshotEnvelope, err := c.queryAcrossRollover(r.Context(), id, "snapshot")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var snapshot gol.GameSnapshot
+	if err := snapshotEnvelope.Get(&snapshot); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// Debug responds with the game's workflow internals (see gol.DebugState)
+// Url is like /debug/{id}
+func (c *TemporalClient) Debug(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	debugEnvelope, err := c.queryAcrossRollover(r.Context(), id, "debug")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var debug gol.DebugState
+	if err := debugEnvelope.Get(&debug); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, debug)
+}
+
+// Resync responds with the game's current board as a full frame, for clients that fell out of sync
+// Url is like /resync/{id}
+// A client whose board no longer matches the stream (a frame's checksum differs from the one it
+// computes, see gol.StateChange) replaces its board with this one without reconnecting, and skips
+// the streamed frames up to the Seq it is at
+// The board comes from the workflow rather than the stream's cache, in case the cache is what's off
+func (c *TemporalClient) Resync(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	boardEnvelope, err := c.queryAcrossRollover(r.Context(), id, "board")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var stateChange gol.StateChange
+	if err := boardEnvelope.Get(&stateChange); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stateChange)
+}
+
+// Restore starts a game from a snapshot taken by /snapshot, it carries on from the snapshot's step
+// Url is like /restore?id={id} with the snapshot as the body, the id defaults to the snapshot's
+// Takes the same wait and reuse parameters as /start, restoring over the running game needs reuse=terminate
+func (c *TemporalClient) Restore(w http.ResponseWriter, r *http.Request) {
+	var snapshot gol.GameSnapshot
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxStartBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&snapshot); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid snapshot: %v", err))
+		return
+	}
+	if err := snapshot.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid snapshot: %v", err))
+		return
+	}
+
+	id := snapshot.Id
+	if queryId := r.URL.Query().Get("id"); queryId != "" {
+		id = queryId
+	}
+	if id == "" {
+		id = GameOfLifeId
+	}
+
+	c.startGame(w, r, id, snapshot.Input())
+}
+
+// startGame starts the game with the request's reuse policy (see StartGameOfLife)
+func (c *TemporalClient) startGame(w http.ResponseWriter, r *http.Request, id string, input gol.GameOfLifeInput) {
 	options := client.StartWorkflowOptions{
-		ID:                    GameOfLifeId,
-		TaskQueue:             c.taskQueue,
-		WorkflowIDReusePolicy: enums.WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING,
+		ID:        id,
+		TaskQueue: c.taskQueue,
+	}
+	switch reuse := r.URL.Query().Get("reuse"); reuse {
+	case "", "reject":
+		// A finished game's id can be reused, a running one is a conflict
+		options.WorkflowIDReusePolicy = enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE
+		options.WorkflowIDConflictPolicy = enums.WORKFLOW_ID_CONFLICT_POLICY_FAIL
+		options.WorkflowExecutionErrorWhenAlreadyStarted = true
+	case "terminate":
+		options.WorkflowIDReusePolicy = enums.WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING
+	case "allow-duplicate":
+		// A running game is joined rather than replaced
+		options.WorkflowIDReusePolicy = enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE
+		options.WorkflowIDConflictPolicy = enums.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown reuse policy %q, valid policies are: reject, terminate, allow-duplicate", reuse))
+		return
 	}
-	_, err := c.ExecuteWorkflow(r.Context(), options, gol.GameOfLife, gol.GameOfLifeInput{})
+
+	c.startAndWait(w, r, options, input)
+}
+
+// startAndWait starts the game with the options and waits for its first frame unless told not to
+func (c *TemporalClient) startAndWait(w http.ResponseWriter, r *http.Request, options client.StartWorkflowOptions, input gol.GameOfLifeInput) {
+	// Subscribe before starting so the first frame isn't missed
+	states, unsubscribe := gol.StateStream.Subscribe(options.ID)
+	defer unsubscribe()
+
+	// The game's frames carry the request's trace id, or the run id when it has none
+	ctx := r.Context()
+	traceId := r.Header.Get(gol.TraceHeader)
+	if traceId != "" {
+		ctx = gol.WithTraceId(ctx, traceId)
+	}
+
+	run, status, err := c.startWithinCap(ctx, options, input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, status, err.Error())
+		return
+	}
+	if traceId == "" {
+		traceId = run.GetRunID()
+	}
+	started := StartedGame{Id: run.GetID(), RunId: run.GetRunID(), Step: input.Step, TraceId: traceId}
+
+	// ?wait=false returns as soon as the workflow is started
+	// A paused game publishes nothing until it is resumed, so there is no first frame to wait for
+	if r.URL.Query().Get("wait") == "false" || input.Paused {
+		writeJSON(w, http.StatusOK, started)
 		return
 	}
 
-	// Wait for state stream to be initialized so we can immediately subscribe to it
-	timeout := time.After(30 * time.Second)
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
+	// A game the workflow rejects (e.g. an oversized board) fails before its first frame
+	// The request's context ends the wait once the handler returns
+	failed := make(chan error, 1)
+	go func() {
+		failed <- run.Get(r.Context(), nil)
+	}()
 
-	for {
-		select {
-		case <-timeout:
-			http.Error(w, "state stream not initialized in time", http.StatusInternalServerError)
+	// Wait for the first frame so the client can immediately subscribe to a running game
+	select {
+	case <-time.After(30 * time.Second):
+		writeError(w, http.StatusInternalServerError, "state stream not initialized in time")
+	case <-r.Context().Done():
+	case <-states:
+		writeJSON(w, http.StatusOK, started)
+	case err := <-failed:
+		var applicationErr *temporal.ApplicationError
+		switch {
+		case errors.As(err, &applicationErr) && applicationErr.Type() == gol.InvalidBoardErrorType:
+			writeError(w, http.StatusBadRequest, applicationErr.Message())
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		default:
+			// Ended before its first frame reached this process, there is nothing left to subscribe to
+			writeJSON(w, http.StatusOK, started)
+		}
+	}
+}
+
+// Game metadata returned by DescribeGame
+type GameMetadata struct {
+	Id         string        `json:"id"`
+	RunId      string        `json:"runId"`
+	Status     string        `json:"status"`
+	StartTime  time.Time     `json:"startTime"`
+	Step       int           `json:"step"`
+	Population int           `json:"population"`
+	Paused     bool          `json:"paused"`
+	TickTime   time.Duration `json:"tickTime"`
+
+	// Frame rates seen by this backend (see gol.GameStats), missing for games published elsewhere
+	AchievedFPS float64 `json:"achievedFPS,omitempty"`
+	TargetFPS   float64 `json:"targetFPS,omitempty"`
+}
+
+// DescribeGame returns a game's workflow metadata combined with its current population
+// Url is like /game/{id}
+func (c *TemporalClient) DescribeGame(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	ctx := r.Context()
+
+	description, err := c.DescribeWorkflowExecution(ctx, id, "")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
 			return
-		case <-ticker.C:
-			_, ok := <-gol.StateStream
-			if ok {
-				w.WriteHeader(http.StatusOK)
-				w.Write([]byte("Stream initialized"))
-				return
-			}
 		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	info := description.WorkflowExecutionInfo
+
+	// The described run may be rolling over, its population comes from whichever run is current by then
+	populationEnvelope, err := c.queryAcrossRollover(ctx, id, "population")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var population gol.PopulationStats
+	if err := populationEnvelope.Get(&population); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	metadata := GameMetadata{
+		Id:         id,
+		RunId:      info.Execution.RunId,
+		Status:     info.Status.String(),
+		StartTime:  info.StartTime.AsTime(),
+		Step:       population.Step,
+		Population: population.Population,
+		Paused:     population.Paused,
+		TickTime:   population.TickTime,
+	}
+	if stats, ok := gol.Metrics.Game(id); ok {
+		metadata.AchievedFPS, metadata.TargetFPS = stats.AchievedFPS, stats.TargetFPS
+	}
+	writeJSON(w, http.StatusOK, metadata)
+}
+
+// Scrollback returns the game's last frames delivered to this backend as a JSON array, oldest first
+// Applying them in reverse onto the current board walks it back one frame at a time
+// Url is like /scrollback/{id}?n=50, n defaults to the whole scrollback
+func (c *TemporalClient) Scrollback(w http.ResponseWriter, r *http.Request) {
+	n := gol.ScrollbackSize
+	if value := r.URL.Query().Get("n"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > gol.ScrollbackSize {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("n must be between 1 and %d, got %q", gol.ScrollbackSize, value))
+			return
+		}
+		n = parsed
+	}
+
+	writeJSON(w, http.StatusOK, gol.StateStream.Scrollback(r.PathValue("id"), n))
+}
+
+// GetArchive returns the final state of a finished game (see gol.ArchivedGame)
+// Url is like /archive/{id}
+func (c *TemporalClient) GetArchive(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	game, ok, err := gol.Archive.Get(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no archived game %s", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, game)
+}
+
+// History size of a game's current run returned by HistorySize
+type HistoryStats struct {
+	Id     string `json:"id"`
+	RunId  string `json:"runId"`
+	Events int64  `json:"events"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// HistorySize reports how big the current run's history is, which is what replaying the game costs
+// Only the current run counts, continue-as-new starts the next run with an empty history
+// Url is like /history/{id}
+func (c *TemporalClient) HistorySize(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	description, err := c.DescribeWorkflowExecution(r.Context(), id, "")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	info := description.WorkflowExecutionInfo
+
+	writeJSON(w, http.StatusOK, HistoryStats{
+		Id:     id,
+		RunId:  info.Execution.RunId,
+		Events: info.HistoryLength,
+		Bytes:  info.HistorySizeBytes,
+	})
+}
+
+// Escapes a prometheus label value
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Metrics serves the game and worker stats in the prometheus text format
+// Per game stats only cover the games published from this process
+func (c *TemporalClient) Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	// Running games come from visibility so they include games on other workers
+	count, err := c.CountWorkflow(r.Context(), &workflowservice.CountWorkflowExecutionsRequest{Query: runningGamesQuery})
+	if err != nil {
+		log.Printf("Error counting running games: %v", err)
+	} else {
+		fmt.Fprintf(w, "# HELP gol_running_games Number of running games.\n")
+		fmt.Fprintf(w, "# TYPE gol_running_games gauge\n")
+		fmt.Fprintf(w, "gol_running_games %d\n", count.Count)
+	}
+
+	games := gol.Metrics.Games()
+	fmt.Fprintf(w, "# HELP gol_game_steps_total Generations published per game.\n")
+	fmt.Fprintf(w, "# TYPE gol_game_steps_total counter\n")
+	for _, game := range games {
+		fmt.Fprintf(w, "gol_game_steps_total{game=\"%s\"} %d\n", labelEscaper.Replace(game.Id), game.Steps)
+	}
+	fmt.Fprintf(w, "# HELP gol_game_population Live cells per game.\n")
+	fmt.Fprintf(w, "# TYPE gol_game_population gauge\n")
+	for _, game := range games {
+		fmt.Fprintf(w, "gol_game_population{game=\"%s\"} %d\n", labelEscaper.Replace(game.Id), game.Population)
+	}
+	fmt.Fprintf(w, "# HELP gol_game_achieved_fps Smoothed rate generations were published at per game.\n")
+	fmt.Fprintf(w, "# TYPE gol_game_achieved_fps gauge\n")
+	for _, game := range games {
+		fmt.Fprintf(w, "gol_game_achieved_fps{game=\"%s\"} %g\n", labelEscaper.Replace(game.Id), game.AchievedFPS)
+	}
+	fmt.Fprintf(w, "# HELP gol_game_target_fps Rate generations are published at per game when nothing falls behind.\n")
+	fmt.Fprintf(w, "# TYPE gol_game_target_fps gauge\n")
+	for _, game := range games {
+		fmt.Fprintf(w, "gol_game_target_fps{game=\"%s\"} %g\n", labelEscaper.Replace(game.Id), game.TargetFPS)
+	}
+
+	subscribers, lagging, droppedFrames := gol.StateStream.Stats()
+	fmt.Fprintf(w, "# HELP gol_sse_subscribers Open state streams.\n")
+	fmt.Fprintf(w, "# TYPE gol_sse_subscribers gauge\n")
+	fmt.Fprintf(w, "gol_sse_subscribers %d\n", subscribers)
+	fmt.Fprintf(w, "# HELP gol_sse_lagging_subscribers State streams that fell too far behind and only get keyframes.\n")
+	fmt.Fprintf(w, "# TYPE gol_sse_lagging_subscribers gauge\n")
+	fmt.Fprintf(w, "gol_sse_lagging_subscribers %d\n", lagging)
+	fmt.Fprintf(w, "# HELP gol_dropped_frames_total Frames merged into a pending frame or skipped because a subscriber was busy.\n")
+	fmt.Fprintf(w, "# TYPE gol_dropped_frames_total counter\n")
+	fmt.Fprintf(w, "gol_dropped_frames_total %d\n", droppedFrames)
+
+	if breaker, ok := gol.StateStream.(*gol.BreakerPublisher); ok {
+		open, breakerDropped := breaker.Breaker()
+		openValue := 0
+		if open {
+			openValue = 1
+		}
+		fmt.Fprintf(w, "# HELP gol_breaker_open Whether publishing failed often enough that frames are being dropped.\n")
+		fmt.Fprintf(w, "# TYPE gol_breaker_open gauge\n")
+		fmt.Fprintf(w, "gol_breaker_open %d\n", openValue)
+		fmt.Fprintf(w, "# HELP gol_breaker_dropped_frames_total Frames dropped while the publish circuit breaker was open.\n")
+		fmt.Fprintf(w, "# TYPE gol_breaker_dropped_frames_total counter\n")
+		fmt.Fprintf(w, "gol_breaker_dropped_frames_total %d\n", breakerDropped)
 	}
 }
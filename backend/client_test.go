@@ -0,0 +1,1354 @@
+package main
+
+import (
+	"backend/gol"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeTemporal stands in for the temporal server, only the calls a test stubs are answered
+// The embedded client is nil, so any other call panics and points at the missing stub
+type fakeTemporal struct {
+	client.Client
+	checkHealth       func(ctx context.Context) error
+	queryWorkflow     func(ctx context.Context, id string, runId string, queryType string) (any, error)
+	signalWorkflow    func(ctx context.Context, id string, signalName string, arg any) error
+	describeWorkflow  func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error)
+	listWorkflow      func(ctx context.Context, query string) ([]string, error)
+	listExecutions    func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (*workflowservice.ListWorkflowExecutionsResponse, error) // Whole responses, for tests listing more than ids
+	executeWorkflow   func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error)
+	countWorkflow     func(ctx context.Context, query string) (int64, error)
+	terminateWorkflow func(ctx context.Context, id string, reason string) error
+}
+
+func (f *fakeTemporal) CountWorkflow(ctx context.Context, request *workflowservice.CountWorkflowExecutionsRequest) (*workflowservice.CountWorkflowExecutionsResponse, error) {
+	count, err := f.countWorkflow(ctx, request.Query)
+	if err != nil {
+		return nil, err
+	}
+	return &workflowservice.CountWorkflowExecutionsResponse{Count: count}, nil
+}
+
+func (f *fakeTemporal) ListWorkflow(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (*workflowservice.ListWorkflowExecutionsResponse, error) {
+	if f.listExecutions != nil {
+		return f.listExecutions(ctx, request)
+	}
+	ids, err := f.listWorkflow(ctx, request.Query)
+	if err != nil {
+		return nil, err
+	}
+	response := &workflowservice.ListWorkflowExecutionsResponse{}
+	for _, id := range ids {
+		response.Executions = append(response.Executions, &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: id, RunId: "run-" + id},
+		})
+	}
+	return response, nil
+}
+
+func (f *fakeTemporal) ExecuteWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow any, args ...any) (client.WorkflowRun, error) {
+	result, err := f.executeWorkflow(ctx, options, args)
+	if err != nil {
+		return nil, err
+	}
+	return fakeRun{id: options.ID, result: result}, nil
+}
+
+// fakeRun is a started workflow that has already finished with result, failed when result is an error
+type fakeRun struct {
+	client.WorkflowRun
+	id     string
+	result any
+}
+
+func (r fakeRun) GetID() string    { return r.id }
+func (r fakeRun) GetRunID() string { return "run-" + r.id }
+
+func (r fakeRun) Get(ctx context.Context, valuePtr any) error {
+	if err, ok := r.result.(error); ok {
+		return err
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(r.result)
+	if err != nil {
+		return err
+	}
+	return converter.GetDefaultDataConverter().FromPayload(payload, valuePtr)
+}
+
+func (f *fakeTemporal) DescribeWorkflowExecution(ctx context.Context, id string, runId string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+	return f.describeWorkflow(ctx, id)
+}
+
+func (f *fakeTemporal) TerminateWorkflow(ctx context.Context, id string, runId string, reason string, details ...any) error {
+	return f.terminateWorkflow(ctx, id, reason)
+}
+
+func (f *fakeTemporal) SignalWorkflow(ctx context.Context, id string, runId string, signalName string, arg any) error {
+	return f.signalWorkflow(ctx, id, signalName, arg)
+}
+
+func (f *fakeTemporal) CheckHealth(ctx context.Context, request *client.CheckHealthRequest) (*client.CheckHealthResponse, error) {
+	if err := f.checkHealth(ctx); err != nil {
+		return nil, err
+	}
+	return &client.CheckHealthResponse{}, nil
+}
+
+func (f *fakeTemporal) QueryWorkflow(ctx context.Context, id string, runId string, queryType string, args ...any) (converter.EncodedValue, error) {
+	result, err := f.queryWorkflow(ctx, id, runId, queryType)
+	if err != nil {
+		return nil, err
+	}
+	payloads, err := converter.GetDefaultDataConverter().ToPayloads(result)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewValue(payloads), nil
+}
+
+// newTestClient returns a TemporalClient talking to the fake
+func newTestClient(fake *fakeTemporal) *TemporalClient {
+	return &TemporalClient{Client: fake, taskQueue: "test", done: make(chan any), recentStarts: make(map[string]time.Time)}
+}
+
+// withConfig changes the backend's configuration for the rest of the test
+func withConfig(t *testing.T, change func(config *Config)) {
+	saved := config
+	t.Cleanup(func() { config = saved })
+	change(&config)
+}
+
+// decodeBody decodes the recorded JSON response into a map
+func decodeBody(t *testing.T, recorder *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response %q isn't JSON: %v", recorder.Body.String(), err)
+	}
+	return body
+}
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"reachable", nil, http.StatusOK, "ok"},
+		{"unreachable", errors.New("connection refused"), http.StatusServiceUnavailable, "unavailable"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(&fakeTemporal{checkHealth: func(ctx context.Context) error {
+				if _, ok := ctx.Deadline(); !ok {
+					t.Error("health check has no deadline")
+				}
+				return test.err
+			}})
+
+			recorder := httptest.NewRecorder()
+			c.Health(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+			if got := decodeBody(t, recorder)["temporal"]; got != test.wantBody {
+				t.Errorf("temporal = %v, want %s", got, test.wantBody)
+			}
+		})
+	}
+}
+
+// sseEvent is an event read off an SSE stream
+type sseEvent struct {
+	name string
+	id   string
+	data string
+}
+
+// readEvent reads the next event off the stream
+func readEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return event
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "event":
+			event.name = value
+		case "id":
+			event.id = value
+		case "data":
+			event.data = value
+		}
+	}
+}
+
+// readFrame reads events up to the next frame and applies it to the board like the frontend does
+func readFrame(t *testing.T, reader *bufio.Reader, board gol.Board) (sseEvent, gol.Board) {
+	t.Helper()
+	for {
+		event := readEvent(t, reader)
+		if event.data == "" {
+			continue
+		}
+		var frame gol.StateChange
+		if err := json.Unmarshal([]byte(event.data), &frame); err != nil {
+			t.Fatalf("frame %q isn't a state change: %v", event.data, err)
+		}
+		if fmt.Sprint(frame.Seq) != event.id {
+			t.Errorf("frame %d has id %s", frame.Seq, event.id)
+		}
+		if frame.Full {
+			board = make(gol.Board, frame.Rows)
+			for i := range board {
+				board[i] = make([]bool, frame.Cols)
+			}
+		}
+		board.Toggle(frame.Flipped)
+		return event, board
+	}
+}
+
+// scriptedGame is a game whose frames are published by the test
+// Its frames are the generations of a glider, with toggles in between that don't advance the step
+type scriptedGame struct {
+	id     string
+	frames []gol.StateChange // frames[seq-1] is frame seq
+	boards []gol.Board       // boards[seq] is the board after frame seq, boards[0] the seed
+}
+
+func newScriptedGame(id string, frames int) *scriptedGame {
+	board := make(gol.Board, 16)
+	for i := range board {
+		board[i] = make([]bool, 16)
+	}
+	board.Toggle([][2]int{{0, 1}, {1, 2}, {2, 0}, {2, 1}, {2, 2}})
+
+	game := &scriptedGame{id: id, boards: []gol.Board{board}}
+	step := 0
+	for seq := 1; seq <= frames; seq++ {
+		var next gol.Board
+		if seq%3 == 0 {
+			// A toggle
+			next = board.Clone()
+			next.Toggle([][2]int{{seq % 16, 15}})
+		} else {
+			next = gol.NextGeneration(board, gol.Rule{})
+			step++
+		}
+		game.frames = append(game.frames, gol.StateChange{Id: id, Seq: seq, Step: step, Flipped: gol.DiffFlipped(board, next)})
+		game.boards = append(game.boards, next)
+		board = next
+	}
+	return game
+}
+
+// publish publishes the frames with Seqs from through to
+func (g *scriptedGame) publish(from, to int) {
+	for seq := from; seq <= to; seq++ {
+		gol.StateStream.Publish(g.id, g.frames[seq-1])
+	}
+}
+
+// board answers the board query as of frame seq
+func (g *scriptedGame) board(seq int) gol.StateChange {
+	frame := g.frames[seq-1]
+	return gol.StateChange{
+		Id:      g.id,
+		Seq:     seq,
+		Step:    frame.Step,
+		Full:    true,
+		Rows:    16,
+		Cols:    16,
+		Flipped: g.boards[seq].LiveCells(),
+	}
+}
+
+func TestStateStreamReconnectsMidStream(t *testing.T) {
+	game := newScriptedGame("reconnect", 10)
+
+	// Each board query plays out what happens on the game while it is in flight
+	queries := []func() gol.StateChange{
+		// The first connection's frames arrive while its board is queried, the board already includes them
+		func() gol.StateChange { game.publish(1, 3); return game.board(3) },
+		// The reconnect's query races frame 9, which gets merged with the frames the board includes
+		func() gol.StateChange { game.publish(6, 8); defer game.publish(9, 9); return game.board(8) },
+		// So the merged frame is replaced by the board again
+		func() gol.StateChange { return game.board(9) },
+	}
+	fake := &fakeTemporal{queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+		if queryType != "board" || len(queries) == 0 {
+			return nil, fmt.Errorf("unexpected %s query", queryType)
+		}
+		query := queries[0]
+		queries = queries[1:]
+		return query(), nil
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	connect := func(lastEventId string) (*bufio.Reader, func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/state/reconnect", nil)
+		if lastEventId != "" {
+			request.Header.Set("Last-Event-ID", lastEventId)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bufio.NewReader(response.Body), func() { cancel(); response.Body.Close() }
+	}
+
+	reader, disconnect := connect("")
+	event, board := readFrame(t, reader, nil)
+	if event.id != "3" {
+		t.Fatalf("initial board is frame %s, want 3", event.id)
+	}
+	game.publish(4, 5)
+	for _, want := range []string{"4", "5"} {
+		if event, board = readFrame(t, reader, board); event.id != want {
+			t.Fatalf("got frame %s, want %s", event.id, want)
+		}
+	}
+	disconnect()
+
+	reader, disconnect = connect("5")
+	defer disconnect()
+	if event, board = readFrame(t, reader, board); event.name != "snapshot" || event.id != "8" {
+		t.Fatalf("reconnect got %s frame %s, want snapshot frame 8", event.name, event.id)
+	}
+	if event, board = readFrame(t, reader, board); event.id != "9" {
+		t.Fatalf("got frame %s, want the board as of frame 9", event.id)
+	}
+	game.publish(10, 10)
+	if event, board = readFrame(t, reader, board); event.id != "10" {
+		t.Fatalf("got frame %s, want 10", event.id)
+	}
+
+	if want := game.boards[10]; fmt.Sprint(board) != fmt.Sprint(want) {
+		t.Errorf("client ended on %v, want %v", board, want)
+	}
+}
+
+// requestKey tags a request's context to tell whether a call was made with it
+type requestKey struct{}
+
+func TestSendSignal(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantSignal any   // Payload forwarded to the game, nil when nothing may be sent
+		signalErr  error // What temporal answers the signal with
+	}{
+		{"unknown signal", "/signal/game/clear", "", http.StatusBadRequest, nil, nil},
+		{"malformed payload", "/signal/game/splatter", `{"x": "1"}`, http.StatusBadRequest, nil, nil},
+		{"valid payload", "/signal/game/splatter", `{"x": 1, "y": 2, "size": 3}`, http.StatusOK, &gol.SplatterSignal{X: 1, Y: 2, Size: 3}, nil},
+		{"game is missing", "/signal/missing/splatter", `{"x": 1, "y": 2, "size": 3}`, http.StatusNotFound, &gol.SplatterSignal{X: 1, Y: 2, Size: 3}, serviceerror.NewNotFound("workflow not found")},
+		{"signal fails", "/signal/game/splatter", `{"x": 1, "y": 2, "size": 3}`, http.StatusInternalServerError, &gol.SplatterSignal{X: 1, Y: 2, Size: 3}, errors.New("frontend unavailable")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sent any
+			fake := &fakeTemporal{signalWorkflow: func(ctx context.Context, id string, signalName string, arg any) error {
+				if test.wantSignal == nil {
+					t.Errorf("signal %s was sent to %s", signalName, id)
+				}
+				// The signal is sent with the request's context, so it's given up with the request
+				if ctx.Value(requestKey{}) != test.name {
+					t.Errorf("signal %s wasn't sent with the request's context", signalName)
+				}
+				sent = arg
+				return test.signalErr
+			}}
+			mux := http.NewServeMux()
+			handleEndpoints(newTestClient(fake), mux)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			mux.ServeHTTP(recorder, request.WithContext(context.WithValue(request.Context(), requestKey{}, test.name)))
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.wantStatus)
+			}
+			if test.wantSignal != nil && fmt.Sprint(sent) != fmt.Sprint(test.wantSignal) {
+				t.Errorf("sent %v, want %v", sent, test.wantSignal)
+			}
+			if test.signalErr != nil {
+				if message, _ := decodeBody(t, recorder)["error"].(string); message == "" {
+					t.Errorf("%s failed without an error message", test.path)
+				}
+			}
+			if test.wantStatus == http.StatusBadRequest {
+				// The valid signals are listed for an unknown one
+				if message, _ := decodeBody(t, recorder)["error"].(string); !strings.Contains(message, gol.SplatterSignalName) {
+					t.Errorf("error %q doesn't mention the splatter signal", message)
+				}
+			}
+		})
+	}
+}
+
+func TestStateStreamAcrossRollover(t *testing.T) {
+	game := newScriptedGame("rolling", 3)
+	for _, test := range []struct {
+		name       string
+		id         string
+		queryErr   error
+		wantStatus int
+		wantTries  int
+	}{
+		{"query lands between runs", "rolling", serviceerror.NewNotFound("workflow not found"), http.StatusOK, 2},
+		{"query fails on the closing run", "rolling", serviceerror.NewQueryFailed("workflow closed"), http.StatusOK, 2},
+		{"game is missing", "missing", serviceerror.NewNotFound("workflow not found"), http.StatusNotFound, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tries := 0
+			fake := &fakeTemporal{
+				// The first query fails, the next one reaches the new run
+				queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+					tries++
+					if tries == 1 {
+						return nil, test.queryErr
+					}
+					return game.board(3), nil
+				},
+				describeWorkflow: func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+					if id != "rolling" {
+						return nil, serviceerror.NewNotFound("workflow not found")
+					}
+					return describedRun(id, "run-1", enums.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW), nil
+				},
+			}
+			mux := http.NewServeMux()
+			handleEndpoints(newTestClient(fake), mux)
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			response, err := http.Get(server.URL + "/state/" + test.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != test.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, test.wantStatus)
+			}
+			if tries != test.wantTries {
+				t.Errorf("queried %d times, want %d", tries, test.wantTries)
+			}
+			if test.wantStatus != http.StatusOK {
+				return
+			}
+			if event, board := readFrame(t, bufio.NewReader(response.Body), nil); event.id != "3" || fmt.Sprint(board.LiveCells()) != fmt.Sprint(game.boards[3].LiveCells()) {
+				t.Errorf("stream started on frame %s, want the new run's board at frame 3", event.id)
+			}
+		})
+	}
+}
+
+// describedRun returns the description of a game's run with the given status
+func describedRun(id, runId string, status enums.WorkflowExecutionStatus) *workflowservice.DescribeWorkflowExecutionResponse {
+	return &workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: id, RunId: runId},
+			Status:    status,
+			StartTime: timestamppb.New(time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC)),
+		},
+	}
+}
+
+func TestDescribeGameDuringRollover(t *testing.T) {
+	// The game is described just as its run continues as new, the next run answers the query once it started
+	describes, queries := 0, 0
+	fake := &fakeTemporal{
+		describeWorkflow: func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+			describes++
+			return describedRun(id, "old", enums.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW), nil
+		},
+		queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+			queries++
+			switch {
+			case runId != "":
+				return nil, serviceerror.NewQueryFailed(fmt.Sprintf("run %s continued as new", runId))
+			case queries == 1:
+				return nil, serviceerror.NewWorkflowNotReady("next run hasn't started")
+			}
+			return gol.PopulationStats{Step: 50, Population: 12}, nil
+		},
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/game/rollover", nil)
+	request.SetPathValue("id", "rollover")
+	recorder := httptest.NewRecorder()
+	newTestClient(fake).DescribeGame(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+	body := decodeBody(t, recorder)
+	if body["step"] != float64(50) || body["population"] != float64(12) {
+		t.Errorf("described step %v with population %v, want step 50 with population 12", body["step"], body["population"])
+	}
+	// Once for the metadata and once to tell the failed query is a rollover
+	if describes != 2 {
+		t.Errorf("described the game %d times, want 2", describes)
+	}
+}
+
+func TestDescribeGameDescribesOnce(t *testing.T) {
+	describes := 0
+	fake := &fakeTemporal{
+		describeWorkflow: func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+			describes++
+			return describedRun(id, "current", enums.WORKFLOW_EXECUTION_STATUS_RUNNING), nil
+		},
+		queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+			return gol.PopulationStats{Step: 7, Population: 3, Paused: true}, nil
+		},
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/game/steady", nil)
+	request.SetPathValue("id", "steady")
+	recorder := httptest.NewRecorder()
+	newTestClient(fake).DescribeGame(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+	body := decodeBody(t, recorder)
+	if body["runId"] != "current" || body["status"] != "Running" || body["paused"] != true {
+		t.Errorf("described %v", body)
+	}
+	if describes != 1 {
+		t.Errorf("described the game %d times, want 1", describes)
+	}
+}
+
+func TestDescribeGameFrameRates(t *testing.T) {
+	// The metrics are shared by the whole process, a fresh id keeps repeated runs apart
+	id := fmt.Sprint("paced-", time.Now().UnixNano())
+	fake := &fakeTemporal{
+		describeWorkflow: func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+			return describedRun(id, "current", enums.WORKFLOW_EXECUTION_STATUS_RUNNING), nil
+		},
+		queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+			return gol.PopulationStats{Step: 2, Population: 3}, nil
+		},
+	}
+	describe := func() map[string]any {
+		request := httptest.NewRequest(http.MethodGet, "/game/"+id, nil)
+		request.SetPathValue("id", id)
+		recorder := httptest.NewRecorder()
+		newTestClient(fake).DescribeGame(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+		}
+		return decodeBody(t, recorder)
+	}
+
+	// Nothing was published through this backend yet
+	if body := describe(); body["targetFPS"] != nil || body["achievedFPS"] != nil {
+		t.Errorf("described frame rates %v and %v for a game never seen", body["targetFPS"], body["achievedFPS"])
+	}
+
+	// The rate is measured between frames at new steps, which takes three frames
+	for step := 1; step <= 3; step++ {
+		gol.Metrics.Observe(gol.StateChange{Id: id, Step: step, EffectiveTickTime: 100 * time.Millisecond})
+		time.Sleep(5 * time.Millisecond)
+	}
+	body := describe()
+	if body["targetFPS"] != float64(10) {
+		t.Errorf("described a target of %v fps, want 10", body["targetFPS"])
+	}
+	if achieved, _ := body["achievedFPS"].(float64); achieved <= 0 || achieved > 200 {
+		t.Errorf("described %v achieved fps for frames 5ms apart", body["achievedFPS"])
+	}
+}
+
+func TestDescribeMissingGame(t *testing.T) {
+	fake := &fakeTemporal{describeWorkflow: func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+		return nil, serviceerror.NewNotFound("workflow not found")
+	}}
+
+	request := httptest.NewRequest(http.MethodGet, "/game/missing", nil)
+	request.SetPathValue("id", "missing")
+	recorder := httptest.NewRecorder()
+	newTestClient(fake).DescribeGame(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestHistorySize(t *testing.T) {
+	// Every describe finds the current run a few steps further along, each step adds events
+	steps := int64(0)
+	fake := &fakeTemporal{describeWorkflow: func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+		if id != "sized" {
+			return nil, serviceerror.NewNotFound("workflow not found")
+		}
+		steps += 3
+		description := describedRun(id, "run-2", enums.WORKFLOW_EXECUTION_STATUS_RUNNING)
+		description.WorkflowExecutionInfo.HistoryLength = 5 + 4*steps
+		description.WorkflowExecutionInfo.HistorySizeBytes = 1000 + 700*steps
+		return description, nil
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	history := func(id string) (int, HistoryStats) {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/history/"+id, nil))
+		var stats HistoryStats
+		if recorder.Code == http.StatusOK {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &stats); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return recorder.Code, stats
+	}
+
+	code, first := history("sized")
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+	if want := (HistoryStats{Id: "sized", RunId: "run-2", Events: 17, Bytes: 3100}); first != want {
+		t.Errorf("history is %+v, want %+v", first, want)
+	}
+	if _, second := history("sized"); second.Events <= first.Events || second.Bytes <= first.Bytes {
+		t.Errorf("history didn't grow with the steps, %+v then %+v", first, second)
+	}
+
+	if code, _ := history("missing"); code != http.StatusNotFound {
+		t.Errorf("missing game responded %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestStartGameWithinTheCap(t *testing.T) {
+	withConfig(t, func(config *Config) { config.MaxGames = 1 })
+
+	// Visibility is behind, it never lists the started games
+	var mu sync.Mutex
+	var started []string
+	fake := &fakeTemporal{
+		listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+			return nil, nil
+		},
+		executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+			// Slow starts leave room for the other requests to check the cap in the meantime
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			started = append(started, options.ID)
+			return nil, nil
+		},
+	}
+	temporalClient := newTestClient(fake)
+	start := func(id string) int {
+		request := httptest.NewRequest(http.MethodPost, "/start?wait=false&reuse=terminate&id="+id, nil)
+		recorder := httptest.NewRecorder()
+		temporalClient.StartGameOfLife(recorder, request)
+		return recorder.Code
+	}
+
+	// Near simultaneous starts of different games, only one fits under the cap
+	codes := make(chan int, 8)
+	for i := range cap(codes) {
+		go func() { codes <- start(fmt.Sprint("game-", i)) }()
+	}
+	statuses := map[int]int{}
+	for range cap(codes) {
+		statuses[<-codes]++
+	}
+	if statuses[http.StatusOK] != 1 || statuses[http.StatusTooManyRequests] != cap(codes)-1 {
+		t.Fatalf("statuses %v, want one %d and the rest %d", statuses, http.StatusOK, http.StatusTooManyRequests)
+	}
+
+	// Restarting the running game replaces it rather than adding one
+	if code := start(started[0]); code != http.StatusOK {
+		t.Errorf("restarting %s responded %d, want %d", started[0], code, http.StatusOK)
+	}
+	if len(started) != 2 {
+		t.Errorf("started %v, want the first game twice", started)
+	}
+}
+
+func TestStartReusePolicies(t *testing.T) {
+	tests := []struct {
+		reuse      string
+		wantStatus int
+		wantPolicy enums.WorkflowIdReusePolicy
+		wantJoin   bool // The running game is kept and the request joins it
+	}{
+		{"", http.StatusConflict, enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE, false},
+		{"reject", http.StatusConflict, enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE, false},
+		{"terminate", http.StatusOK, enums.WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING, false},
+		{"allow-duplicate", http.StatusOK, enums.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE, true},
+		{"clobber", http.StatusBadRequest, enums.WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED, false},
+	}
+	for _, test := range tests {
+		t.Run("reuse="+test.reuse, func(t *testing.T) {
+			// The game is already running, the fake answers the start like the server would
+			var started *client.StartWorkflowOptions
+			joined, terminated := false, false
+			fake := &fakeTemporal{
+				listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+					return []string{"taken"}, nil
+				},
+				executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+					started = &options
+					switch {
+					case options.WorkflowIDReusePolicy == enums.WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING:
+						terminated = true
+					case options.WorkflowIDConflictPolicy == enums.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING:
+						joined = true
+					case options.WorkflowExecutionErrorWhenAlreadyStarted:
+						return nil, serviceerror.NewWorkflowExecutionAlreadyStarted("workflow already started", "", "run-taken")
+					}
+					return nil, nil
+				},
+			}
+			request := httptest.NewRequest(http.MethodPost, "/start?wait=false&id=taken&reuse="+test.reuse, nil)
+			recorder := httptest.NewRecorder()
+			newTestClient(fake).StartGameOfLife(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+			if test.wantStatus == http.StatusBadRequest {
+				if started != nil {
+					t.Error("an unknown policy started the game")
+				}
+				return
+			}
+			if started.WorkflowIDReusePolicy != test.wantPolicy {
+				t.Errorf("started with %v, want %v", started.WorkflowIDReusePolicy, test.wantPolicy)
+			}
+			if joined != test.wantJoin || terminated != (test.reuse == "terminate") {
+				t.Errorf("joined %v and terminated %v the running game", joined, terminated)
+			}
+		})
+	}
+}
+
+func TestStartFromInitialBoard(t *testing.T) {
+	board := make(gol.Board, gol.DefaultBoardLength)
+	for i := range board {
+		board[i] = make([]bool, gol.DefaultBoardWidth)
+	}
+	board.Toggle([][2]int{{0, 1}, {1, 2}, {2, 0}, {2, 1}, {2, 2}, {300, 511}})
+	ragged := gol.Board{make([]bool, 512), make([]bool, 3)}
+	small := gol.Board{{true, false}, {false, true}}
+
+	for _, test := range []struct {
+		name       string
+		board      gol.Board
+		wantStatus int
+	}{
+		{"fixed board", board, http.StatusOK},
+		{"ragged", ragged, http.StatusBadRequest},
+		{"wrong size", small, http.StatusBadRequest},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var started *gol.GameOfLifeInput
+			fake := &fakeTemporal{
+				listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+					return nil, nil
+				},
+				executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+					input := args[0].(gol.GameOfLifeInput)
+					started = &input
+					return nil, nil
+				},
+			}
+			body, err := json.Marshal(StartRequest{InitialBoard: test.board})
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorder := httptest.NewRecorder()
+			newTestClient(fake).StartGameOfLife(recorder, httptest.NewRequest(http.MethodPost, "/start?wait=false&id=fixed", bytes.NewReader(body)))
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.wantStatus, recorder.Body)
+			}
+			if test.wantStatus != http.StatusOK {
+				if started != nil {
+					t.Error("the game started")
+				}
+				return
+			}
+			if started.Board == nil || fmt.Sprint(started.Board.Unpack().LiveCells()) != fmt.Sprint(board.LiveCells()) {
+				t.Errorf("started without the posted board")
+			}
+		})
+	}
+}
+
+func TestMetricsScrape(t *testing.T) {
+	fake := &fakeTemporal{countWorkflow: func(ctx context.Context, query string) (int64, error) {
+		if query != runningGamesQuery {
+			t.Errorf("counted %q, want the running games", query)
+		}
+		return 3, nil
+	}}
+	temporalClient := newTestClient(fake)
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		temporalClient.Metrics(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+			t.Errorf("Content-Type = %q, want the text format", contentType)
+		}
+		return recorder.Body.String()
+	}
+
+	// Three generations of a game published through this process
+	for step := range 3 {
+		state := gol.StateChange{Id: "scraped", Step: step, Population: 10 + step, EffectiveTickTime: 100 * time.Millisecond}
+		if _, err := gol.AmInstance.SendState(context.Background(), state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	metrics := scrape()
+	for _, want := range []string{
+		"# TYPE gol_running_games gauge\ngol_running_games 3\n",
+		"gol_game_steps_total{game=\"scraped\"} 2\n",
+		"gol_game_population{game=\"scraped\"} 12\n",
+		"gol_game_target_fps{game=\"scraped\"} 10\n",
+		"gol_game_achieved_fps{game=\"scraped\"} ",
+		"# TYPE gol_sse_subscribers gauge\ngol_sse_subscribers ",
+		"# TYPE gol_sse_lagging_subscribers gauge\ngol_sse_lagging_subscribers ",
+		"# TYPE gol_dropped_frames_total counter\ngol_dropped_frames_total ",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics are missing %q:\n%s", want, metrics)
+		}
+	}
+
+	// Once the game ended its series are gone
+	if _, err := gol.AmInstance.SendState(context.Background(), gol.StateChange{Id: "scraped", Step: 2, Ended: gol.EndedMaxSteps}); err != nil {
+		t.Fatal(err)
+	}
+	if metrics := scrape(); strings.Contains(metrics, `game="scraped"`) {
+		t.Errorf("metrics still have the ended game:\n%s", metrics)
+	}
+}
+
+func TestStatsStream(t *testing.T) {
+	fake := &fakeTemporal{queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+		if queryType != "population" {
+			return nil, fmt.Errorf("unexpected %s query", queryType)
+		}
+		return gol.PopulationStats{Step: 0, Population: 5}, nil
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/stats/charted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	readStats := func() (sseEvent, StatsFrame) {
+		t.Helper()
+		event := readEvent(t, reader)
+		for event.data == "" {
+			event = readEvent(t, reader)
+		}
+		var stats StatsFrame
+		if err := json.Unmarshal([]byte(event.data), &stats); err != nil {
+			t.Fatalf("stats %q don't decode: %v", event.data, err)
+		}
+		return event, stats
+	}
+	if _, initial := readStats(); initial != (StatsFrame{Population: 5}) {
+		t.Fatalf("initial stats are %+v, want the queried population", initial)
+	}
+
+	// Each generation of the glider is charted with its births and deaths
+	game := newScriptedGame("charted", 6)
+	for _, frame := range game.frames {
+		if frame.Step == 0 || frame.Seq%3 == 0 {
+			continue
+		}
+		born := 0
+		for _, cell := range frame.Flipped {
+			if !game.boards[frame.Seq-1][cell[0]][cell[1]] {
+				born++
+			}
+		}
+		frame.Births, frame.Deaths, frame.Population = born, len(frame.Flipped)-born, len(game.boards[frame.Seq].LiveCells())
+		gol.StateStream.Publish("charted", frame)
+
+		_, stats := readStats()
+		if want := (StatsFrame{Step: frame.Step, Population: frame.Population, Births: frame.Births, Deaths: frame.Deaths}); stats != want {
+			t.Errorf("stats are %+v, want %+v", stats, want)
+		}
+		if stats.Births+stats.Deaths != len(frame.Flipped) {
+			t.Errorf("step %d charts %d births and %d deaths for %d flipped cells", stats.Step, stats.Births, stats.Deaths, len(frame.Flipped))
+		}
+	}
+
+	gol.StateStream.Publish("charted", gol.StateChange{Id: "charted", Step: 4, Ended: gol.EndedMaxSteps})
+	if event, _ := readStats(); event.name != "game_ended" {
+		t.Errorf("the stream ended with a %q event, want game_ended", event.name)
+	}
+}
+
+func TestPauseTwiceStaysPaused(t *testing.T) {
+	// The game's paused state as its signals leave it
+	paused := false
+	var signals []gol.SetPausedSignal
+	fake := &fakeTemporal{
+		queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+			return gol.PopulationStats{Step: 12, Paused: paused}, nil
+		},
+		signalWorkflow: func(ctx context.Context, id string, signalName string, arg any) error {
+			if signalName != gol.SetPausedSignalName {
+				t.Fatalf("sent %s, want %s so a concurrent toggle can't invert it", signalName, gol.SetPausedSignalName)
+			}
+			signal := arg.(gol.SetPausedSignal)
+			signals = append(signals, signal)
+			paused = signal.Paused
+			return nil
+		},
+	}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	send := func(path string) map[string]any {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s responded %d", path, recorder.Code)
+		}
+		return decodeBody(t, recorder)
+	}
+
+	for range 2 {
+		if body := send("/pause/paused"); body["paused"] != true || !paused {
+			t.Errorf("pausing responded %v with the game paused %v", body, paused)
+		}
+	}
+	if len(signals) != 1 {
+		t.Errorf("two pauses sent %d signals, want the first one only", len(signals))
+	}
+
+	if body := send("/resume/paused"); body["paused"] != false || paused {
+		t.Errorf("resuming responded %v with the game paused %v", body, paused)
+	}
+}
+
+func TestStateStreamPings(t *testing.T) {
+	withConfig(t, func(config *Config) { config.SSEPingInterval = 20 * time.Millisecond })
+	game := newScriptedGame("pinged", 1)
+	fake := &fakeTemporal{queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+		return game.board(1), nil
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/state/pinged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	readFrame(t, reader, nil)
+
+	// Nothing happens in the game, so the stream is nothing but pings
+	last := time.Now()
+	for i := range 4 {
+		if event := readEvent(t, reader); event.name != "ping" {
+			t.Fatalf("got %q event, want a ping", event.name)
+		}
+		if gap := time.Since(last); i > 0 && (gap < 15*time.Millisecond || gap > 200*time.Millisecond) {
+			t.Errorf("ping %d came %v after the one before, want about 20ms", i+1, gap)
+		}
+		last = time.Now()
+	}
+}
+
+func TestStateStreamNDJSON(t *testing.T) {
+	withConfig(t, func(config *Config) { config.SSEPingInterval = time.Millisecond })
+	game := newScriptedGame("lines", 3)
+	fake := &fakeTemporal{queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+		return game.board(1), nil
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/state/lines", nil)
+	request.Header.Set("Accept", "application/x-ndjson")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if contentType := response.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/x-ndjson") {
+		t.Errorf("content type is %s", contentType)
+	}
+
+	// Every line is a frame, the pings and the connection event have no line of their own
+	reader := bufio.NewReader(response.Body)
+	for seq := 1; seq <= 3; seq++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		var frame gol.StateChange
+		if err := json.Unmarshal([]byte(line), &frame); err != nil {
+			t.Fatalf("line %q isn't a state change: %v", line, err)
+		}
+		if frame.Seq != seq || strings.Count(line, "\n") != 1 {
+			t.Errorf("line %q is frame %d, want frame %d on one line", line, frame.Seq, seq)
+		}
+		if seq == 1 {
+			game.publish(2, 3)
+		}
+	}
+}
+
+func TestScrollbackEndpoint(t *testing.T) {
+	game := newScriptedGame("scrolled", 6)
+	game.publish(1, 6)
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(&fakeTemporal{}), mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/scrollback/scrolled?n=3", nil))
+	var frames []gol.StateChange
+	if err := json.NewDecoder(recorder.Body).Decode(&frames); err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 3 || frames[0].Seq != 4 || frames[2].Seq != 6 {
+		t.Errorf("scrollback is %+v, want frames 4 to 6", frames)
+	}
+
+	for _, n := range []string{"0", "many", fmt.Sprint(gol.ScrollbackSize + 1)} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/scrollback/scrolled?n="+n, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("n=%s responded %d, want 400", n, recorder.Code)
+		}
+	}
+}
+
+func TestArchiveEndpoint(t *testing.T) {
+	gol.Archive.Put(gol.ArchivedGame{Id: "finished", Step: 42, Population: 5})
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(&fakeTemporal{}), mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/archive/finished", nil))
+	if body := decodeBody(t, recorder); recorder.Code != http.StatusOK || body["step"] != 42.0 || body["population"] != 5.0 {
+		t.Errorf("archive responded %d with %v", recorder.Code, body)
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/archive/unknown", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("unknown game responded %d, want 404", recorder.Code)
+	}
+}
+
+func TestStateStreamEndsWithGameEnded(t *testing.T) {
+	withConfig(t, func(config *Config) { config.SSEPingInterval = time.Hour })
+	game := newScriptedGame("over", 3)
+	game.frames[2].Ended = gol.EndedTerminated
+	fake := &fakeTemporal{queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+		return game.board(1), nil
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/state/over")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	_, board := readFrame(t, reader, nil)
+	game.publish(2, 3)
+	for _, want := range []string{"2", "3"} {
+		var event sseEvent
+		if event, board = readFrame(t, reader, board); event.id != want {
+			t.Fatalf("got frame %s, want %s", event.id, want)
+		}
+	}
+
+	event := readEvent(t, reader)
+	var ended GameEndedEvent
+	if err := json.Unmarshal([]byte(event.data), &ended); event.name != "game_ended" || err != nil {
+		t.Fatalf("got %q event %q after the last frame, want game_ended", event.name, event.data)
+	}
+	if ended.Reason != gol.EndedTerminated || ended.Step != game.frames[2].Step {
+		t.Errorf("game ended with %+v, want terminated at step %d", ended, game.frames[2].Step)
+	}
+	// Nothing comes after it, the stream is closed
+	if rest, err := io.ReadAll(reader); err != nil || len(rest) != 0 {
+		t.Errorf("the stream went on with %q (%v)", rest, err)
+	}
+}
+
+func TestStartRejectsAnOversizedBoard(t *testing.T) {
+	fake := &fakeTemporal{
+		listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+			return nil, nil
+		},
+		// The workflow checks the size of the boards it makes itself
+		executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+			return temporal.NewNonRetryableApplicationError("board can be at most 4096x4096, got 100000x100000", gol.InvalidBoardErrorType, nil), nil
+		},
+	}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+
+	for name, body := range map[string]string{
+		"random board":  "",
+		"initial board": `{"initialBoard": [[` + strings.Repeat("false,", gol.MaxBoardSide) + `false]]}`,
+	} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/start?reuse=terminate&id=huge", strings.NewReader(body)))
+		message, _ := decodeBody(t, recorder)["error"].(string)
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(message, "at most 4096x4096") {
+			t.Errorf("oversized %s responded %d: %s", name, recorder.Code, message)
+		}
+	}
+}
+
+func TestSnapshotRestoreEndpoints(t *testing.T) {
+	board := make(gol.Board, 8)
+	for i := range board {
+		board[i] = make([]bool, 8)
+	}
+	board.Toggle([][2]int{{1, 2}, {2, 3}, {3, 1}, {3, 2}, {3, 3}})
+	snapshot := gol.GameSnapshot{Id: "saved", Step: 40, MaxSteps: 100, TickTime: 50 * time.Millisecond, Rule: "B36/S23", Board: board.Pack()}
+
+	var restored gol.GameOfLifeInput
+	var restoredId string
+	fake := &fakeTemporal{
+		queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+			if queryType != "snapshot" {
+				return nil, fmt.Errorf("unexpected %s query", queryType)
+			}
+			return snapshot, nil
+		},
+		listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+			return nil, nil
+		},
+		executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+			restoredId, restored = options.ID, args[0].(gol.GameOfLifeInput)
+			return nil, nil
+		},
+	}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/snapshot/saved", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("snapshot responded %d: %s", recorder.Code, recorder.Body)
+	}
+
+	// The snapshot is posted back as is
+	body := recorder.Body
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/restore?wait=false&id=copy", body))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("restore responded %d: %s", recorder.Code, recorder.Body)
+	}
+	if restoredId != "copy" || restored.Step != 40 || restored.MaxSteps != 100 || restored.Rule != "B36/S23" || restored.TickTime != 50*time.Millisecond {
+		t.Errorf("restored %s with %+v", restoredId, restored)
+	}
+	if fmt.Sprint(restored.Board.Unpack()) != fmt.Sprint(board) {
+		t.Errorf("restored board %v, want %v", restored.Board.Unpack(), board)
+	}
+}
+
+func TestDebugEndpoint(t *testing.T) {
+	debug := gol.DebugState{Id: "game", Step: 12, MaxSteps: 100, Paused: true, TickTime: 50 * time.Millisecond, Rows: 30, Cols: 40, Population: 17, LastDiff: 6}
+	fake := &fakeTemporal{queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+		if queryType != "debug" {
+			return nil, fmt.Errorf("unexpected %s query", queryType)
+		}
+		if id != "game" {
+			return nil, serviceerror.NewNotFound("workflow not found")
+		}
+		return debug, nil
+	}, describeWorkflow: func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+		return nil, serviceerror.NewNotFound("workflow not found")
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/game", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("debug responded %d: %s", recorder.Code, recorder.Body)
+	}
+	var got gol.DebugState
+	if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != debug {
+		t.Errorf("debug served %+v, want %+v", got, debug)
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/missing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("missing game responded %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestListSignalsEndpoint(t *testing.T) {
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(&fakeTemporal{}), mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/signals", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("signals responded %d: %s", recorder.Code, recorder.Body)
+	}
+	var descriptors []gol.SignalDescriptor
+	if err := json.NewDecoder(recorder.Body).Decode(&descriptors); err != nil {
+		t.Fatal(err)
+	}
+	if len(descriptors) != len(gol.Signals) {
+		t.Fatalf("listed %d signals, want the %d registered", len(descriptors), len(gol.Signals))
+	}
+	for _, descriptor := range descriptors {
+		if _, ok := gol.Signals[descriptor.Name]; !ok {
+			t.Errorf("listed unregistered signal %s", descriptor.Name)
+		}
+	}
+}
+
+func TestStartCarriesTheTraceId(t *testing.T) {
+	for _, test := range []struct {
+		name, header, want string
+	}{
+		{"from the header", "request-1234", "request-1234"},
+		{"defaults to the run id", "", "run-traced"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var startedWith string
+			fake := &fakeTemporal{
+				listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+					return nil, nil
+				},
+				executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+					// The propagator puts the context's trace id in the start's headers
+					startedWith = gol.TraceId(ctx)
+					return nil, nil
+				},
+			}
+			request := httptest.NewRequest(http.MethodPost, "/start?wait=false&id=traced", nil)
+			if test.header != "" {
+				request.Header.Set(gol.TraceHeader, test.header)
+			}
+			recorder := httptest.NewRecorder()
+			newTestClient(fake).StartGameOfLife(recorder, request)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", recorder.Code, recorder.Body)
+			}
+			if startedWith != test.header {
+				t.Errorf("game started with trace id %q, want %q", startedWith, test.header)
+			}
+			var started StartedGame
+			if err := json.NewDecoder(recorder.Body).Decode(&started); err != nil {
+				t.Fatal(err)
+			}
+			if started.TraceId != test.want {
+				t.Errorf("start answered trace id %q, want %q", started.TraceId, test.want)
+			}
+		})
+	}
+}
+
+func TestResyncEndpoint(t *testing.T) {
+	game := newScriptedGame("drifted", 7)
+	fake := &fakeTemporal{queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+		if queryType != "board" {
+			return nil, fmt.Errorf("unexpected %s query", queryType)
+		}
+		if id != "drifted" {
+			return nil, serviceerror.NewNotFound("workflow not found")
+		}
+		return game.board(7), nil
+	}, describeWorkflow: func(ctx context.Context, id string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+		return nil, serviceerror.NewNotFound("workflow not found")
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/resync/drifted", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("resync responded %d: %s", recorder.Code, recorder.Body)
+	}
+	var frame gol.StateChange
+	if err := json.NewDecoder(recorder.Body).Decode(&frame); err != nil {
+		t.Fatal(err)
+	}
+	if !frame.Full || frame.Seq != 7 {
+		t.Fatalf("resynced to %+v, want the full board as of frame 7", frame)
+	}
+
+	// The board is the one a client reaches by applying the streamed diffs to the seed
+	board := game.boards[0].Clone()
+	for _, diff := range game.frames {
+		board.Toggle(diff.Flipped)
+	}
+	if fmt.Sprint(frame.Flipped) != fmt.Sprint(board.LiveCells()) {
+		t.Errorf("resynced to %v, the stream reconstructs %v", frame.Flipped, board.LiveCells())
+	}
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/resync/missing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("missing game responded %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
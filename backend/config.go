@@ -0,0 +1,119 @@
+package main
+
+import (
+	"backend/gol"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Backend configuration, every setting is a flag whose default can come from the environment
+type Config struct {
+	TemporalHost    string
+	TaskQueue       string
+	HTTPAddr        string
+	MaxActivities   int
+	MaxGames        int
+	AllowedOrigins  []string // * allows any origin (local dev)
+	SignalRate      float64  // Signals per second each client can send to a game
+	SignalBurst     int
+	RedisAddr       string        // Publishes states on redis when set, in memory otherwise
+	SSEPingInterval time.Duration // Pings keep idle streams open behind proxies that reap quiet connections
+	SSEWriteTimeout time.Duration // A stream write taking longer than this counts the client as gone
+	ScrollbackSize  int           // Frames of each game kept for /scrollback
+	BreakerFailures int           // Redis publish failures in a row before frames are dropped instead of retried
+	BreakerCooldown time.Duration // How long frames are dropped for before publishing is tried again
+}
+
+// DefaultConfig returns the configuration used when nothing is set
+func DefaultConfig() Config {
+	return Config{
+		TemporalHost:    "localhost:7233",
+		TaskQueue:       "gol",
+		HTTPAddr:        ":8080",
+		MaxActivities:   1000,
+		MaxGames:        10,
+		AllowedOrigins:  []string{"*"},
+		SignalRate:      20,
+		SignalBurst:     40,
+		SSEPingInterval: 10 * time.Second,
+		SSEWriteTimeout: 10 * time.Second,
+		ScrollbackSize:  gol.DefaultScrollbackSize,
+		BreakerFailures: gol.DefaultBreakerThreshold,
+		BreakerCooldown: gol.DefaultBreakerCooldown,
+	}
+}
+
+// ParseConfig parses the flags in args (without the program name)
+// The environment overrides the defaults and the flags override the environment
+func ParseConfig(args []string) (Config, error) {
+	config := DefaultConfig()
+	config.TemporalHost = envOr("TEMPORAL_HOST", config.TemporalHost)
+	config.TaskQueue = envOr("TASK_QUEUE", config.TaskQueue)
+	config.HTTPAddr = envOr("HTTP_ADDR", config.HTTPAddr)
+
+	flags := flag.NewFlagSet("backend", flag.ContinueOnError)
+
+	// Errors are printed like the flag set prints its own
+	fail := func(err error) (Config, error) {
+		fmt.Fprintln(flags.Output(), err)
+		return Config{}, err
+	}
+
+	var err error
+	if config.SSEPingInterval, err = envDurationOr("SSE_PING_INTERVAL", config.SSEPingInterval); err != nil {
+		return fail(err)
+	}
+	flags.StringVar(&config.TemporalHost, "temporal-host", config.TemporalHost, "temporal server (host:port), or $TEMPORAL_HOST")
+	flags.StringVar(&config.TaskQueue, "task-queue", config.TaskQueue, "task queue the worker polls and games start on, or $TASK_QUEUE")
+	flags.StringVar(&config.HTTPAddr, "http-addr", config.HTTPAddr, "address the http server listens on, or $HTTP_ADDR")
+	flags.IntVar(&config.MaxActivities, "max-activities", config.MaxActivities, "maximum activities the worker runs at once")
+	flags.IntVar(&config.MaxGames, "max-games", config.MaxGames, "maximum number of games running at once")
+	origins := flags.String("allowed-origins", strings.Join(config.AllowedOrigins, ","), "comma separated origins allowed by CORS, * allows any")
+	flags.Float64Var(&config.SignalRate, "signal-rate", config.SignalRate, "signals per second each client can send to a game")
+	flags.IntVar(&config.SignalBurst, "signal-burst", config.SignalBurst, "signals a client can send to a game in a burst")
+	flags.StringVar(&config.RedisAddr, "redis-addr", config.RedisAddr, "redis server (host:port) to publish states on so several backends can serve streams, in memory when empty")
+	flags.DurationVar(&config.SSEPingInterval, "sse-ping-interval", config.SSEPingInterval, "time between pings on idle event streams, or $SSE_PING_INTERVAL")
+	flags.DurationVar(&config.SSEWriteTimeout, "sse-write-timeout", config.SSEWriteTimeout, "time an event stream write can take before the client counts as gone")
+	flags.IntVar(&config.ScrollbackSize, "scrollback-size", config.ScrollbackSize, fmt.Sprintf("frames of each game kept for /scrollback, at most %d", gol.MaxScrollbackSize))
+	flags.IntVar(&config.BreakerFailures, "breaker-failures", config.BreakerFailures, "redis publish failures in a row before frames are dropped for the cooldown")
+	flags.DurationVar(&config.BreakerCooldown, "breaker-cooldown", config.BreakerCooldown, "time frames are dropped for once redis publishing keeps failing")
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+	config.AllowedOrigins = strings.Split(*origins, ",")
+
+	if config.SSEPingInterval <= 0 || config.SSEWriteTimeout <= 0 {
+		return fail(fmt.Errorf("sse ping interval and write timeout must be positive, got %v and %v", config.SSEPingInterval, config.SSEWriteTimeout))
+	}
+	if config.ScrollbackSize < 1 || config.ScrollbackSize > gol.MaxScrollbackSize {
+		return fail(fmt.Errorf("scrollback size must be between 1 and %d, got %d", gol.MaxScrollbackSize, config.ScrollbackSize))
+	}
+
+	if config.BreakerFailures < 1 || config.BreakerCooldown <= 0 {
+		return fail(fmt.Errorf("breaker failures and cooldown must be positive, got %d and %v", config.BreakerFailures, config.BreakerCooldown))
+	}
+
+	return config, nil
+}
+
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envDurationOr(key string, fallback time.Duration) (time.Duration, error) {
+	value := envOr(key, "")
+	if value == "" {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("$%s: %w", key, err)
+	}
+	return duration, nil
+}
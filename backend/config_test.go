@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want func(config *Config) // Changes from the defaults
+	}{
+		{"defaults", nil, nil, func(config *Config) {}},
+		{
+			"flags",
+			nil,
+			[]string{"-task-queue", "gol-staging", "-temporal-host", "temporal:7233", "-http-addr", ":9090", "-max-activities", "50", "-allowed-origins", "https://a.example,https://b.example", "-sse-ping-interval", "3s"},
+			func(config *Config) {
+				config.TaskQueue = "gol-staging"
+				config.TemporalHost = "temporal:7233"
+				config.HTTPAddr = ":9090"
+				config.MaxActivities = 50
+				config.AllowedOrigins = []string{"https://a.example", "https://b.example"}
+				config.SSEPingInterval = 3 * time.Second
+			},
+		},
+		{
+			"environment",
+			map[string]string{"TASK_QUEUE": "gol-env", "TEMPORAL_HOST": "env:7233", "HTTP_ADDR": ":7070", "SSE_PING_INTERVAL": "2s"},
+			nil,
+			func(config *Config) {
+				config.TaskQueue = "gol-env"
+				config.TemporalHost = "env:7233"
+				config.HTTPAddr = ":7070"
+				config.SSEPingInterval = 2 * time.Second
+			},
+		},
+		{
+			"flags over the environment",
+			map[string]string{"TASK_QUEUE": "gol-env", "HTTP_ADDR": ":7070"},
+			[]string{"-task-queue", "gol-flag"},
+			func(config *Config) {
+				config.TaskQueue = "gol-flag"
+				config.HTTPAddr = ":7070"
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, key := range []string{"TASK_QUEUE", "TEMPORAL_HOST", "HTTP_ADDR", "SSE_PING_INTERVAL"} {
+				t.Setenv(key, test.env[key])
+			}
+			config, err := ParseConfig(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := DefaultConfig()
+			test.want(&want)
+			if !reflect.DeepEqual(config, want) {
+				t.Errorf("config is\n%+v\nwant\n%+v", config, want)
+			}
+		})
+	}
+}
+
+func TestParseConfigRejectsBadValues(t *testing.T) {
+	for _, args := range [][]string{
+		{"-max-activities", "many"},
+		{"-unknown-flag"},
+		{"-sse-ping-interval", "0s"},
+		{"-scrollback-size", "0"},
+		{"-breaker-failures", "0"},
+	} {
+		if _, err := ParseConfig(args); err == nil {
+			t.Errorf("%v parsed", args)
+		}
+	}
+}
@@ -2,9 +2,15 @@ package gol
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
 	"time"
 
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -17,21 +23,71 @@ type Am struct{}
 
 var AmInstance = &Am{}
 
-var ao = workflow.ActivityOptions{
+// Options for activities without their own entry in ActivityOptions
+var DefaultActivityOptions = workflow.ActivityOptions{
 	StartToCloseTimeout: 10 * time.Second,
+	RetryPolicy: &temporal.RetryPolicy{
+		InitialInterval:    time.Second,
+		BackoffCoefficient: 2,
+		MaximumInterval:    10 * time.Second,
+		MaximumAttempts:    5,
+	},
+}
+
+// Options per activity, keyed by the activity's method name
+// Tune these before starting the worker, they are read whenever a workflow runs an activity
+var ActivityOptions = map[string]workflow.ActivityOptions{
+	// Publishing is fast, a stuck publish should be retried quickly rather than stall the tick
+	"SendState": {
+		StartToCloseTimeout: 2 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    100 * time.Millisecond,
+			BackoffCoefficient: 2,
+			MaximumInterval:    time.Second,
+			MaximumAttempts:    5,
+		},
+	},
+	// Computes up to MaxRecordRunSteps generations of a full board
+	"RecordRun": {
+		StartToCloseTimeout: 2 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: 5 * time.Second,
+			MaximumAttempts: 2,
+		},
+	},
+}
+
+// activityOptions returns the options for the activity (a method of Am)
+func activityOptions(activity any) workflow.ActivityOptions {
+	if options, ok := ActivityOptions[activityName(activity)]; ok {
+		return options
+	}
+	return DefaultActivityOptions
+}
+
+// activityName returns the name temporal registers the activity (a method of Am) under
+func activityName(activity any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(activity).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
 }
 
 // splatter affects a single cell and its surrounding cells
-// randomly chooses spat zones and then randomly sets cells to true in the splat zone
+// randomly chooses spat zones and then randomly picks cells to bring alive in the splat zone
+// The activity only chooses the cells, the workflow applies them to its board
+// It never sees a board, so splatters of any number of games can't race with their generations
 type SplatterInput struct {
-	Row    int
-	Col    int
-	Radius int
+	Rows    int
+	Cols    int
+	Row     int
+	Col     int
+	Radius  int
+	Density float64 // Fraction of the splat zone brought alive (defaults to 0.6)
 }
 
-func (a *Am) Splatter(ctx context.Context, input SplatterInput) error {
-	rows := len(GolBoard)
-	cols := len(GolBoard[0])
+const DefaultSplatterDensity = 0.6
+
+func (a *Am) Splatter(ctx context.Context, input SplatterInput) (cells [][2]int, err error) {
 	// Collect all cells within the circular radius
 	var candidates [][2]int
 	for i := -input.Radius; i <= input.Radius; i++ {
@@ -39,113 +95,186 @@ func (a *Am) Splatter(ctx context.Context, input SplatterInput) error {
 			if i*i+j*j <= input.Radius*input.Radius {
 				r := input.Row + i
 				c := input.Col + j
-				if r >= 0 && r < rows && c >= 0 && c < cols {
+				if r >= 0 && r < input.Rows && c >= 0 && c < input.Cols {
 					candidates = append(candidates, [2]int{r, c})
 				}
 			}
 		}
 	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
 	// Randomly shuffle candidates
 	rand.Shuffle(len(candidates), func(i, j int) {
 		candidates[i], candidates[j] = candidates[j], candidates[i]
 	})
-	// Choose a random number of cells to fill
-	numToFill := rand.Intn(len(candidates)/2) + 1
-	for i := range numToFill {
-		r, c := candidates[i][0], candidates[i][1]
-		GolBoard[r][c] = true
+	// Fill the density's share of the zone
+	density := input.Density
+	if density == 0 {
+		density = DefaultSplatterDensity
 	}
+	density = min(max(density, 0), 1)
+	numToFill := max(int(math.Round(density*float64(len(candidates)))), 1)
+	cells = candidates[:numToFill]
 	// Ensure the center cell is always alive
-	GolBoard[input.Row][input.Col] = true
-	return nil
+	if input.Row >= 0 && input.Row < input.Rows && input.Col >= 0 && input.Col < input.Cols {
+		cells = append(cells, [2]int{input.Row, input.Col})
+	}
+	return cells, nil
 }
 
 type GetInitialBoardInput struct {
-	Length           int
-	Width            int
-	UseInMemoryBoard bool
+	Length int
+	Width  int
+	RandomBoardOptions
 }
 
 func (a *Am) GetInitialBoard(ctx context.Context, input GetInitialBoardInput) (board Board, err error) {
-	if GolBoard != nil && input.UseInMemoryBoard {
-		return GolBoard, nil
-	}
-
 	// Create a random board
 	return a.GetRandomBoard(ctx, GetRandomBoardInput{
-		Length: input.Length,
-		Width:  input.Width,
+		Length:             input.Length,
+		Width:              input.Width,
+		RandomBoardOptions: input.RandomBoardOptions,
 	})
 
 }
 
+// Defaults of the random board options
+const (
+	DefaultDensity        = 0.6
+	DefaultMinClusters    = 5
+	DefaultMaxClusters    = 12
+	DefaultMinRadius      = 2
+	DefaultMaxRadius      = 5
+	DefaultOffsetFraction = 1.0 / 6
+)
+
+// Tunes how the random board is generated, zero values keep the defaults
+type RandomBoardOptions struct {
+	Density        float64 // Chance a candidate cell is alive, clamped to [0, 1] (defaults to 0.6)
+	NumClusters    int     // Exact number of clusters for the center fill, overrides MinClusters and MaxClusters
+	MinClusters    int     // Fewest clusters for the center fill (defaults to 5)
+	MaxClusters    int     // Most clusters for the center fill (defaults to 12)
+	MinRadius      int     // Smallest cluster radius (defaults to 2)
+	MaxRadius      int     // Biggest cluster radius (defaults to 5)
+	OffsetFraction float64 // Furthest a cluster center is from the middle, as a fraction of the board size (defaults to ⅙)
+	Fill           string  // Fill strategy, one of center (default), uniform or perlin (see fill.go)
+	Symmetry       string  // Mirrors the fill, one of horizontal, vertical or 4fold (see symmetry.go), empty keeps it as is
+	Seed           int64   // Seeds the generator so the same options give the same board, 0 picks a random seed
+}
+
+func (o RandomBoardOptions) Validate() error {
+	if _, ok := fills[o.Fill]; !ok {
+		return fmt.Errorf("unknown fill %q, valid fills are: %s", o.Fill, strings.Join(FillNames(), ", "))
+	}
+	if _, ok := symmetries[o.Symmetry]; !ok {
+		return fmt.Errorf("unknown symmetry %q, valid symmetries are: %s", o.Symmetry, strings.Join(SymmetryNames(), ", "))
+	}
+	if o.NumClusters < 0 || o.MinClusters < 0 || o.MaxClusters < 0 {
+		return fmt.Errorf("cluster counts must not be negative, got %d, %d and %d", o.NumClusters, o.MinClusters, o.MaxClusters)
+	}
+	if o.MinRadius < 0 || o.MaxRadius < 0 {
+		return fmt.Errorf("cluster radius must not be negative, got %d to %d", o.MinRadius, o.MaxRadius)
+	}
+	if o.OffsetFraction < 0 || o.OffsetFraction > 0.5 {
+		return fmt.Errorf("offset fraction must be between 0 and 0.5, got %v", o.OffsetFraction)
+	}
+
+	o = o.withDefaults()
+	if o.MinClusters > o.MaxClusters {
+		return fmt.Errorf("min clusters %d is above max clusters %d", o.MinClusters, o.MaxClusters)
+	}
+	if o.MinRadius > o.MaxRadius {
+		return fmt.Errorf("min radius %d is above max radius %d", o.MinRadius, o.MaxRadius)
+	}
+	return nil
+}
+
+// withDefaults fills in the defaults of the cluster options left at zero
+// A default never contradicts the other end of its range, so setting just one end is enough
+func (o RandomBoardOptions) withDefaults() RandomBoardOptions {
+	o.MinClusters, o.MaxClusters = defaultRange(o.MinClusters, o.MaxClusters, DefaultMinClusters, DefaultMaxClusters)
+	o.MinRadius, o.MaxRadius = defaultRange(o.MinRadius, o.MaxRadius, DefaultMinRadius, DefaultMaxRadius)
+	if o.OffsetFraction == 0 {
+		o.OffsetFraction = DefaultOffsetFraction
+	}
+	return o
+}
+
+func defaultRange(low, high, defaultLow, defaultHigh int) (int, int) {
+	switch {
+	case low == 0 && high == 0:
+		return defaultLow, defaultHigh
+	case low == 0:
+		return min(defaultLow, high), high
+	case high == 0:
+		return low, max(defaultHigh, low)
+	}
+	return low, high
+}
+
 type GetRandomBoardInput struct {
 	Length int
 	Width  int
+	RandomBoardOptions
 }
 
-// GetRandomBoard returns a board with a random splatter in the middle
+// GetRandomBoard returns a board filled by the chosen fill strategy
 func (a *Am) GetRandomBoard(ctx context.Context, input GetRandomBoardInput) (board Board, err error) {
+	if err := input.Validate(); err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidFill", nil)
+	}
+	if input.Length < 0 || input.Width < 0 {
+		return nil, temporal.NewNonRetryableApplicationError(fmt.Sprintf("board dimensions must not be negative, got %dx%d", input.Length, input.Width), InvalidBoardErrorType, nil)
+	}
+	// Check the size before allocating, an oversized board would take the worker down with it
+	if err := ValidateBoardSize(input.Length, input.Width); err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), InvalidBoardErrorType, nil)
+	}
+
 	board = make(Board, input.Length)
 	for i := range board {
 		board[i] = make([]bool, input.Width)
 	}
 
-	// Number of random clusters
-	numClusters := rand.Intn(8) + 5 // 5–12 clusters
-
-	// Center point
-	centerRowMid := input.Length / 2
-	centerColMid := input.Width / 2
-
-	for range numClusters {
-		offsetRow := rand.Intn(input.Length/3) - input.Length/6 // within ±⅙ of total height
-		offsetCol := rand.Intn(input.Width/3) - input.Width/6   // within ±⅙ of total width
-		centerRow := centerRowMid + offsetRow
-		centerCol := centerColMid + offsetCol
-		radius := rand.Intn(4) + 2 // radius 2–5
-
-		// Fill cells in roughly circular clusters
-		for i := -radius; i <= radius; i++ {
-			for j := -radius; j <= radius; j++ {
-				if i*i+j*j <= radius*radius {
-					r := centerRow + i
-					c := centerCol + j
-					if r >= 0 && r < input.Length && c >= 0 && c < input.Width {
-						if rand.Float64() < 0.6 { // density
-							board[r][c] = true
-						}
-					}
-				}
-			}
-		}
+	density := input.Density
+	if density == 0 {
+		density = DefaultDensity
 	}
+	density = min(max(density, 0), 1)
 
+	seed := input.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	fills[input.Fill](board, input.RandomBoardOptions, density, rng)
+	symmetries[input.Symmetry](board)
+
+	// Retrying can't fix the dimensions, fail the workflow with the reason instead of panicking in it later
+	if err := board.Validate(); err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), InvalidBoardErrorType, nil)
+	}
 	return board, nil
 }
 
 /* ------------------------------ IO Activites ------------------------------ */
 
-// Mimics a redis channel
-var StateStream chan StateChange
+// Carries state changes to the SSE streams, in memory unless main switches it to redis
+var StateStream StatePublisher = NewMemoryPublisher()
 
-func (a *Am) Tick(ctx context.Context, duration time.Duration) error {
-	time.Sleep(duration)
-	Steps++
-	return nil
-}
-
-func (a *Am) SendState(ctx context.Context, state StateChange) error {
+// Stats of the games published from this process, served on /metrics
+var Metrics = NewGameMetrics()
 
-	if StateStream == nil {
-		StateStream = make(chan StateChange, 5)
+// SendState publishes the state change to the game's subscribers (see StateHub for the drop semantics)
+// The result reports the slow subscribers so the workflow can apply backpressure
+func (a *Am) SendState(ctx context.Context, state StateChange) (SendStateResult, error) {
+	state.TraceId = TraceId(ctx)
+	Metrics.Observe(state)
+	dropped, err := StateStream.Publish(state.Id, state)
+	if err != nil {
+		return SendStateResult{}, err
 	}
-
-	select {
-	case StateStream <- state:
-	case StateStream <- state:
-	default:
-		// Drop if no listener
-	}
-	return nil
+	return SendStateResult{Dropped: dropped}, nil
 }
@@ -0,0 +1,129 @@
+package gol
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// averagePopulation returns the average population of random boards made with the options over a few fixed seeds
+func averagePopulation(t *testing.T, options RandomBoardOptions) float64 {
+	t.Helper()
+	total := 0
+	for seed := int64(1); seed <= 10; seed++ {
+		options.Seed = seed
+		board, err := AmInstance.GetRandomBoard(context.Background(), GetRandomBoardInput{Length: 60, Width: 60, RandomBoardOptions: options})
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += board.Population()
+	}
+	return float64(total) / 10
+}
+
+func TestRandomBoardDensity(t *testing.T) {
+	for _, fill := range FillNames() {
+		t.Run(fill, func(t *testing.T) {
+			previous := 0.0
+			for _, density := range []float64{0.1, 0.4, 0.7, 1} {
+				population := averagePopulation(t, RandomBoardOptions{Fill: fill, Density: density, NumClusters: 8})
+				if population <= previous {
+					t.Errorf("density %v averages %v live cells, not more than the sparser board's %v", density, population, previous)
+				}
+				previous = population
+			}
+
+			// Densities out of range are clamped, zero keeps the default
+			if dense, full := averagePopulation(t, RandomBoardOptions{Fill: fill, Density: 5}), averagePopulation(t, RandomBoardOptions{Fill: fill, Density: 1}); dense != full {
+				t.Errorf("density 5 averages %v live cells, want density 1's %v", dense, full)
+			}
+			if empty := averagePopulation(t, RandomBoardOptions{Fill: fill, Density: -1}); empty != 0 {
+				t.Errorf("density -1 averages %v live cells, want none", empty)
+			}
+			if defaulted, want := averagePopulation(t, RandomBoardOptions{Fill: fill}), averagePopulation(t, RandomBoardOptions{Fill: fill, Density: DefaultDensity}); defaulted != want {
+				t.Errorf("density 0 averages %v live cells, want the default density's %v", defaulted, want)
+			}
+		})
+	}
+}
+
+func TestRandomBoardClusters(t *testing.T) {
+	few := averagePopulation(t, RandomBoardOptions{NumClusters: 1, MinRadius: 3, MaxRadius: 3, Density: 1})
+	many := averagePopulation(t, RandomBoardOptions{NumClusters: 20, MinRadius: 3, MaxRadius: 3, Density: 1})
+	if few != 29 {
+		t.Errorf("a single full cluster of radius 3 has %v live cells, want 29", few)
+	}
+	if many <= few {
+		t.Errorf("20 clusters average %v live cells, not more than a single cluster's %v", many, few)
+	}
+}
+
+func TestSplatterDensity(t *testing.T) {
+	// A radius 5 disc in the middle of the board has 81 cells
+	live := func(density float64) int {
+		cells, err := AmInstance.Splatter(context.Background(), SplatterInput{Rows: 30, Cols: 30, Row: 15, Col: 15, Radius: 5, Density: density})
+		if err != nil {
+			t.Fatal(err)
+		}
+		distinct := map[[2]int]bool{}
+		for _, cell := range cells {
+			distinct[cell] = true
+		}
+		return len(distinct)
+	}
+	for _, test := range []struct {
+		density  float64
+		min, max int
+	}{
+		{0.25, 20, 21},
+		{0, 49, 50}, // The default 0.6
+		{1, 81, 81},
+	} {
+		if got := live(test.density); got < test.min || got > test.max {
+			t.Errorf("density %v brought %d cells alive, want %d to %d", test.density, got, test.min, test.max)
+		}
+	}
+}
+
+func TestSplatterXIsTheColumn(t *testing.T) {
+	game := newTestGame(t)
+	game.signalAt(time.Millisecond, SplatterSignalName, SplatterSignal{X: 20, Y: 3})
+	game.signalAt(2*time.Millisecond, SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: 1})
+	game.run(t, GameOfLifeInput{Board: emptyBoard(10, 30).Pack(), Paused: true, Step: 1, MaxSteps: 10, TickTime: time.Millisecond})
+
+	var board StateChange
+	game.query(t, "board", &board)
+	if fmt.Sprint(board.Flipped) != "[[3 20]]" {
+		t.Errorf("splatter at x 20, y 3 brought %v alive, want row 3, column 20", board.Flipped)
+	}
+}
+
+// Splatter runs as an activity while the game keeps ticking, and several games run at once.
+// The activity only picks cells and the workflow applies them, which `go test -race` checks here.
+func TestSplatterWhileTicking(t *testing.T) {
+	for n := range 4 {
+		t.Run(fmt.Sprint("game ", n), func(t *testing.T) {
+			t.Parallel()
+			seed := randomBoard(rand.New(rand.NewSource(int64(602+n))), 40, 40, 0.3)
+			game := newTestGame(t)
+			for i := range 10 {
+				game.signalAt(time.Duration(3*i+1)*time.Millisecond, SplatterSignalName, SplatterSignal{X: 4 * i, Y: 39 - 4*i, Size: 3})
+			}
+			game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 40, StoreInterval: 100, TickTime: time.Millisecond})
+
+			var board StateChange
+			game.query(t, "board", &board)
+			if board.Step != 40 {
+				t.Errorf("game ended at step %d, want 40", board.Step)
+			}
+			if replayed := game.replay(t, seed); !equalBoards(replayed, applyFrame(t, nil, board)) {
+				t.Errorf("the frames replay to\n%vnot the game's board\n%v", replayed, applyFrame(t, nil, board))
+			}
+			if equalBoards(applyFrame(t, nil, board), evolve(seed, 40)) {
+				t.Error("the game ended on the board it would have reached without the splatters")
+			}
+		})
+	}
+}
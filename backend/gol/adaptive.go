@@ -0,0 +1,48 @@
+package gol
+
+import "time"
+
+/* -------------------------------------------------------------------------- */
+/*                                Adaptive Tick                               */
+/* -------------------------------------------------------------------------- */
+
+// With an adaptive tick the game runs fast while the board is calm and slows down while it
+// is busy. The tick time scales linearly with the board's density from a fraction of the
+// configured tick time on an empty board up to the full tick time at the saturation density.
+// The population comes from the generation that was just computed, so it is deterministic.
+
+const (
+	MinAdaptiveTickFraction   = 0.1 // Tick time of an empty board as a fraction of the configured one
+	AdaptiveSaturationDensity = 0.3 // Density at which the game runs at the configured tick time
+)
+
+// AdaptiveTickTime returns the tick time for a board of the given population
+func (s GolState) AdaptiveTickTime(population int) time.Duration {
+	cells := 0
+	if len(s.Board) > 0 {
+		cells = len(s.Board) * len(s.Board[0])
+	}
+	if cells == 0 {
+		return s.TickTime
+	}
+
+	fraction := min(float64(population)/float64(cells)/AdaptiveSaturationDensity, 1)
+	fraction = MinAdaptiveTickFraction + (1-MinAdaptiveTickFraction)*fraction
+	return time.Duration(fraction * float64(s.TickTime))
+}
+
+// ApplyAdaptiveTick adjusts the tick time to the population of the current generation
+func (s *GolState) ApplyAdaptiveTick(population int) {
+	if !s.AdaptiveTick {
+		return
+	}
+	s.adaptiveTickTime = s.AdaptiveTickTime(population)
+}
+
+// baseTickTime returns the tick time before backpressure, the configured one unless the tick is adaptive
+func (s GolState) baseTickTime() time.Duration {
+	if s.AdaptiveTick && s.adaptiveTickTime > 0 {
+		return s.adaptiveTickTime
+	}
+	return s.TickTime
+}
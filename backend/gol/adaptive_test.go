@@ -0,0 +1,67 @@
+package gol
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTickTime(t *testing.T) {
+	state := GolState{Board: emptyBoard(100, 100), TickTime: time.Second, AdaptiveTick: true}
+	tests := []struct {
+		population int
+		want       time.Duration
+	}{
+		{0, 100 * time.Millisecond},    // Empty boards run at the fastest tick
+		{1500, 550 * time.Millisecond}, // Half the saturation density is half way
+		{3000, time.Second},            // The saturation density runs at the configured tick
+		{9000, time.Second},            // Denser boards don't go slower than that
+	}
+	for _, test := range tests {
+		if got := state.AdaptiveTickTime(test.population); got != test.want {
+			t.Errorf("population %d ticks every %v, want %v", test.population, got, test.want)
+		}
+	}
+}
+
+func TestDenserBoardTicksSlower(t *testing.T) {
+	// tickTimes runs the game and returns the effective tick time of its diff frames
+	tickTimes := func(seed Board, adaptive bool) []time.Duration {
+		game := newTestGame(t)
+		game.run(t, GameOfLifeInput{Board: seed.Pack(), Wrap: true, AdaptiveTick: adaptive, MaxSteps: 10, TickTime: 100 * time.Millisecond})
+		var ticks []time.Duration
+		for _, frame := range game.frames {
+			if !frame.Full && frame.Ended == "" {
+				ticks = append(ticks, frame.EffectiveTickTime)
+			}
+		}
+		return ticks
+	}
+
+	dense := randomBoard(rand.New(rand.NewSource(72)), 32, 32, 0.5)
+	sparse := emptyBoard(32, 32)
+	glider, _ := Pattern("glider")
+	sparse.Stamp(glider, 4, 4)
+
+	denseTicks, sparseTicks := tickTimes(dense, true), tickTimes(sparse, true)
+	if len(denseTicks) == 0 || len(sparseTicks) == 0 {
+		t.Fatal("a game published no diff frames")
+	}
+	for i := range min(len(denseTicks), len(sparseTicks)) {
+		if denseTicks[i] <= sparseTicks[i] {
+			t.Errorf("tick %d of the dense board is %v, not longer than the sparse board's %v", i+1, denseTicks[i], sparseTicks[i])
+		}
+	}
+	for _, tick := range append(denseTicks, sparseTicks...) {
+		if tick > 100*time.Millisecond {
+			t.Errorf("adaptive tick %v is slower than the configured tick", tick)
+		}
+	}
+
+	// Without the option both run at the configured tick
+	for _, tick := range append(tickTimes(dense, false), tickTimes(sparse, false)...) {
+		if tick != 100*time.Millisecond {
+			t.Errorf("fixed tick is %v, want the configured 100ms", tick)
+		}
+	}
+}
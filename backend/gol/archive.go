@@ -0,0 +1,128 @@
+package gol
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                   Archive                                  */
+/* -------------------------------------------------------------------------- */
+
+// A finished game's workflow can still be queried, but only until temporal's retention
+// removes it and only by replaying its last run. The final board is archived when the game
+// ends instead, like the state stream the store lives in the worker's process unless main
+// swaps it for a shared one.
+
+// Games the in-memory archive keeps before evicting the least recently used
+const DefaultArchiveSize = 100
+
+// Final state of a finished game
+type ArchivedGame struct {
+	Id         string       `json:"id"`
+	Step       int          `json:"step"`
+	Population int          `json:"population"`
+	Terminated bool         `json:"terminated"`       // Ended because the board settled rather than at MaxSteps
+	Period     int          `json:"period,omitempty"` // Period the board settled into
+	Board      *PackedBoard `json:"board"`
+	ArchivedAt time.Time    `json:"archivedAt"`
+}
+
+// Stores the final states of finished games
+type ArchiveStore interface {
+	Put(game ArchivedGame) error
+	Get(id string) (ArchivedGame, bool, error)
+}
+
+// Archive finished games are stored in
+var Archive ArchiveStore = NewMemoryArchive(DefaultArchiveSize)
+
+// In-memory archive evicting the least recently used game once full
+type MemoryArchive struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first
+}
+
+func NewMemoryArchive(size int) *MemoryArchive {
+	return &MemoryArchive{
+		size:    max(size, 1),
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Put stores the game, replacing an earlier game with the same id
+func (a *MemoryArchive) Put(game ArchivedGame) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if element, ok := a.entries[game.Id]; ok {
+		element.Value = game
+		a.order.MoveToFront(element)
+		return nil
+	}
+	a.entries[game.Id] = a.order.PushFront(game)
+	if a.order.Len() > a.size {
+		oldest := a.order.Back()
+		a.order.Remove(oldest)
+		delete(a.entries, oldest.Value.(ArchivedGame).Id)
+	}
+	return nil
+}
+
+func (a *MemoryArchive) Get(id string) (ArchivedGame, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	element, ok := a.entries[id]
+	if !ok {
+		return ArchivedGame{}, false, nil
+	}
+	a.order.MoveToFront(element)
+	return element.Value.(ArchivedGame), true, nil
+}
+
+type ArchiveInput struct {
+	Id         string
+	Step       int
+	Terminated bool
+	Period     int
+	Board      *PackedBoard
+}
+
+func (a *Am) ArchiveFinalState(ctx context.Context, input ArchiveInput) error {
+	if input.Board == nil {
+		return errors.New("archiving requires the final board")
+	}
+	if err := input.Board.Validate(); err != nil {
+		return err
+	}
+
+	return Archive.Put(ArchivedGame{
+		Id:         input.Id,
+		Step:       input.Step,
+		Population: input.Board.Unpack().Population(),
+		Terminated: input.Terminated,
+		Period:     input.Period,
+		Board:      input.Board,
+		ArchivedAt: time.Now(),
+	})
+}
+
+// ArchiveFinalState archives the game's board once it has ended
+func ArchiveFinalState(ctx workflow.Context, state GolState) error {
+	return DoActivity(ctx, AmInstance.ArchiveFinalState, ArchiveInput{
+		Id:         state.Id,
+		Step:       state.Step,
+		Terminated: state.Terminated,
+		Period:     state.Period,
+		Board:      state.Board.Pack(),
+	})
+}
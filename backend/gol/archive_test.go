@@ -0,0 +1,52 @@
+package gol
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+func TestFinalStateIsArchived(t *testing.T) {
+	defer func(archive ArchiveStore) { Archive = archive }(Archive)
+	Archive = NewMemoryArchive(DefaultArchiveSize)
+
+	blinker := parseBoard(
+		".....",
+		"..#..",
+		"..#..",
+		"..#..",
+		".....",
+	)
+	game := newTestGame(t)
+	game.env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: "archived"})
+	game.run(t, GameOfLifeInput{Board: blinker.Pack(), MaxSteps: 20, TickTime: time.Millisecond})
+
+	archived, ok, err := Archive.Get("archived")
+	if err != nil || !ok {
+		t.Fatalf("the finished game isn't archived (%v)", err)
+	}
+	// The cycle detector ends the blinker once it is back to its seed
+	if !archived.Terminated || archived.Period != 2 || archived.Step != 3 || archived.Population != 3 {
+		t.Errorf("archived %+v, want the blinker terminated at step 3 with period 2", archived)
+	}
+	if want := evolve(blinker, 3); fmt.Sprint(archived.Board.Unpack()) != fmt.Sprint(want) {
+		t.Errorf("archived board %v, want %v", archived.Board.Unpack(), want)
+	}
+}
+
+func TestMemoryArchiveEvictsTheLeastRecentlyUsed(t *testing.T) {
+	archive := NewMemoryArchive(2)
+	for _, id := range []string{"a", "b"} {
+		archive.Put(ArchivedGame{Id: id})
+	}
+	archive.Get("a")
+	archive.Put(ArchivedGame{Id: "c"})
+
+	for id, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := archive.Get(id); ok != want {
+			t.Errorf("game %s archived is %v, want %v", id, ok, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gol
+
+import "time"
+
+/* -------------------------------------------------------------------------- */
+/*                                Backpressure                                */
+/* -------------------------------------------------------------------------- */
+
+// With backpressure enabled the workflow slows down while subscribers can't keep up.
+// SendState reports how many subscribers still had the previous frame pending, and after
+// a few congested frames in a row the tick time doubles (up to a cap). Every frame that
+// gets through without a drop halves it again until it is back at the configured tick time.
+// The drop count comes back as the activity result, so the workflow stays deterministic.
+
+const (
+	BackpressureThreshold   = 3                     // Congested frames in a row before slowing down
+	MinBackpressureTickTime = 10 * time.Millisecond // First step up from a zero tick time
+	MaxBackpressureTickTime = 2 * time.Second       // Slowest the game gets (unless its tick time is slower)
+)
+
+// Result of publishing a state change
+type SendStateResult struct {
+	Dropped int // Subscribers whose previous frame was still pending and got merged
+}
+
+// EffectiveTickTime returns the tick time after the adaptive tick and backpressure adjusted it
+func (s GolState) EffectiveTickTime() time.Duration {
+	return max(s.baseTickTime(), s.BackpressureTickTime)
+}
+
+// ApplyBackpressure adjusts the tick time after publishing a frame
+func (s *GolState) ApplyBackpressure(result SendStateResult) {
+	if !s.Backpressure {
+		return
+	}
+
+	if result.Dropped == 0 {
+		s.congestedFrames = 0
+		s.BackpressureTickTime /= 2
+		if s.BackpressureTickTime <= s.TickTime || s.BackpressureTickTime < MinBackpressureTickTime {
+			s.BackpressureTickTime = 0
+		}
+		return
+	}
+
+	s.congestedFrames++
+	if s.congestedFrames < BackpressureThreshold {
+		return
+	}
+	s.congestedFrames = 0
+	slowed := max(s.EffectiveTickTime()*2, MinBackpressureTickTime)
+	s.BackpressureTickTime = min(slowed, max(MaxBackpressureTickTime, s.TickTime))
+}
@@ -0,0 +1,267 @@
+package gol
+
+import "math/bits"
+
+/* -------------------------------------------------------------------------- */
+/*                                  Bit Board                                 */
+/* -------------------------------------------------------------------------- */
+
+// Alternative board representation for large grids
+// Each row is packed into uint64 words so a generation is computed 64 cells at a time,
+// and the same pair of boards is reused every generation instead of allocating a new [][]bool.
+// Full scans of the workflow go through it (see bitScratch), the Flipped wire format is unchanged.
+
+// Bit packed board, bit k of word w in a row is the cell at column w*64+k
+type BitBoard struct {
+	Rows  int
+	Cols  int
+	Words int // Words per row
+	Bits  []uint64
+}
+
+// NewBitBoard returns an all dead bit board
+func NewBitBoard(rows, cols int) *BitBoard {
+	words := (cols + 63) / 64
+	return &BitBoard{
+		Rows:  rows,
+		Cols:  cols,
+		Words: words,
+		Bits:  make([]uint64, rows*words),
+	}
+}
+
+// BitBoardFrom packs a board into a bit board
+func BitBoardFrom(board Board) *BitBoard {
+	cols := 0
+	if len(board) > 0 {
+		cols = len(board[0])
+	}
+	b := NewBitBoard(len(board), cols)
+	b.Load(board)
+	return b
+}
+
+// Get returns whether the cell at (i, j) is alive
+func (b *BitBoard) Get(i, j int) bool {
+	return b.Bits[i*b.Words+j/64]&(1<<(j%64)) != 0
+}
+
+// Set sets the cell at (i, j) alive or dead
+func (b *BitBoard) Set(i, j int, alive bool) {
+	if alive {
+		b.Bits[i*b.Words+j/64] |= 1 << (j % 64)
+	} else {
+		b.Bits[i*b.Words+j/64] &^= 1 << (j % 64)
+	}
+}
+
+// Board expands the bit board back into a board
+func (b *BitBoard) Board() Board {
+	board := make(Board, b.Rows)
+	for i := range board {
+		board[i] = make([]bool, b.Cols)
+		for j := range board[i] {
+			board[i][j] = b.Get(i, j)
+		}
+	}
+	return board
+}
+
+// row returns the words of row i, or nil when i is off the board (all dead)
+func (b *BitBoard) row(i int) []uint64 {
+	if i < 0 || i >= b.Rows {
+		return nil
+	}
+	return b.Bits[i*b.Words : (i+1)*b.Words]
+}
+
+// Load packs the board into b, which must have the board's dimensions
+func (b *BitBoard) Load(board Board) {
+	clear(b.Bits)
+	for i, row := range board {
+		for j, cell := range row {
+			if cell {
+				b.Bits[i*b.Words+j/64] |= 1 << (j % 64)
+			}
+		}
+	}
+}
+
+// NextGeneration writes the next generation under the rule into next (which must have the same dimensions)
+// Same result as NextGeneration, computed 64 cells at a time with a bit sliced neighbor count
+// walls holds the rule's walls packed like the board, nil when there are none
+func (b *BitBoard) NextGeneration(next *BitBoard, rule Rule, walls *BitBoard) {
+	if b.Rows == 0 || b.Cols == 0 {
+		return
+	}
+
+	// Mask of the valid columns in the last word of a row
+	lastMask := ^uint64(0)
+	if b.Cols%64 != 0 {
+		lastMask = 1<<(b.Cols%64) - 1
+	}
+
+	// Live walls are counted as neighbors, the rows are merged with their walls into these
+	var merged [3][]uint64
+	if walls != nil && rule.WallsAlive {
+		for k := range merged {
+			merged[k] = make([]uint64, b.Words)
+		}
+	}
+
+	birth, survival := rule.counts()
+	for i := 0; i < b.Rows; i++ {
+		above := b.countedRow(i-1, rule, walls, merged[0])
+		current := b.countedRow(i, rule, walls, merged[1])
+		below := b.countedRow(i+1, rule, walls, merged[2])
+		alive := b.row(i)
+		out := next.row(i)
+		for w := 0; w < b.Words; w++ {
+			// Sum the neighbors into a 4 bit counter, one independent counter per bit position
+			var count [4]uint64
+			west, east := b.shiftedNeighbors(current, w, rule.Wrap)
+			addBits(&count, west)
+			addBits(&count, east)
+			for _, r := range [2][]uint64{above, below} {
+				if r == nil {
+					continue
+				}
+				addBits(&count, r[w])
+				if rule.Neighborhood != VonNeumann {
+					west, east := b.shiftedNeighbors(r, w, rule.Wrap)
+					addBits(&count, west)
+					addBits(&count, east)
+				}
+			}
+
+			var word uint64
+			for n := 0; n <= 8; n++ {
+				if !birth.Has(n) && !survival.Has(n) {
+					continue
+				}
+				eq := countEquals(&count, n)
+				if birth.Has(n) {
+					word |= eq &^ alive[w]
+				}
+				if survival.Has(n) {
+					word |= eq & alive[w]
+				}
+			}
+			if walls != nil {
+				word &^= walls.row(i)[w]
+			}
+			out[w] = word
+		}
+		out[b.Words-1] &= lastMask
+	}
+}
+
+// countedRow returns the words of row i counted as live neighbors, nil when the row is off the board
+// Wrapped rules wrap the row around, live walls are merged with the row into buf
+func (b *BitBoard) countedRow(i int, rule Rule, walls *BitBoard, buf []uint64) []uint64 {
+	if rule.Wrap {
+		i = wrapIndex(i, b.Rows)
+	}
+	r := b.row(i)
+	if r == nil || buf == nil {
+		return r
+	}
+	for w, word := range r {
+		buf[w] = word | walls.row(i)[w]
+	}
+	return buf
+}
+
+// addBits adds x into the bit sliced counter, one independent counter per bit position
+func addBits(count *[4]uint64, x uint64) {
+	for k := range count {
+		carry := count[k] & x
+		count[k] ^= x
+		x = carry
+	}
+}
+
+// countEquals returns the bits whose counter equals n
+func countEquals(count *[4]uint64, n int) uint64 {
+	eq := ^uint64(0)
+	for k, slice := range count {
+		if n&(1<<k) != 0 {
+			eq &= slice
+		} else {
+			eq &^= slice
+		}
+	}
+	return eq
+}
+
+// shiftedNeighbors returns the west (col-1) and east (col+1) neighbors of each cell in word w
+// With wrap the first column's west neighbor is the last column and the other way around
+func (b *BitBoard) shiftedNeighbors(r []uint64, w int, wrap bool) (west uint64, east uint64) {
+	last := uint(b.Cols-1) % 64
+	west = r[w] << 1
+	if w > 0 {
+		west |= r[w-1] >> 63
+	} else if wrap {
+		west |= r[len(r)-1] >> last & 1
+	}
+	east = r[w] >> 1
+	if w+1 < len(r) {
+		east |= r[w+1] << 63
+	} else if wrap {
+		east |= (r[0] & 1) << last
+	}
+	return west, east
+}
+
+// DiffFlipped returns the cells that differ between b and curr in the same [row, col] format as DiffFlipped
+func (b *BitBoard) DiffFlipped(curr *BitBoard) [][2]int {
+	return b.DiffFlippedInto(curr, nil)
+}
+
+// DiffFlippedInto is DiffFlipped appending to buf[:0] (see DiffFlippedInto)
+func (b *BitBoard) DiffFlippedInto(curr *BitBoard, buf [][2]int) [][2]int {
+	flipped := buf[:0]
+	for i := 0; i < b.Rows; i++ {
+		for w := 0; w < b.Words; w++ {
+			diff := b.Bits[i*b.Words+w] ^ curr.Bits[i*b.Words+w]
+			for diff != 0 {
+				k := bits.TrailingZeros64(diff)
+				flipped = append(flipped, [2]int{i, w*64 + k})
+				diff &= diff - 1
+			}
+		}
+	}
+	return flipped
+}
+
+// Pair of bit boards full scans compute the next generation with, reused across generations
+// The zero value is ready to use, the boards are (re)allocated whenever the board's size changes
+type bitScratch struct {
+	curr, next, walls *BitBoard
+}
+
+// nextGeneration advances the board in place and returns the flipped cells written into buf
+func (s *bitScratch) nextGeneration(board Board, rule Rule, buf [][2]int) [][2]int {
+	if len(board) == 0 {
+		return buf[:0]
+	}
+	rows, cols := len(board), len(board[0])
+	if s.curr == nil || s.curr.Rows != rows || s.curr.Cols != cols {
+		s.curr, s.next, s.walls = NewBitBoard(rows, cols), NewBitBoard(rows, cols), nil
+	}
+	s.curr.Load(board)
+
+	var walls *BitBoard
+	if rule.Walls != nil {
+		if s.walls == nil {
+			s.walls = NewBitBoard(rows, cols)
+		}
+		s.walls.Load(rule.Walls)
+		walls = s.walls
+	}
+
+	s.curr.NextGeneration(s.next, rule, walls)
+	flipped := s.curr.DiffFlippedInto(s.next, buf)
+	board.Toggle(flipped)
+	return flipped
+}
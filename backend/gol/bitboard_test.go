@@ -0,0 +1,119 @@
+package gol
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// Rules the bit board has to compute exactly like NextGeneration
+var bitBoardRules = map[string]func(rows, cols int, random *rand.Rand) Rule{
+	"conway": func(int, int, *rand.Rand) Rule { return Rule{} },
+	"von neumann": func(int, int, *rand.Rand) Rule {
+		return Rule{Neighborhood: VonNeumann, Birth: 1<<1 | 1<<2, Survival: 1<<1 | 1<<3}
+	},
+	"highlife": func(int, int, *rand.Rand) Rule { return Rule{Birth: 1<<3 | 1<<6, Survival: ConwaySurvival} },
+	"wrap":     func(int, int, *rand.Rand) Rule { return Rule{Wrap: true} },
+	"walls": func(rows, cols int, random *rand.Rand) Rule {
+		return Rule{Walls: randomBoard(random, rows, cols, 0.1)}
+	},
+	"live walls": func(rows, cols int, random *rand.Rand) Rule {
+		return Rule{Walls: randomBoard(random, rows, cols, 0.1), WallsAlive: true, Wrap: true}
+	},
+}
+
+func TestBitBoardMatchesNextGeneration(t *testing.T) {
+	random := rand.New(rand.NewSource(5))
+	for name, newRule := range bitBoardRules {
+		// Sizes around the word boundaries, and boards small enough for wrapped neighbors to repeat
+		for _, size := range [][2]int{{1, 1}, {2, 3}, {7, 63}, {5, 64}, {9, 65}, {33, 130}} {
+			t.Run(fmt.Sprintf("%s %dx%d", name, size[0], size[1]), func(t *testing.T) {
+				rule := newRule(size[0], size[1], random)
+				board := randomBoard(random, size[0], size[1], 0.4)
+				if rule.Walls != nil {
+					board.Toggle(keepOnWalls(board, rule.Walls))
+				}
+
+				var scratch bitScratch
+				for generation := range 8 {
+					want := NextGeneration(board, rule)
+					wantFlipped := DiffFlipped(board, want)
+					flipped := scratch.nextGeneration(board, rule, nil)
+					if fmt.Sprint(flipped) != fmt.Sprint(wantFlipped) {
+						t.Fatalf("generation %d flipped %v, want %v", generation, flipped, wantFlipped)
+					}
+					if !equalBoards(board, want) {
+						t.Fatalf("generation %d =\n%vwant\n%v", generation, board, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+// keepOnWalls returns the live cells on walls, walls are never alive
+func keepOnWalls(board, walls Board) [][2]int {
+	var cells [][2]int
+	for _, cell := range board.LiveCells() {
+		if walls[cell[0]][cell[1]] {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+func BenchmarkNextGeneration(b *testing.B) {
+	board := randomBoard(rand.New(rand.NewSource(6)), 512, 512, 0.3)
+	var buf [][2]int
+	b.ReportAllocs()
+	for b.Loop() {
+		next := NextGeneration(board, Rule{})
+		buf = DiffFlippedInto(board, next, buf)
+		board = next
+	}
+}
+
+// Compare allocs/op, a fresh slice every generation against one reused across them
+func BenchmarkDiffFlipped(b *testing.B) {
+	board := randomBoard(rand.New(rand.NewSource(6)), 512, 512, 0.3)
+	next := NextGeneration(board, Rule{})
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			DiffFlipped(board, next)
+		}
+	})
+	b.Run("reused", func(b *testing.B) {
+		var buf [][2]int
+		b.ReportAllocs()
+		for b.Loop() {
+			buf = DiffFlippedInto(board, next, buf)
+		}
+	})
+}
+
+func TestDiffFlippedIntoReusesTheBuffer(t *testing.T) {
+	board := randomBoard(rand.New(rand.NewSource(60)), 64, 64, 0.3)
+	next := NextGeneration(board, Rule{})
+	want := DiffFlipped(board, next)
+
+	// Leftovers of a bigger diff are cut off rather than kept
+	buf := DiffFlippedInto(board, emptyBoard(64, 64), nil)
+	buf = DiffFlippedInto(board, next, buf)
+	if fmt.Sprint(buf) != fmt.Sprint(want) {
+		t.Fatalf("diff into a used buffer is %v, want %v", buf, want)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { buf = DiffFlippedInto(board, next, buf) }); allocs != 0 {
+		t.Errorf("diffing into a grown buffer allocates %v times", allocs)
+	}
+}
+
+func BenchmarkBitBoardNextGeneration(b *testing.B) {
+	board := randomBoard(rand.New(rand.NewSource(6)), 512, 512, 0.3)
+	var scratch bitScratch
+	var buf [][2]int
+	b.ReportAllocs()
+	for b.Loop() {
+		buf = scratch.nextGeneration(board, Rule{}, buf)
+	}
+}
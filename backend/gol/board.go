@@ -0,0 +1,287 @@
+package gol
+
+import (
+	"fmt"
+	"sort"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Board Helpers                               */
+/* -------------------------------------------------------------------------- */
+
+// Bit packed board used to carry the board through workflow inputs
+// A 512x512 board packs into 32KB rather than ~1.4MB of JSON booleans
+type PackedBoard struct {
+	Rows int    `json:"rows"`
+	Cols int    `json:"cols"`
+	Bits []byte `json:"bits"` // Row-major, one bit per cell (least significant bit first)
+}
+
+// Pack packs the board into one bit per cell
+func (b Board) Pack() *PackedBoard {
+	packed := &PackedBoard{Rows: len(b)}
+	if len(b) > 0 {
+		packed.Cols = len(b[0])
+	}
+	packed.Bits = make([]byte, (packed.Rows*packed.Cols+7)/8)
+	for i, row := range b {
+		for j, cell := range row {
+			if cell {
+				index := i*packed.Cols + j
+				packed.Bits[index/8] |= 1 << (index % 8)
+			}
+		}
+	}
+	return packed
+}
+
+// Validate checks the dimensions against the bits so Unpack can't index past them
+// The size is checked first so an oversized board is rejected before anything is allocated for it
+func (p *PackedBoard) Validate() error {
+	if p.Rows < 0 || p.Cols < 0 {
+		return fmt.Errorf("packed board dimensions must not be negative, got %dx%d", p.Rows, p.Cols)
+	}
+	if err := ValidateBoardSize(p.Rows, p.Cols); err != nil {
+		return err
+	}
+	if need := (p.Rows*p.Cols + 7) / 8; len(p.Bits) < need {
+		return fmt.Errorf("packed %dx%d board needs %d bytes of bits, got %d", p.Rows, p.Cols, need, len(p.Bits))
+	}
+	return nil
+}
+
+// Unpack expands the packed bits back into a board
+func (p *PackedBoard) Unpack() Board {
+	board := make(Board, p.Rows)
+	for i := range board {
+		board[i] = make([]bool, p.Cols)
+		for j := range board[i] {
+			index := i*p.Cols + j
+			board[i][j] = p.Bits[index/8]&(1<<(index%8)) != 0
+		}
+	}
+	return board
+}
+
+// Dimensions returns the board's rows and columns
+// Every row has to be as long as the first, the generation code indexes neighbors assuming it
+func (b Board) Dimensions() (rows, cols int, err error) {
+	rows = len(b)
+	if rows == 0 {
+		return 0, 0, nil
+	}
+	cols = len(b[0])
+	for i, row := range b {
+		if len(row) != cols {
+			return 0, 0, fmt.Errorf("board is ragged, row %d has %d cells but row 0 has %d", i, len(row), cols)
+		}
+	}
+	return rows, cols, nil
+}
+
+// Every generation scans the whole board and a Board takes a byte per cell,
+// so these keep a single game from running the worker out of memory
+const (
+	MaxBoardSide  = 4096    // Biggest number of rows or columns a board can have
+	MaxBoardCells = 1 << 22 // Biggest number of cells a board can have (4MB as a Board, e.g. 2048x2048)
+)
+
+// Application error type of boards failing ValidateBoardSize or Validate, retrying can't fix them
+const InvalidBoardErrorType = "InvalidBoard"
+
+// ValidateBoardSize checks the dimensions are within MaxBoardSide and MaxBoardCells
+// Checked before allocating a board, so the error is descriptive rather than an out of memory worker
+func ValidateBoardSize(rows, cols int) error {
+	if rows == 0 || cols == 0 {
+		return fmt.Errorf("board must have at least one cell, got %dx%d", rows, cols)
+	}
+	if rows > MaxBoardSide || cols > MaxBoardSide {
+		return fmt.Errorf("board can be at most %dx%d, got %dx%d", MaxBoardSide, MaxBoardSide, rows, cols)
+	}
+	if rows*cols > MaxBoardCells {
+		return fmt.Errorf("board can have at most %d cells, got %dx%d (%d cells)", MaxBoardCells, rows, cols, rows*cols)
+	}
+	return nil
+}
+
+// invalidBoardError fails the workflow with the board's error as an InvalidBoardErrorType,
+// so whoever started it can tell a rejected board from a failed game
+func invalidBoardError(err error) error {
+	return temporal.NewNonRetryableApplicationError(err.Error(), InvalidBoardErrorType, nil)
+}
+
+// Validate checks the board is a non-empty rectangle within the size limits (see ValidateBoardSize)
+// The handlers and the generation code index the board assuming all of this
+func (b Board) Validate() error {
+	rows, cols, err := b.Dimensions()
+	if err != nil {
+		return err
+	}
+	return ValidateBoardSize(rows, cols)
+}
+
+// Clone returns a deep copy of the board
+func (b Board) Clone() Board {
+	clone := make(Board, len(b))
+	for i := range b {
+		clone[i] = append([]bool(nil), b[i]...)
+	}
+	return clone
+}
+
+// LiveCells returns the [row, col] of every live cell in row-major order
+func (b Board) LiveCells() [][2]int {
+	var cells [][2]int
+	for i, row := range b {
+		for j, cell := range row {
+			if cell {
+				cells = append(cells, [2]int{i, j})
+			}
+		}
+	}
+	return cells
+}
+
+// Population returns the number of live cells
+func (b Board) Population() int {
+	population := 0
+	for _, row := range b {
+		for _, cell := range row {
+			if cell {
+				population++
+			}
+		}
+	}
+	return population
+}
+
+// SetAlive brings the given cells alive and returns the ones that actually flipped
+func (b Board) SetAlive(cells [][2]int) [][2]int {
+	var flipped [][2]int
+	for _, cell := range cells {
+		r, c := cell[0], cell[1]
+		if r < 0 || r >= len(b) || c < 0 || c >= len(b[r]) || b[r][c] {
+			continue
+		}
+		b[r][c] = true
+		flipped = append(flipped, cell)
+	}
+	return flipped
+}
+
+// Fraction of the board above which tracking the active region costs more than a full scan
+const ActiveScanFraction = 0.1
+
+// NextGenerationActive advances the board in place and returns the flipped cells
+// Only the cells that changed last generation and their neighbors can change, so those are the only cells evaluated
+// Falls back to a full scan on the scratch bit boards when fullScan is set or the active region is too large
+// The flipped cells match DiffFlipped(board, NextGeneration(board, rule)) exactly, including the row-major order
+// Full scans write the flipped cells into buf (see DiffFlippedInto), which may be changed itself
+func NextGenerationActive(board Board, rule Rule, changed [][2]int, fullScan bool, scratch *bitScratch, buf [][2]int) [][2]int {
+	if len(board) == 0 {
+		return nil
+	}
+	rows, cols := len(board), len(board[0])
+
+	if fullScan || float64(len(changed)*9) > ActiveScanFraction*float64(rows*cols) {
+		return scratch.nextGeneration(board, rule, buf)
+	}
+
+	// Collect the changed cells and their neighbors
+	candidates := make(map[[2]int]struct{}, len(changed)*9)
+	for _, cell := range changed {
+		for x := -1; x <= 1; x++ {
+			for y := -1; y <= 1; y++ {
+				if r, c, ok := rule.neighborCell(rows, cols, cell[0]+x, cell[1]+y); ok {
+					candidates[[2]int{r, c}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	// Evaluate against the current board before applying any flips
+	var flipped [][2]int
+	for cell := range candidates {
+		i, j := cell[0], cell[1]
+		if rule.isWall(i, j) {
+			continue
+		}
+		aliveNeighbors := countAliveNeighbors(board, rule, i, j)
+		next := rule.next(board[i][j], aliveNeighbors)
+		if next != board[i][j] {
+			flipped = append(flipped, cell)
+		}
+	}
+	sort.Slice(flipped, func(a, b int) bool {
+		if flipped[a][0] != flipped[b][0] {
+			return flipped[a][0] < flipped[b][0]
+		}
+		return flipped[a][1] < flipped[b][1]
+	})
+
+	board.Toggle(flipped)
+	return flipped
+}
+
+// Toggle flips each of the given cells
+func (b Board) Toggle(cells [][2]int) {
+	for _, cell := range cells {
+		b[cell[0]][cell[1]] = !b[cell[0]][cell[1]]
+	}
+}
+
+// ToggleCells toggles each distinct cell once, skipping cells outside the board and walls
+// Returns the toggled cells in row-major order
+func (s *GolState) ToggleCells(cells [][2]int) [][2]int {
+	seen := make(map[[2]int]struct{}, len(cells))
+	var flipped [][2]int
+	for _, cell := range cells {
+		i, j := cell[0], cell[1]
+		if i < 0 || i >= len(s.Board) || j < 0 || j >= len(s.Board[i]) || s.Rule().isWall(i, j) {
+			continue
+		}
+		if _, ok := seen[cell]; ok {
+			continue
+		}
+		seen[cell] = struct{}{}
+		flipped = append(flipped, cell)
+	}
+	sort.Slice(flipped, func(a, b int) bool {
+		if flipped[a][0] != flipped[b][0] {
+			return flipped[a][0] < flipped[b][0]
+		}
+		return flipped[a][1] < flipped[b][1]
+	})
+
+	s.Board.Toggle(flipped)
+	return flipped
+}
+
+// Region returns the live cells within the rectangle clamped to the board
+func (b Board) Region(request RegionRequest) (Region, error) {
+	if request.Height <= 0 || request.Width <= 0 {
+		return Region{}, fmt.Errorf("region must have a positive area, got %dx%d", request.Height, request.Width)
+	}
+
+	rows, cols := len(b), 0
+	if rows > 0 {
+		cols = len(b[0])
+	}
+	top, left := max(request.Row, 0), max(request.Col, 0)
+	bottom, right := min(request.Row+request.Height, rows), min(request.Col+request.Width, cols)
+	if top >= bottom || left >= right {
+		return Region{}, fmt.Errorf("region at (%d, %d) of %dx%d is outside the %dx%d board", request.Row, request.Col, request.Height, request.Width, rows, cols)
+	}
+
+	region := Region{Row: top, Col: left, Height: bottom - top, Width: right - left, Cells: [][2]int{}}
+	for i := top; i < bottom; i++ {
+		for j := left; j < right; j++ {
+			if b[i][j] {
+				region.Cells = append(region.Cells, [2]int{i - top, j - left})
+			}
+		}
+	}
+	return region, nil
+}
@@ -0,0 +1,204 @@
+package gol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/temporal"
+)
+
+func FuzzNextGenerationActive(f *testing.F) {
+	f.Add(int64(1), uint8(16), uint8(16), uint8(0), uint8(0))
+	f.Add(int64(2), uint8(40), uint8(70), uint8(1), uint8(10))
+	f.Add(int64(3), uint8(3), uint8(5), uint8(2|4), uint8(40))
+	f.Add(int64(4), uint8(64), uint8(64), uint8(1|2|4|8), uint8(25))
+
+	// Bits of options: wrap, walls, von neumann, live walls
+	f.Fuzz(func(t *testing.T, seed int64, rows, cols, options, density uint8) {
+		if rows == 0 || cols == 0 {
+			return
+		}
+		random := rand.New(rand.NewSource(seed))
+		rule := Rule{Wrap: options&1 != 0, WallsAlive: options&8 != 0}
+		if options&4 != 0 {
+			rule.Neighborhood = VonNeumann
+			rule.Birth, rule.Survival = 1<<1|1<<2, 1<<1|1<<3
+		}
+		if options&2 != 0 {
+			rule.Walls = randomBoard(random, int(rows), int(cols), 0.1)
+		}
+
+		// Sparse boards keep the active region small enough to skip the full scan
+		board := randomBoard(random, int(rows), int(cols), float64(density%50)/100)
+		if rule.Walls != nil {
+			board.Toggle(keepOnWalls(board, rule.Walls))
+		}
+
+		var scratch bitScratch
+		var changed [][2]int
+		for generation := range 6 {
+			want := DiffFlipped(board, NextGeneration(board, rule))
+			changed = NextGenerationActive(board, rule, changed, generation == 0, &scratch, nil)
+			if fmt.Sprint(changed) != fmt.Sprint(want) {
+				t.Fatalf("generation %d flipped %v, want %v", generation, changed, want)
+			}
+		}
+	})
+}
+
+func TestRegion(t *testing.T) {
+	board := parseBoard(
+		"#.....",
+		".##...",
+		"..#.#.",
+		"....##",
+	)
+	tests := []struct {
+		request RegionRequest
+		want    Region // Without the step
+	}{
+		{RegionRequest{Row: 1, Col: 1, Height: 2, Width: 3}, Region{Row: 1, Col: 1, Height: 2, Width: 3, Cells: [][2]int{{0, 0}, {0, 1}, {1, 1}}}},
+		// Clamped to the board on every side
+		{RegionRequest{Row: 2, Col: 3, Height: 10, Width: 10}, Region{Row: 2, Col: 3, Height: 2, Width: 3, Cells: [][2]int{{0, 1}, {1, 1}, {1, 2}}}},
+		{RegionRequest{Row: -2, Col: -2, Height: 3, Width: 3}, Region{Row: 0, Col: 0, Height: 1, Width: 1, Cells: [][2]int{{0, 0}}}},
+		// Nothing alive in it
+		{RegionRequest{Row: 0, Col: 3, Height: 2, Width: 3}, Region{Row: 0, Col: 3, Height: 2, Width: 3, Cells: [][2]int{}}},
+	}
+	for _, test := range tests {
+		got, err := board.Region(test.request)
+		if err != nil {
+			t.Errorf("region %+v: %v", test.request, err)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("region %+v = %+v, want %+v", test.request, got, test.want)
+		}
+	}
+
+	for _, request := range []RegionRequest{
+		{Height: 0, Width: 3},
+		{Height: 3, Width: -1},
+		{Row: 4, Col: 0, Height: 2, Width: 2}, // Below the board
+		{Row: -5, Col: 0, Height: 2, Width: 2},
+	} {
+		if _, err := board.Region(request); err == nil {
+			t.Errorf("region %+v didn't fail", request)
+		}
+	}
+}
+
+func TestRegionQuery(t *testing.T) {
+	board := emptyBoard(20, 20)
+	board.Toggle([][2]int{{10, 10}, {10, 11}, {11, 10}, {11, 11}}) // A block, it stays put
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: board.Pack(), MaxSteps: 1, TickTime: time.Millisecond})
+
+	var region Region
+	game.query(t, "region", &region, RegionRequest{Row: 9, Col: 10, Height: 3, Width: 3})
+	want := Region{Step: 1, Row: 9, Col: 10, Height: 3, Width: 3, Cells: [][2]int{{1, 0}, {1, 1}, {2, 0}, {2, 1}}}
+	if fmt.Sprint(region) != fmt.Sprint(want) {
+		t.Errorf("region = %+v, want %+v", region, want)
+	}
+	if _, err := game.env.QueryWorkflow("region", RegionRequest{Row: 5, Col: 5}); err == nil {
+		t.Error("zero area region didn't fail")
+	}
+}
+
+func TestNextGenerationOfAnEmptyBoard(t *testing.T) {
+	for name, board := range map[string]Board{
+		"nil":        nil,
+		"no rows":    {},
+		"no columns": {{}, {}},
+	} {
+		for _, rule := range []Rule{{}, {Wrap: true}, {Neighborhood: VonNeumann}} {
+			next := NextGeneration(board, rule)
+			if len(next) != len(board) || len(next.LiveCells()) != 0 {
+				t.Errorf("%s board with %+v became %v", name, rule, next)
+			}
+			if next := NextGenerationParallel(board, rule, 4); len(next) != len(board) {
+				t.Errorf("%s board with %+v became %v in parallel", name, rule, next)
+			}
+		}
+		if err := board.Validate(); err == nil {
+			t.Errorf("%s board is valid", name)
+		}
+	}
+}
+
+func TestRaggedBoardIsRejected(t *testing.T) {
+	ragged := Board{make([]bool, 8), make([]bool, 8), make([]bool, 5)}
+	if err := ragged.Validate(); err == nil || !strings.Contains(err.Error(), "row 2 has 5 cells") {
+		t.Errorf("ragged board validated with %v", err)
+	}
+
+	// A board that comes out of an activity ragged fails the game instead of the worker
+	game := newTestGame(t)
+	attempts := 0
+	game.env.OnActivity(AmInstance.GetInitialBoard, mock.Anything, mock.Anything).Return(
+		func(context.Context, GetInitialBoardInput) (Board, error) {
+			attempts++
+			return ragged, nil
+		})
+	game.env.ExecuteWorkflow(GameOfLife, GameOfLifeInput{Rows: 3, Cols: 8, TickTime: time.Millisecond})
+
+	var applicationErr *temporal.ApplicationError
+	if err := game.env.GetWorkflowError(); !errors.As(err, &applicationErr) || applicationErr.Type() != InvalidBoardErrorType {
+		t.Fatalf("game ended with %v, want an %s error", err, InvalidBoardErrorType)
+	}
+	if attempts != 1 {
+		t.Errorf("the board was fetched %d times, retrying can't fix it", attempts)
+	}
+	if len(game.frames) != 0 {
+		t.Errorf("the ragged board was published %d times", len(game.frames))
+	}
+}
+
+func TestBoardValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		board   Board
+		wantErr string // Empty for a valid board
+	}{
+		{"valid", emptyBoard(3, 5), ""},
+		{"single cell", Board{{true}}, ""},
+		{"empty", Board{}, "at least one cell"},
+		{"no columns", Board{{}, {}}, "at least one cell"},
+		{"ragged", Board{make([]bool, 4), make([]bool, 3)}, "row 1 has 3 cells but row 0 has 4"},
+		{"too wide", Board{make([]bool, MaxBoardSide+1)}, "at most"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.board.Validate()
+			if test.wantErr == "" && err != nil {
+				t.Errorf("valid board failed with %v", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Errorf("validated with %v, want an error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestOversizedBoardFailsTheGame(t *testing.T) {
+	for _, size := range [][2]int{{100000, 100000}, {MaxBoardSide + 1, 8}, {3000, 3000}} {
+		game := newTestGame(t)
+		game.env.ExecuteWorkflow(GameOfLife, GameOfLifeInput{Rows: size[0], Cols: size[1], TickTime: time.Millisecond})
+
+		var applicationErr *temporal.ApplicationError
+		if err := game.env.GetWorkflowError(); !errors.As(err, &applicationErr) || applicationErr.Type() != InvalidBoardErrorType || !strings.Contains(err.Error(), "at most") {
+			t.Errorf("a %dx%d game ended with %v, want an %s error", size[0], size[1], err, InvalidBoardErrorType)
+		}
+
+		// The activity checks it too before allocating anything
+		_, err := AmInstance.GetRandomBoard(context.Background(), GetRandomBoardInput{Length: size[0], Width: size[1]})
+		if !errors.As(err, &applicationErr) || !applicationErr.NonRetryable() {
+			t.Errorf("a %dx%d random board failed with %v, want a non retryable error", size[0], size[1], err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package gol
+
+import (
+	"context"
+	"errors"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Board Stats                                 */
+/* -------------------------------------------------------------------------- */
+
+// Live cells touching in any of the 8 directions belong to the same component, the same
+// neighborhood the rules count, so a glider or a block is always a single component.
+//
+// A running game's board is served by the boardStats query, any other board by the BoardStats
+// activity, both count the same way.
+
+// Inclusive bounds of the live cells
+type BoundingBox struct {
+	Top    int `json:"top"`
+	Left   int `json:"left"`
+	Bottom int `json:"bottom"`
+	Right  int `json:"right"`
+}
+
+// Statistics of a board served by the boardStats query and the BoardStats activity
+type BoardStatsResult struct {
+	Step        int          `json:"step"`
+	Population  int          `json:"population"`
+	Components  int          `json:"components"`            // Connected groups of live cells
+	BoundingBox *BoundingBox `json:"boundingBox,omitempty"` // Nil when the board is empty
+}
+
+// BoardStats computes the statistics of a board outside a running game, Step is left at zero
+func (a *Am) BoardStats(ctx context.Context, board *PackedBoard) (BoardStatsResult, error) {
+	// Retrying can't fix the board
+	if board == nil {
+		return BoardStatsResult{}, invalidBoardError(errors.New("board stats requires a board"))
+	}
+	if err := board.Validate(); err != nil {
+		return BoardStatsResult{}, invalidBoardError(err)
+	}
+	return BoardStats(board.Unpack()), nil
+}
+
+// BoardStats counts the live cells and their connected components and bounds them
+func BoardStats(board Board) BoardStatsResult {
+	var stats BoardStatsResult
+
+	visited := make([][]bool, len(board))
+	for i := range board {
+		visited[i] = make([]bool, len(board[i]))
+	}
+
+	var stack [][2]int
+	for i := range board {
+		for j, alive := range board[i] {
+			if !alive {
+				continue
+			}
+
+			stats.Population++
+			if stats.BoundingBox == nil {
+				stats.BoundingBox = &BoundingBox{Top: i, Left: j, Bottom: i, Right: j}
+			}
+			box := stats.BoundingBox
+			box.Left, box.Right, box.Bottom = min(box.Left, j), max(box.Right, j), i
+
+			if visited[i][j] {
+				continue
+			}
+
+			// Flood fill the new component
+			stats.Components++
+			visited[i][j] = true
+			stack = append(stack[:0], [2]int{i, j})
+			for len(stack) > 0 {
+				cell := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for x := -1; x <= 1; x++ {
+					for y := -1; y <= 1; y++ {
+						r, c := cell[0]+x, cell[1]+y
+						if r < 0 || r >= len(board) || c < 0 || c >= len(board[r]) || !board[r][c] || visited[r][c] {
+							continue
+						}
+						visited[r][c] = true
+						stack = append(stack, [2]int{r, c})
+					}
+				}
+			}
+		}
+	}
+	return stats
+}
@@ -0,0 +1,101 @@
+package gol
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestBoardStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		board Board
+		want  BoardStatsResult
+	}{
+		{"empty", emptyBoard(6, 6), BoardStatsResult{}},
+		{"single block", parseBoard(
+			"......",
+			"..##..",
+			"..##..",
+			"......",
+		), BoardStatsResult{Population: 4, Components: 1, BoundingBox: &BoundingBox{Top: 1, Left: 2, Bottom: 2, Right: 3}}},
+		{"two separated blocks", parseBoard(
+			"##.....",
+			"##.....",
+			".......",
+			".....##",
+			".....##",
+		), BoardStatsResult{Population: 8, Components: 2, BoundingBox: &BoundingBox{Top: 0, Left: 0, Bottom: 4, Right: 6}}},
+		// Cells touching only at a corner are neighbors too
+		{"diagonal", parseBoard(
+			"#...",
+			".#..",
+			"..#.",
+			"...#",
+		), BoardStatsResult{Population: 4, Components: 1, BoundingBox: &BoundingBox{Top: 0, Left: 0, Bottom: 3, Right: 3}}},
+		{"glider", parseBoard(
+			".#.",
+			"..#",
+			"###",
+		), BoardStatsResult{Population: 5, Components: 1, BoundingBox: &BoundingBox{Top: 0, Left: 0, Bottom: 2, Right: 2}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := BoardStats(test.board)
+			if got.Population != test.want.Population || got.Components != test.want.Components {
+				t.Errorf("population %d in %d components, want %d in %d", got.Population, got.Components, test.want.Population, test.want.Components)
+			}
+			if (got.BoundingBox == nil) != (test.want.BoundingBox == nil) || got.BoundingBox != nil && *got.BoundingBox != *test.want.BoundingBox {
+				t.Errorf("bounding box %+v, want %+v", got.BoundingBox, test.want.BoundingBox)
+			}
+		})
+	}
+}
+
+func TestBoardStatsQuery(t *testing.T) {
+	// Two blocks are still lifes, the game ends as soon as the board repeats
+	seed := parseBoard(
+		"........",
+		".##.....",
+		".##.....",
+		"........",
+		"....##..",
+		"....##..",
+	)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 10, TickTime: time.Millisecond})
+
+	var stats BoardStatsResult
+	game.query(t, "boardStats", &stats)
+	if stats.Step != 2 || stats.Population != 8 || stats.Components != 2 {
+		t.Errorf("stats are %+v, want 8 cells in 2 components at step 2", stats)
+	}
+	if want := (BoundingBox{Top: 1, Left: 1, Bottom: 5, Right: 5}); stats.BoundingBox == nil || *stats.BoundingBox != want {
+		t.Errorf("bounding box %+v, want %+v", stats.BoundingBox, want)
+	}
+}
+
+func TestBoardStatsActivity(t *testing.T) {
+	stats, err := AmInstance.BoardStats(context.Background(), parseBoard(
+		"##...",
+		"##...",
+		"....#",
+	).Pack())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Population != 5 || stats.Components != 2 || stats.BoundingBox == nil || *stats.BoundingBox != (BoundingBox{Top: 0, Left: 0, Bottom: 2, Right: 4}) {
+		t.Errorf("stats are %+v with bounding box %+v, want 5 cells in 2 components within the whole board", stats, stats.BoundingBox)
+	}
+
+	for _, board := range []*PackedBoard{nil, {Rows: 4, Cols: 4}} {
+		_, err := AmInstance.BoardStats(context.Background(), board)
+		var applicationErr *temporal.ApplicationError
+		if !errors.As(err, &applicationErr) || !applicationErr.NonRetryable() {
+			t.Errorf("board %v failed with %v, want a non retryable error", board, err)
+		}
+	}
+}
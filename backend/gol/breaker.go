@@ -0,0 +1,81 @@
+package gol
+
+import (
+	"sync"
+	"time"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Circuit Breaker                              */
+/* -------------------------------------------------------------------------- */
+
+// When the state sink is down every frame of every game fails SendState, and temporal retries
+// each of them, so the games crawl along at the pace of the retries. The breaker opens after
+// a number of failures in a row and drops frames for a cooldown instead, SendState succeeds
+// without publishing so nothing is retried. Once the cooldown is over a single frame goes
+// through as a probe (half open), its success closes the breaker and its failure reopens it.
+// Clients miss the dropped frames, the next keyframe brings them back in sync.
+
+const (
+	DefaultBreakerThreshold = 5                // Failures in a row that open the breaker
+	DefaultBreakerCooldown  = 10 * time.Second // Frames are dropped for this long once open
+)
+
+// Wraps a publisher with a circuit breaker, subscribing is passed straight through
+type BreakerPublisher struct {
+	StatePublisher
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int       // Failures in a row
+	openUntil time.Time // Zero while closed
+	probing   bool      // A half open probe is in flight, other frames are dropped meanwhile
+	dropped   uint64
+}
+
+func NewBreakerPublisher(publisher StatePublisher, threshold int, cooldown time.Duration) *BreakerPublisher {
+	return &BreakerPublisher{
+		StatePublisher: publisher,
+		threshold:      max(threshold, 1),
+		cooldown:       cooldown,
+	}
+}
+
+// Publish publishes through the wrapped publisher unless the breaker is open
+// A dropped frame isn't an error, retrying it would only wait out the cooldown
+func (p *BreakerPublisher) Publish(id string, state StateChange) (int, error) {
+	p.mu.Lock()
+	if !p.openUntil.IsZero() {
+		if p.probing || time.Now().Before(p.openUntil) {
+			p.dropped++
+			p.mu.Unlock()
+			return 0, nil
+		}
+		p.probing = true
+	}
+	p.mu.Unlock()
+
+	dropped, err := p.StatePublisher.Publish(id, state)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.probing = false
+	if err != nil {
+		p.failures++
+		if p.failures >= p.threshold || !p.openUntil.IsZero() {
+			p.openUntil = time.Now().Add(p.cooldown)
+		}
+		return 0, err
+	}
+	p.failures = 0
+	p.openUntil = time.Time{}
+	return dropped, nil
+}
+
+// Breaker returns whether the breaker is open (or half open) and the frames it dropped so far
+func (p *BreakerPublisher) Breaker() (open bool, droppedFrames uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.openUntil.IsZero(), p.dropped
+}
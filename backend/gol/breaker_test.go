@@ -0,0 +1,121 @@
+package gol
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failingPublisher is an in-memory publisher whose Publish fails while failing is set
+// and waits for release (when set) before publishing
+type failingPublisher struct {
+	*MemoryPublisher
+	mu      sync.Mutex
+	failing bool
+	calls   int
+	release chan struct{}
+}
+
+func (p *failingPublisher) Publish(id string, state StateChange) (int, error) {
+	p.mu.Lock()
+	p.calls++
+	failing, release := p.failing, p.release
+	p.mu.Unlock()
+
+	if release != nil {
+		<-release
+	}
+	if failing {
+		return 0, errors.New("sink is down")
+	}
+	return p.MemoryPublisher.Publish(id, state)
+}
+
+func (p *failingPublisher) set(failing bool, release chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failing, p.release = failing, release
+}
+
+func (p *failingPublisher) callCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func TestBreakerTripsAndRecovers(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	sink := &failingPublisher{MemoryPublisher: NewMemoryPublisher(), failing: true}
+	breaker := NewBreakerPublisher(sink, 3, cooldown)
+	frame := StateChange{Id: "breaker", Step: 1}
+
+	// Failures below the threshold are returned so SendState retries them
+	for i := range 3 {
+		if _, err := breaker.Publish(frame.Id, frame); err == nil {
+			t.Fatalf("failure %d wasn't returned", i+1)
+		}
+	}
+	if open, _ := breaker.Breaker(); !open {
+		t.Fatal("3 failures in a row didn't open the breaker")
+	}
+
+	// Open, frames are dropped without reaching the sink or failing
+	for range 5 {
+		if _, err := breaker.Publish(frame.Id, frame); err != nil {
+			t.Errorf("open breaker returned %v", err)
+		}
+	}
+	if _, dropped := breaker.Breaker(); sink.callCount() != 3 || dropped != 5 {
+		t.Errorf("sink called %d times with %d frames dropped, want 3 calls and 5 drops", sink.callCount(), dropped)
+	}
+
+	// Half open after the cooldown, a failing probe opens it again
+	time.Sleep(cooldown + 5*time.Millisecond)
+	if _, err := breaker.Publish(frame.Id, frame); err == nil || sink.callCount() != 4 {
+		t.Fatalf("probe returned %v after %d calls, want the sink's failure on call 4", err, sink.callCount())
+	}
+	breaker.Publish(frame.Id, frame)
+	if open, _ := breaker.Breaker(); !open || sink.callCount() != 4 {
+		t.Fatal("the failed probe didn't reopen the breaker")
+	}
+
+	// Frames arriving while the probe is in flight are dropped
+	time.Sleep(cooldown + 5*time.Millisecond)
+	release := make(chan struct{})
+	sink.set(false, release)
+	probed := make(chan error)
+	go func() {
+		_, err := breaker.Publish(frame.Id, frame)
+		probed <- err
+	}()
+	for sink.callCount() != 5 {
+		time.Sleep(time.Millisecond)
+	}
+	breaker.Publish(frame.Id, frame)
+	close(release)
+	if err := <-probed; err != nil {
+		t.Fatalf("successful probe returned %v", err)
+	}
+	if _, dropped := breaker.Breaker(); sink.callCount() != 5 || dropped != 7 {
+		t.Errorf("sink called %d times with %d frames dropped, want the probe alone to get through", sink.callCount(), dropped)
+	}
+
+	// The successful probe closed it, frames reach subscribers again
+	if open, _ := breaker.Breaker(); open {
+		t.Fatal("the successful probe didn't close the breaker")
+	}
+	states, unsubscribe := breaker.Subscribe(frame.Id)
+	defer unsubscribe()
+	sink.set(false, nil)
+	if _, err := breaker.Publish(frame.Id, StateChange{Id: frame.Id, Step: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, states); got.Step != 2 {
+		t.Errorf("subscriber got step %d, want 2", got.Step)
+	}
+}
+
+func TestBreakerPublisher(t *testing.T) {
+	testPublisher(t, NewBreakerPublisher(NewMemoryPublisher(), DefaultBreakerThreshold, DefaultBreakerCooldown))
+}
@@ -0,0 +1,146 @@
+package gol
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Snapshot Cache                               */
+/* -------------------------------------------------------------------------- */
+
+// Snapshots served to new /state connections instead of querying the workflow
+const DefaultSnapshotTTL = 5 * time.Second
+
+// The cache keeps the last known board of every game delivered to this process
+//
+// A game's board is only known from a full frame onwards, the diffs after it are applied
+// to the live cells, and in a team game to the team of every live cell. A frame going back
+// in steps (a restarted game) drops the game's board until the next full frame. Entries not
+// updated within the TTL are stale since the game may be running on another worker, those
+// callers fall back to the board query.
+type SnapshotCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	games map[string]*snapshot
+}
+
+type snapshot struct {
+	state     StateChange // Metadata of the last frame, Flipped and Teams are rebuilt from live on read
+	live      map[[2]int]struct{}
+	teams     map[[2]int]uint8 // Team of each live cell, nil unless the game is a team game
+	updatedAt time.Time
+}
+
+func NewSnapshotCache(ttl time.Duration) *SnapshotCache {
+	return &SnapshotCache{
+		ttl:   ttl,
+		games: make(map[string]*snapshot),
+	}
+}
+
+// Publish applies the state change to the game's cached board and publishes it on the hub
+// Both happen under the cache lock so Subscribe never sees a frame in the snapshot and on the channel
+// Returns the number of subscribers that dropped a frame (see StateHub.Publish)
+func (c *SnapshotCache) Publish(hub *StateHub, state StateChange) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.update(state)
+	return hub.Publish(state)
+}
+
+// Subscribe subscribes to the game on the hub and returns its cached board (see Get)
+// Every frame on the channel comes after the returned board
+func (c *SnapshotCache) Subscribe(hub *StateHub, id string) (StateChange, bool, <-chan StateChange, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	states, unsubscribe := hub.Subscribe(id)
+	state, ok := c.get(id)
+	return state, ok, states, unsubscribe
+}
+
+func (c *SnapshotCache) update(state StateChange) {
+	entry, ok := c.games[state.Id]
+	if state.Full {
+		entry = &snapshot{live: make(map[[2]int]struct{}, len(state.Flipped))}
+		c.games[state.Id] = entry
+	} else if !ok {
+		return
+	} else if state.Step < entry.state.Step {
+		delete(c.games, state.Id)
+		return
+	}
+
+	// An empty team board encodes no teams, so the first frame that has some makes it a team game
+	if entry.teams == nil && len(state.Teams) > 0 {
+		entry.teams = make(map[[2]int]uint8, len(entry.live)+len(state.Flipped))
+	}
+	for i, cell := range state.Flipped {
+		if _, alive := entry.live[cell]; alive {
+			delete(entry.live, cell)
+			delete(entry.teams, cell)
+		} else {
+			entry.live[cell] = struct{}{}
+			if entry.teams != nil && i < len(state.Teams) {
+				entry.teams[cell] = state.Teams[i]
+			}
+		}
+	}
+
+	// Walls and the board's size only come with full frames
+	previous := entry.state
+	entry.state = state
+	if !state.Full {
+		entry.state.Walls = previous.Walls
+		entry.state.Rows, entry.state.Cols = previous.Rows, previous.Cols
+	}
+	entry.state.Flipped = nil
+	entry.state.Cells = nil
+	entry.state.Teams = nil
+	entry.updatedAt = time.Now()
+}
+
+// Get returns the game's board as a full frame, like the board query does
+// ok is false when the game's board is unknown or stale
+func (c *SnapshotCache) Get(id string) (StateChange, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.get(id)
+}
+
+func (c *SnapshotCache) get(id string) (StateChange, bool) {
+	entry, ok := c.games[id]
+	if !ok {
+		return StateChange{}, false
+	}
+	if time.Since(entry.updatedAt) > c.ttl {
+		delete(c.games, id)
+		return StateChange{}, false
+	}
+
+	flipped := make([][2]int, 0, len(entry.live))
+	for cell := range entry.live {
+		flipped = append(flipped, cell)
+	}
+	sort.Slice(flipped, func(a, b int) bool {
+		if flipped[a][0] != flipped[b][0] {
+			return flipped[a][0] < flipped[b][0]
+		}
+		return flipped[a][1] < flipped[b][1]
+	})
+
+	state := entry.state
+	state.Flipped = flipped
+	state.Full = true
+	if entry.teams != nil {
+		state.Teams = make([]uint8, len(flipped))
+		for i, cell := range flipped {
+			state.Teams[i] = entry.teams[cell]
+		}
+	}
+	return state, true
+}
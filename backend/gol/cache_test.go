@@ -0,0 +1,85 @@
+package gol
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSnapshotCache(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(17)), 12, 12, 0.35)
+	frames, final := diffFrames("cached", seed, 6)
+	full := StateChange{Id: "cached", Full: true, Rows: 12, Cols: 12, Flipped: seed.LiveCells(), Walls: [][2]int{{0, 0}}}
+
+	cache := NewSnapshotCache(50 * time.Millisecond)
+	hub := NewStateHub()
+
+	// A game is unknown until its first full frame, diffs alone can't rebuild its board
+	if _, ok := cache.Get("cached"); ok {
+		t.Fatal("hit for a game never published")
+	}
+	cache.Publish(hub, frames[0])
+	if _, ok := cache.Get("cached"); ok {
+		t.Fatal("hit for a game only published as diffs")
+	}
+
+	cache.Publish(hub, full)
+	for _, frame := range frames {
+		cache.Publish(hub, frame)
+	}
+	state, ok := cache.Get("cached")
+	if !ok {
+		t.Fatal("miss right after the game was published")
+	}
+	if got := applyFrame(t, emptyBoard(12, 12), state); !equalBoards(got, final) {
+		t.Errorf("cached board =\n%vwant\n%v", got, final)
+	}
+	if !state.Full || state.Step != 6 || state.Rows != 12 || state.Cols != 12 || len(state.Walls) != 1 {
+		t.Errorf("cached frame is step %d (full %v, %dx%d, walls %v), want the full board of step 6 with its size and walls",
+			state.Step, state.Full, state.Rows, state.Cols, state.Walls)
+	}
+
+	// A frame going back in steps belongs to a restarted game, the cached board is dropped
+	cache.Publish(hub, StateChange{Id: "cached", Step: 1})
+	if _, ok := cache.Get("cached"); ok {
+		t.Error("hit after the game went back in steps")
+	}
+
+	// Entries left alone past the TTL are stale
+	cache.Publish(hub, full)
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := cache.Get("cached"); ok {
+		t.Error("hit past the TTL")
+	}
+}
+
+func TestSnapshotCacheKeepsTheTeamOfEveryLiveCell(t *testing.T) {
+	cache := NewSnapshotCache(time.Minute)
+	hub := NewStateHub()
+
+	// Three cells of mixed teams, then a diff killing one and giving birth to a team 1 cell
+	cache.Publish(hub, StateChange{Id: "teams", Full: true, Rows: 4, Cols: 4,
+		Flipped: [][2]int{{0, 0}, {1, 1}, {2, 2}}, Teams: []uint8{0, 1, 0}})
+	cache.Publish(hub, StateChange{Id: "teams", Step: 1, Flipped: [][2]int{{3, 3}, {1, 1}}, Teams: []uint8{1, 1}})
+
+	state, ok := cache.Get("teams")
+	if !ok {
+		t.Fatal("miss right after the game was published")
+	}
+	want := map[[2]int]uint8{{0, 0}: 0, {2, 2}: 0, {3, 3}: 1}
+	if len(state.Flipped) != len(want) || len(state.Teams) != len(state.Flipped) {
+		t.Fatalf("cached cells %v with teams %v, want the teams of %v", state.Flipped, state.Teams, want)
+	}
+	for i, cell := range state.Flipped {
+		if team, live := want[cell]; !live || state.Teams[i] != team {
+			t.Errorf("cached cell %v is on team %d, want %v", cell, state.Teams[i], want)
+		}
+	}
+
+	// A classic game has no teams
+	cache.Publish(hub, StateChange{Id: "classic", Full: true, Rows: 4, Cols: 4, Flipped: [][2]int{{0, 0}}})
+	cache.Publish(hub, StateChange{Id: "classic", Step: 1, Flipped: [][2]int{{1, 1}}})
+	if state, _ := cache.Get("classic"); state.Teams != nil {
+		t.Errorf("classic game cached teams %v", state.Teams)
+	}
+}
@@ -0,0 +1,82 @@
+package gol
+
+import (
+	"sort"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Diff Compaction                               */
+/* -------------------------------------------------------------------------- */
+
+// Signals arriving together (e.g. a splatter and a batch toggle between two ticks) would each
+// publish their own diff. Instead the handlers queue their flips and the loop publishes a single
+// frame once every pending signal is handled, a cell flipped an even number of times nets out
+// and is left out of it. Handlers replacing the board or changing walls queue a full frame,
+// which already covers every flip queued before it. Handlers that only change the pause state
+// queue a status frame so clients see it right away instead of on the next tick, which a paused
+// game never has.
+
+// queueFlips queues cells a signal handler flipped for the next published frame
+// Every cell is flipped on the board already, once
+func (s *GolState) queueFlips(flipped [][2]int) {
+	s.trackFlips(flipped)
+	if !s.pendingFull {
+		s.pendingFlips = append(s.pendingFlips, flipped...)
+	}
+}
+
+// queueFullFrame makes the next published frame a full one
+// Whatever the handler changed is counted again before the next generation
+func (s *GolState) queueFullFrame() {
+	s.tracked = false
+	s.pendingFlips = nil
+	s.pendingFull = true
+}
+
+// queueStatus makes sure a frame is published even when no cells were flipped
+func (s *GolState) queueStatus() {
+	s.pendingStatus = true
+}
+
+// PublishPending publishes the frame queued by the signal handlers, if there is anything to publish
+func PublishPending(ctx workflow.Context, golState *GolState) error {
+	full, flipped := golState.pendingFull, NetFlips(golState.pendingFlips)
+	status := golState.pendingStatus
+	golState.pendingFlips, golState.pendingFull, golState.pendingStatus = nil, false, false
+
+	switch {
+	case full:
+		_, err := PublishState(ctx, golState, StateChangeFromNothing(*golState))
+		return err
+	case len(flipped) > 0 || status:
+		golState.lastDiff = len(flipped)
+		return SendState(ctx, golState, flipped)
+	}
+	return nil
+}
+
+// NetFlips returns the cells flipped an odd number of times across the lists in row-major order
+func NetFlips(lists ...[][2]int) [][2]int {
+	counts := make(map[[2]int]int)
+	for _, cells := range lists {
+		for _, cell := range cells {
+			counts[cell]++
+		}
+	}
+
+	var flipped [][2]int
+	for cell, count := range counts {
+		if count%2 == 1 {
+			flipped = append(flipped, cell)
+		}
+	}
+	sort.Slice(flipped, func(a, b int) bool {
+		if flipped[a][0] != flipped[b][0] {
+			return flipped[a][0] < flipped[b][0]
+		}
+		return flipped[a][1] < flipped[b][1]
+	})
+	return flipped
+}
@@ -0,0 +1,56 @@
+package gol
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNetFlips(t *testing.T) {
+	for _, test := range []struct {
+		flips [][][2]int
+		want  string
+	}{
+		{[][][2]int{{{2, 3}, {0, 1}, {5, 5}}, {{5, 5}, {2, 0}}}, "[[0 1] [2 0] [2 3]]"},
+		{[][][2]int{{{1, 1}, {1, 1}}}, "[]"},
+		{[][][2]int{{{1, 1}}, {{1, 1}}, {{1, 1}}}, "[[1 1]]"},
+	} {
+		if got := NetFlips(test.flips...); fmt.Sprint(got) != test.want {
+			t.Errorf("NetFlips%v = %v, want %s", test.flips, got, test.want)
+		}
+	}
+}
+
+func TestCellFlippedTwiceInOneWindowNetsOut(t *testing.T) {
+	seed := emptyBoard(16, 16)
+	game := newTestGame(t)
+	game.env.OnActivity(AmInstance.Splatter, mock.Anything, mock.Anything).Return(
+		func(context.Context, SplatterInput) ([][2]int, error) { return [][2]int{{4, 4}}, nil })
+	// The toggle arrives while the splatter's activity runs, so both are handled before the game publishes
+	game.env.RegisterDelayedCallback(func() {
+		game.env.SignalWorkflow(SplatterSignalName, SplatterSignal{X: 4, Y: 4})
+		game.env.SignalWorkflow(BatchToggleSignalName, BatchToggleSignal{Cells: [][2]int{{4, 4}, {7, 9}}})
+	}, 5*time.Millisecond)
+	game.signalAt(10*time.Millisecond, SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: 1})
+	// Paused so nothing but the toggles changes the board
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Paused: true, Step: 1, MaxSteps: 10, TickTime: time.Millisecond})
+
+	var diffs []StateChange
+	for _, frame := range game.frames {
+		if !frame.Full && frame.Ended == "" {
+			diffs = append(diffs, frame)
+		}
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("the two toggles were published as %d frames, want a single one", len(diffs))
+	}
+	if fmt.Sprint(diffs[0].Flipped) != "[[7 9]]" {
+		t.Errorf("diff flipped %v, want only [7 9] since [4 4] was flipped twice", diffs[0].Flipped)
+	}
+	if live := game.replay(t, seed).LiveCells(); fmt.Sprint(live) != fmt.Sprint(diffs[0].Flipped) {
+		t.Errorf("replaying the frames leaves %v alive, want %v", live, diffs[0].Flipped)
+	}
+}
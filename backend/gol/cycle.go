@@ -0,0 +1,119 @@
+package gol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Cycle Detection                               */
+/* -------------------------------------------------------------------------- */
+
+// A game has settled once its board repeats: a board equal to the previous one is a still
+// life (an extinct board being the empty still life), and a board equal to one K generations
+// back is an oscillator of period K. Only hashes of the recent boards are kept so the window
+// costs 8 bytes per generation.
+
+// Boards remembered for period detection, oscillators with a longer period keep running
+const DefaultCycleWindow = 16
+
+// BoardHash returns the FNV-1a hash of the board (and the cell states for Generations rules)
+// Cells are hashed in row-major order after the dimensions, so equal boards always hash the same
+func BoardHash(board Board, ages [][]uint8) uint64 {
+	hash := fnv.New64a()
+
+	var dims [16]byte
+	binary.LittleEndian.PutUint64(dims[:8], uint64(len(board)))
+	if len(board) > 0 {
+		binary.LittleEndian.PutUint64(dims[8:], uint64(len(board[0])))
+	}
+	hash.Write(dims[:])
+
+	hash.Write(board.Pack().Bits)
+	for _, row := range ages {
+		hash.Write(row)
+	}
+	return hash.Sum64()
+}
+
+// Checksum returns the hash of the board alone as 16 hex digits, a JSON number would lose precision in browsers
+// Clients recompute it as FNV-1a over the rows and cols (little-endian uint64s) followed by the
+// board packed like PackedBoard.Bits, and resync (/resync/{id}) when it doesn't match a frame's
+func Checksum(board Board) string {
+	return fmt.Sprintf("%016x", BoardHash(board, nil))
+}
+
+// Hashing the whole board every generation would cost as much as computing it, so the live cells are
+// hashed incrementally instead: every cell has a fixed key and the hash is the XOR of the keys of the live
+// cells, flipping a cell XORs its key in or out. The population is kept up to date along with it.
+// Generations games hash their ages too, which also change without flipping, so they hash the whole board.
+
+// cellKey returns the key of the cell at the row-major index (splitmix64, so every run and worker agrees)
+func cellKey(index int) uint64 {
+	z := uint64(index) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// trackFlips updates the live cell hash and population with cells flipped on the board
+// Until they are counted (a new run, a replaced board) the whole board is counted instead
+func (s *GolState) trackFlips(flipped [][2]int) {
+	if len(s.Board) == 0 {
+		return
+	}
+	cols := len(s.Board[0])
+	if !s.tracked {
+		s.liveHash, s.population = 0, 0
+		for _, cell := range s.Board.LiveCells() {
+			s.liveHash ^= cellKey(cell[0]*cols + cell[1])
+			s.population++
+		}
+		s.tracked = true
+		return
+	}
+	for _, cell := range flipped {
+		s.liveHash ^= cellKey(cell[0]*cols + cell[1])
+		if s.Board[cell[0]][cell[1]] {
+			s.population++
+		} else {
+			s.population--
+		}
+	}
+}
+
+// cycleHash returns the hash of the current board for DetectCycle
+func (s *GolState) cycleHash() uint64 {
+	if s.Generations > 2 {
+		return BoardHash(s.Board, s.Ages)
+	}
+	s.trackFlips(nil)
+	return s.liveHash
+}
+
+// Population returns the number of live cells, counted incrementally once the board was counted
+func (s GolState) Population() int {
+	if s.tracked {
+		return s.population
+	}
+	return s.Board.Population()
+}
+
+// DetectCycle records the hash of the current board and returns the period it repeats with
+// Returns 0 when the board wasn't seen within the cycle window
+func (s *GolState) DetectCycle(hash uint64) int {
+	period := 0
+	for i := len(s.RecentHashes) - 1; i >= 0; i-- {
+		if s.RecentHashes[i] == hash {
+			period = len(s.RecentHashes) - i
+			break
+		}
+	}
+
+	s.RecentHashes = append(s.RecentHashes, hash)
+	if len(s.RecentHashes) > s.CycleWindow {
+		s.RecentHashes = s.RecentHashes[len(s.RecentHashes)-s.CycleWindow:]
+	}
+	return period
+}
@@ -0,0 +1,244 @@
+package gol
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSettledBoardsEndTheGame(t *testing.T) {
+	tests := []struct {
+		name   string
+		board  Board
+		period int
+		ended  string
+		step   int // Step the board repeated at, the starting board isn't remembered
+	}{
+		{"block", parseBoard(
+			"......",
+			"..##..",
+			"..##..",
+			"......",
+		), 1, EndedTerminated, 2},
+		{"blinker", parseBoard(
+			".....",
+			"..#..",
+			"..#..",
+			"..#..",
+			".....",
+		), 2, EndedTerminated, 3},
+		{"extinct", parseBoard(
+			".....",
+			".#...",
+			"...#.",
+			".....",
+		), 1, EndedExtinct, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := newTestGame(t)
+			game.run(t, GameOfLifeInput{Board: test.board.Pack(), MaxSteps: 100, TickTime: time.Millisecond})
+
+			last := game.frames[len(game.frames)-1]
+			if last.Ended != test.ended {
+				t.Errorf("game ended with %q, want %q", last.Ended, test.ended)
+			}
+			if last.Step != test.step || !last.Terminated || last.Period != test.period {
+				t.Errorf("game ended at step %d with terminated %v and period %d, want step %d with period %d",
+					last.Step, last.Terminated, last.Period, test.step, test.period)
+			}
+		})
+	}
+}
+
+func TestTrackedPopulationMatchesTheBoard(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(18)), 24, 24, 0.35)
+	game := newTestGame(t)
+
+	// Every kind of edit between the generations, the frames' populations have to keep up with them
+	game.signalAt(3*time.Millisecond+time.Microsecond, BatchToggleSignalName, BatchToggleSignal{Cells: [][2]int{{0, 0}, {5, 5}, {23, 23}}})
+	game.signalAt(5*time.Millisecond+time.Microsecond, SplatterSignalName, SplatterSignal{X: 12, Y: 12, Size: 4})
+	game.signalAt(7*time.Millisecond+time.Microsecond, ShiftSignalName, ShiftSignal{DRow: 3, DCol: -2})
+	game.signalAt(9*time.Millisecond+time.Microsecond, SetWallSignalName, SetWallSignal{Cells: [][2]int{{10, 10}, {10, 11}, {10, 12}}, Wall: true})
+	game.signalAt(11*time.Millisecond+time.Microsecond, PlacePatternSignalName, PlacePatternSignal{Name: "glider", Row: 2, Col: 2})
+	game.signalAt(13*time.Millisecond+time.Microsecond, ResetSignalName, nil)
+	game.signalAt(15*time.Millisecond+time.Microsecond, BatchToggleSignalName, BatchToggleSignal{Cells: [][2]int{{1, 1}}})
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 20, TickTime: time.Millisecond})
+
+	board := seed.Clone()
+	for _, frame := range game.frames {
+		if frame.Ended != "" {
+			continue
+		}
+		board = applyFrame(t, board, frame)
+		if want := board.Population(); frame.Population != want {
+			t.Fatalf("frame %d (step %d) has population %d, the board has %d", frame.Seq, frame.Step, frame.Population, want)
+		}
+	}
+}
+
+func TestTrackedHashFollowsTheBoard(t *testing.T) {
+	random := rand.New(rand.NewSource(19))
+	state := GolState{Board: randomBoard(random, 16, 20, 0.4)}
+	state.trackFlips(nil)
+
+	// Flipping cells and flipping them back gives the same hash, different boards (almost surely) don't
+	start := state.cycleHash()
+	flipped := [][2]int{{0, 0}, {3, 7}, {15, 19}}
+	state.Board.Toggle(flipped)
+	state.trackFlips(flipped)
+	if state.cycleHash() == start {
+		t.Error("flipping cells kept the hash")
+	}
+	state.Board.Toggle(flipped)
+	state.trackFlips(flipped)
+	if state.cycleHash() != start {
+		t.Error("flipping the cells back didn't restore the hash")
+	}
+
+	// The tracked hash and population agree with counting the board from scratch
+	for range 10 {
+		next := NextGeneration(state.Board, Rule{})
+		flipped := DiffFlipped(state.Board, next)
+		state.Board = next
+		state.trackFlips(flipped)
+	}
+	counted := GolState{Board: state.Board}
+	if counted.cycleHash() != state.cycleHash() || state.Population() != state.Board.Population() {
+		t.Errorf("tracked hash %x with population %d, counting the board gives %x with %d",
+			state.cycleHash(), state.Population(), counted.cycleHash(), state.Board.Population())
+	}
+}
+
+func TestBoardHash(t *testing.T) {
+	board := randomBoard(rand.New(rand.NewSource(22)), 30, 70, 0.4)
+	hash := BoardHash(board, nil)
+	if BoardHash(board.Clone(), nil) != hash {
+		t.Error("identical boards hash differently")
+	}
+
+	// Every single cell matters, including the last of a row that doesn't fill its packed byte
+	for _, cell := range [][2]int{{0, 0}, {15, 33}, {29, 69}} {
+		changed := board.Clone()
+		changed.Toggle([][2]int{cell})
+		if BoardHash(changed, nil) == hash {
+			t.Errorf("flipping %v kept the hash", cell)
+		}
+	}
+
+	// The same cells on a board of another shape are another board
+	if BoardHash(emptyBoard(2, 8), nil) == BoardHash(emptyBoard(4, 4), nil) {
+		t.Error("empty 2x8 and 4x4 boards hash the same")
+	}
+
+	// Generations boards differ by their ages too
+	ages := NewAges(board, 4)
+	older := NewAges(board, 4)
+	older[3][3] = 1
+	if BoardHash(board, ages) == BoardHash(board, older) {
+		t.Error("different ages hash the same")
+	}
+}
+
+func TestBoardHashQuery(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(23)), 16, 16, 0.35)
+	hashes := make([]BoardHashResult, 2)
+	for i := range hashes {
+		game := newTestGame(t)
+		game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 6, TickTime: time.Millisecond})
+		game.query(t, "boardHash", &hashes[i])
+	}
+
+	if hashes[0] != hashes[1] {
+		t.Errorf("two runs of the same seed hash to %+v and %+v", hashes[0], hashes[1])
+	}
+	if want := Checksum(evolve(seed, 6)); hashes[0].Step != 6 || hashes[0].Hash != want {
+		t.Errorf("boardHash = %+v, want step 6 with hash %s", hashes[0], want)
+	}
+}
+
+func TestPopulationQueryReportsThePeriod(t *testing.T) {
+	blinker := parseBoard(
+		".....",
+		"..#..",
+		"..#..",
+		"..#..",
+		".....",
+	)
+
+	// Before the board repeats there is no cycle, after it the blinker's period
+	game := newTestGame(t)
+	var before, after PopulationStats
+	game.env.RegisterDelayedCallback(func() { game.query(t, "population", &before) }, 15*time.Millisecond)
+	game.run(t, GameOfLifeInput{Board: blinker.Pack(), MaxSteps: 100, TickTime: 10 * time.Millisecond})
+	game.query(t, "population", &after)
+	if before.CycleDetected || before.Period != 0 {
+		t.Errorf("at step %d the cycle is detected %v with period %d, want none yet", before.Step, before.CycleDetected, before.Period)
+	}
+	if !after.CycleDetected || after.Period != 2 {
+		t.Errorf("blinker ended with cycle detected %v and period %d, want period 2", after.CycleDetected, after.Period)
+	}
+
+	// A window of one board only catches still lifes
+	game = newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: blinker.Pack(), CycleWindow: 1, MaxSteps: 20, TickTime: time.Millisecond})
+	game.query(t, "population", &after)
+	if after.Step != 20 || after.CycleDetected || after.Period != 0 {
+		t.Errorf("with a window of 1 the blinker ended at step %d with cycle detected %v and period %d, want it to run to 20", after.Step, after.CycleDetected, after.Period)
+	}
+}
+
+// clientChecksum computes a board's checksum the way a client does, from its description on Checksum
+func clientChecksum(board Board) string {
+	rows, cols := len(board), len(board[0])
+	bytes := make([]byte, 16, 16+(rows*cols+7)/8)
+	for i := range 8 {
+		bytes[i] = byte(uint64(rows) >> (8 * i))
+		bytes[8+i] = byte(uint64(cols) >> (8 * i))
+	}
+	bits := make([]byte, (rows*cols+7)/8)
+	for i := range rows {
+		for j := range cols {
+			if board[i][j] {
+				bits[(i*cols+j)/8] |= 1 << ((i*cols + j) % 8)
+			}
+		}
+	}
+	bytes = append(bytes, bits...)
+
+	// FNV-1a, 64 bits
+	hash := uint64(14695981039346656037)
+	for _, b := range bytes {
+		hash ^= uint64(b)
+		hash *= 1099511628211
+	}
+	return fmt.Sprintf("%016x", hash)
+}
+
+func TestFramesCarryTheChecksumOfTheirBoard(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(612)), 17, 23, 0.35)
+	game := newTestGame(t)
+	game.signalAt(5*time.Millisecond, BatchToggleSignalName, BatchToggleSignal{Cells: [][2]int{{0, 0}, {8, 11}, {16, 22}}})
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Checksums: true, RevealSteps: 3, MaxSteps: 30, StoreInterval: 100, TickTime: time.Millisecond})
+
+	// Reveal frames, generations, the toggle and the ended frame all carry the board a client has after them
+	var board Board
+	for _, frame := range game.frames {
+		board = applyFrame(t, board, frame)
+		if want := clientChecksum(board); frame.Checksum != want {
+			t.Errorf("step %d (seq %d) has checksum %q, the client computes %q", frame.Step, frame.Seq, frame.Checksum, want)
+		}
+	}
+	if got, want := Checksum(board), clientChecksum(board); got != want {
+		t.Errorf("Checksum = %s, the client computes %s", got, want)
+	}
+
+	plain := newTestGame(t)
+	plain.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 5, TickTime: time.Millisecond})
+	for _, frame := range plain.frames {
+		if frame.Checksum != "" {
+			t.Errorf("step %d carries checksum %s without Checksums", frame.Step, frame.Checksum)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package gol
+
+import "fmt"
+
+/* -------------------------------------------------------------------------- */
+/*                              Time Travel Diff                              */
+/* -------------------------------------------------------------------------- */
+
+// Steps to diff with the diffBetween query, both have to be in the recorded history
+type DiffRequest struct {
+	FromStep int `json:"fromStep"`
+	ToStep   int `json:"toStep"`
+}
+
+// Cells that differ between the boards of two steps, applying them to the board at
+// FromStep gives the board at ToStep (and the other way around)
+type StepDiff struct {
+	FromStep int      `json:"fromStep"`
+	ToStep   int      `json:"toStep"`
+	Flipped  [][2]int `json:"flipped"` // Row-major slice of [row, col] pairs
+}
+
+// DiffBetween diffs two recorded boards, failing when either step isn't in the history
+func (s GolState) DiffBetween(request DiffRequest) (StepDiff, error) {
+	from, err := s.recordedBoard(request.FromStep)
+	if err != nil {
+		return StepDiff{}, err
+	}
+	to, err := s.recordedBoard(request.ToStep)
+	if err != nil {
+		return StepDiff{}, err
+	}
+	return StepDiff{
+		FromStep: request.FromStep,
+		ToStep:   request.ToStep,
+		Flipped:  DiffFlipped(from, to),
+	}, nil
+}
+
+// recordedBoard finds the board of the step in the history
+func (s GolState) recordedBoard(step int) (Board, error) {
+	if len(s.History) == 0 {
+		return nil, fmt.Errorf("step %d is not recorded, nothing is recorded (see the %s signal)", step, StartRecordingSignal)
+	}
+	// A reset starts the steps over, so the latest snapshot of a step wins
+	for i := len(s.History) - 1; i >= 0; i-- {
+		if s.History[i].Step == step {
+			return s.History[i].Board, nil
+		}
+	}
+	return nil, fmt.Errorf("step %d is not recorded, recorded steps are %d to %d", step, s.History[0].Step, s.History[len(s.History)-1].Step)
+}
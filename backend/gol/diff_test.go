@@ -0,0 +1,34 @@
+package gol
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestDiffBetweenQuery(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(89)), 20, 20, 0.35)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Recording: true, MaxSteps: 8, TickTime: time.Millisecond})
+
+	var diff StepDiff
+	game.query(t, "diffBetween", &diff, DiffRequest{FromStep: 0, ToStep: 5})
+	if want := DiffFlipped(seed, evolve(seed, 5)); diff.FromStep != 0 || diff.ToStep != 5 || fmt.Sprint(diff.Flipped) != fmt.Sprint(want) {
+		t.Errorf("diff from 0 to 5 is %+v, want %v", diff, want)
+	}
+
+	// Applying the diff walks the board back too
+	game.query(t, "diffBetween", &diff, DiffRequest{FromStep: 5, ToStep: 0})
+	back := evolve(seed, 5)
+	back.Toggle(diff.Flipped)
+	if !equalBoards(back, seed) {
+		t.Errorf("applying the diff from 5 to 0 gives\n%vwant\n%v", back, seed)
+	}
+
+	for _, request := range []DiffRequest{{FromStep: 0, ToStep: 9}, {FromStep: -1, ToStep: 3}} {
+		if _, err := game.env.QueryWorkflow("diffBetween", request); err == nil {
+			t.Errorf("diff %+v outside the recorded steps didn't fail", request)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package gol
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Compact Encoding                              */
+/* -------------------------------------------------------------------------- */
+
+// At high churn a JSON [row, col] pair costs 10+ bytes per flipped cell.
+// The compact encoding writes each flipped cell as the uvarint of its row-major index
+// (row*width + col) and base64 (standard, padded) encodes the buffer, so a cell on a
+// 512 wide board costs at most 3 bytes before base64.
+
+// Compact wire form of a StateChange, every field of the StateChange is carried over
+// The [row, col] lists (Flipped, Walls, Born, Died) are encoded with EncodeFlipped and Cells with
+// EncodeCells, all of them relative to Width. Expand converts a frame back into its StateChange.
+type CompactStateChange struct {
+	Id                string        `json:"id"`
+	Paused            bool          `json:"paused"`
+	Step              int           `json:"step"`
+	TickTime          time.Duration `json:"tickTime"`
+	Width             int           `json:"width"`
+	Flipped           string        `json:"flipped"`
+	Cells             string        `json:"cells,omitempty"`
+	Full              bool          `json:"full,omitempty"`
+	Terminated        bool          `json:"terminated,omitempty"`
+	Period            int           `json:"period,omitempty"`
+	Population        int           `json:"population"`
+	EffectiveTickTime time.Duration `json:"effectiveTickTime"`
+	Walls             string        `json:"walls,omitempty"`
+	Births            int           `json:"births,omitempty"`
+	Deaths            int           `json:"deaths,omitempty"`
+	Ended             string        `json:"ended,omitempty"`
+	Rows              int           `json:"rows,omitempty"`
+	Cols              int           `json:"cols,omitempty"`
+	Born              string        `json:"born,omitempty"`
+	Died              string        `json:"died,omitempty"`
+	Teams             []uint8       `json:"teams,omitempty"`
+	TeamPopulations   []int         `json:"teamPopulations,omitempty"`
+	Restarted         bool          `json:"restarted,omitempty"`
+	Checksum          string        `json:"checksum,omitempty"`
+	TraceId           string        `json:"traceId,omitempty"`
+	Seq               int           `json:"seq"`
+}
+
+// Compact converts the state change to its compact wire form
+func (s StateChange) Compact(width int) CompactStateChange {
+	return CompactStateChange{
+		Id:                s.Id,
+		Paused:            s.Paused,
+		Step:              s.Step,
+		TickTime:          s.TickTime,
+		Width:             width,
+		Flipped:           EncodeFlipped(s.Flipped, width),
+		Cells:             EncodeCells(s.Cells, width),
+		Full:              s.Full,
+		Terminated:        s.Terminated,
+		Period:            s.Period,
+		Population:        s.Population,
+		EffectiveTickTime: s.EffectiveTickTime,
+		Walls:             EncodeFlipped(s.Walls, width),
+		Births:            s.Births,
+		Deaths:            s.Deaths,
+		Ended:             s.Ended,
+		Rows:              s.Rows,
+		Cols:              s.Cols,
+		Born:              EncodeFlipped(s.Born, width),
+		Died:              EncodeFlipped(s.Died, width),
+		Teams:             s.Teams,
+		TeamPopulations:   s.TeamPopulations,
+		Restarted:         s.Restarted,
+		Checksum:          s.Checksum,
+		TraceId:           s.TraceId,
+		Seq:               s.Seq,
+	}
+}
+
+// Expand converts the compact wire form back into the state change
+func (c CompactStateChange) Expand() (StateChange, error) {
+	s := StateChange{
+		Id:                c.Id,
+		Paused:            c.Paused,
+		Step:              c.Step,
+		TickTime:          c.TickTime,
+		Full:              c.Full,
+		Terminated:        c.Terminated,
+		Period:            c.Period,
+		Population:        c.Population,
+		EffectiveTickTime: c.EffectiveTickTime,
+		Births:            c.Births,
+		Deaths:            c.Deaths,
+		Ended:             c.Ended,
+		Rows:              c.Rows,
+		Cols:              c.Cols,
+		Teams:             c.Teams,
+		TeamPopulations:   c.TeamPopulations,
+		Restarted:         c.Restarted,
+		Checksum:          c.Checksum,
+		TraceId:           c.TraceId,
+		Seq:               c.Seq,
+	}
+
+	var err error
+	for _, list := range []struct {
+		encoded string
+		cells   *[][2]int
+	}{{c.Flipped, &s.Flipped}, {c.Walls, &s.Walls}, {c.Born, &s.Born}, {c.Died, &s.Died}} {
+		if *list.cells, err = DecodeFlipped(list.encoded, c.Width); err != nil {
+			return StateChange{}, err
+		}
+	}
+	if s.Cells, err = DecodeCells(c.Cells, c.Width); err != nil {
+		return StateChange{}, err
+	}
+	return s, nil
+}
+
+// EncodeFlipped packs the flipped cells as uvarint indexes and base64 encodes them
+// No cells encode as the empty string
+func EncodeFlipped(flipped [][2]int, width int) string {
+	buf := make([]byte, 0, len(flipped)*3)
+	for _, cell := range flipped {
+		buf = binary.AppendUvarint(buf, uint64(cell[0]*width+cell[1]))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// DecodeFlipped unpacks flipped cells encoded with EncodeFlipped
+func DecodeFlipped(encoded string, width int) ([][2]int, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("invalid board width %d", width)
+	}
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var flipped [][2]int
+	for len(buf) > 0 {
+		index, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid varint in flipped cells")
+		}
+		buf = buf[n:]
+		flipped = append(flipped, [2]int{int(index) / width, int(index) % width})
+	}
+	return flipped, nil
+}
+
+// EncodeCells packs Generations cells as the uvarint index of each cell followed by the uvarint of its state
+func EncodeCells(cells [][3]int, width int) string {
+	buf := make([]byte, 0, len(cells)*4)
+	for _, cell := range cells {
+		buf = binary.AppendUvarint(buf, uint64(cell[0]*width+cell[1]))
+		buf = binary.AppendUvarint(buf, uint64(cell[2]))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// DecodeCells unpacks Generations cells encoded with EncodeCells
+func DecodeCells(encoded string, width int) ([][3]int, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("invalid board width %d", width)
+	}
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var cells [][3]int
+	for len(buf) > 0 {
+		index, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid varint in cells")
+		}
+		state, m := binary.Uvarint(buf[n:])
+		if m <= 0 {
+			return nil, fmt.Errorf("invalid varint in cells")
+		}
+		buf = buf[n+m:]
+		cells = append(cells, [3]int{int(index) / width, int(index) % width, int(state)})
+	}
+	return cells, nil
+}
@@ -0,0 +1,79 @@
+package gol
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompactRoundTrip(t *testing.T) {
+	const width = 300
+
+	// Every field is set so a field the compact form drops fails the comparison
+	frame := StateChange{
+		Id:                "compact",
+		Paused:            true,
+		Step:              42,
+		TickTime:          100 * time.Millisecond,
+		Flipped:           [][2]int{{0, 0}, {0, 299}, {7, 128}, {199, 299}},
+		Cells:             [][3]int{{0, 1, 1}, {150, 299, 3}, {3, 200, 200}},
+		Full:              true,
+		Terminated:        true,
+		Period:            2,
+		Population:        4,
+		EffectiveTickTime: 250 * time.Millisecond,
+		Walls:             [][2]int{{5, 5}, {5, 6}},
+		Births:            3,
+		Deaths:            1,
+		Ended:             EndedTerminated,
+		Rows:              200,
+		Cols:              width,
+		Born:              [][2]int{{0, 0}, {0, 299}, {7, 128}},
+		Died:              [][2]int{{199, 299}},
+		Teams:             []uint8{1, 2, 1, 0},
+		TeamPopulations:   []int{1, 2, 1},
+		Restarted:         true,
+		Checksum:          "00ff00ff00ff00ff",
+		TraceId:           "trace",
+		Seq:               43,
+	}
+	fields := reflect.ValueOf(frame)
+	for i := range fields.NumField() {
+		if fields.Type().Field(i).IsExported() && fields.Field(i).IsZero() {
+			t.Fatalf("test frame doesn't set %s", fields.Type().Field(i).Name)
+		}
+	}
+
+	encoded, err := json.Marshal(frame.Compact(width))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compact CompactStateChange
+	if err := json.Unmarshal(encoded, &compact); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := compact.Expand()
+	if err != nil {
+		t.Fatalf("expanding %s: %v", encoded, err)
+	}
+	if !reflect.DeepEqual(decoded, frame) {
+		t.Errorf("round trip through %s gave\n%+v\nwant\n%+v", encoded, decoded, frame)
+	}
+}
+
+func TestDecodeFlippedRejectsBadInput(t *testing.T) {
+	for _, input := range []struct {
+		encoded string
+		width   int
+	}{
+		{"not base64!", 10},
+		{EncodeFlipped([][2]int{{1, 2}}, 10)[:2], 10}, // Truncated base64
+		{"gA==", 10},                                  // A varint that never ends
+		{"", 0},
+	} {
+		if _, err := DecodeFlipped(input.encoded, input.width); err == nil {
+			t.Errorf("DecodeFlipped(%q, %d) didn't fail", input.encoded, input.width)
+		}
+	}
+}
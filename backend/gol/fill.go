@@ -0,0 +1,127 @@
+package gol
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Fill Strategies                              */
+/* -------------------------------------------------------------------------- */
+
+const (
+	FillCenter  = "center"  // Circular clusters around the middle of the board
+	FillUniform = "uniform" // Every cell alive with the density as its probability
+	FillPerlin  = "perlin"  // Smooth noise, cells are dense in some bands and sparse in others
+)
+
+// Fills an empty board, density is already defaulted and clamped
+type fillFunc func(board Board, options RandomBoardOptions, density float64, rng *rand.Rand)
+
+var fills = map[string]fillFunc{
+	"":          fillCenter,
+	FillCenter:  fillCenter,
+	FillUniform: fillUniform,
+	FillPerlin:  fillPerlin,
+}
+
+// FillNames returns the names of the fill strategies in sorted order
+func FillNames() []string {
+	names := make([]string, 0, len(fills))
+	for name := range fills {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func fillCenter(board Board, options RandomBoardOptions, density float64, rng *rand.Rand) {
+	length, width := len(board), 0
+	if length > 0 {
+		width = len(board[0])
+	}
+
+	options = options.withDefaults()
+
+	// Number of random clusters
+	numClusters := options.NumClusters
+	if numClusters <= 0 {
+		numClusters = rng.Intn(options.MaxClusters-options.MinClusters+1) + options.MinClusters
+	}
+
+	// Center point
+	centerRowMid := length / 2
+	centerColMid := width / 2
+
+	// Cluster centers are within ±OffsetFraction of the total height and width
+	rowSpan := int(float64(length) * options.OffsetFraction)
+	colSpan := int(float64(width) * options.OffsetFraction)
+
+	for range numClusters {
+		offsetRow := rng.Intn(2*rowSpan+1) - rowSpan
+		offsetCol := rng.Intn(2*colSpan+1) - colSpan
+		centerRow := centerRowMid + offsetRow
+		centerCol := centerColMid + offsetCol
+		radius := rng.Intn(options.MaxRadius-options.MinRadius+1) + options.MinRadius
+
+		// Fill cells in roughly circular clusters
+		for i := -radius; i <= radius; i++ {
+			for j := -radius; j <= radius; j++ {
+				if i*i+j*j <= radius*radius {
+					r := centerRow + i
+					c := centerCol + j
+					if r >= 0 && r < length && c >= 0 && c < width {
+						if rng.Float64() < density {
+							board[r][c] = true
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func fillUniform(board Board, options RandomBoardOptions, density float64, rng *rand.Rand) {
+	for i := range board {
+		for j := range board[i] {
+			board[i][j] = rng.Float64() < density
+		}
+	}
+}
+
+// Cells between two lattice points of the noise
+const perlinScale = 32
+
+// fillPerlin fills with value noise: random values on a coarse lattice are smoothly
+// interpolated, and a cell is alive with twice the density times the noise there,
+// so the board averages out near the density but comes in bands of dense and sparse cells
+func fillPerlin(board Board, options RandomBoardOptions, density float64, rng *rand.Rand) {
+	length, width := len(board), 0
+	if length > 0 {
+		width = len(board[0])
+	}
+
+	lattice := make([][]float64, length/perlinScale+2)
+	for i := range lattice {
+		lattice[i] = make([]float64, width/perlinScale+2)
+		for j := range lattice[i] {
+			lattice[i][j] = rng.Float64()
+		}
+	}
+
+	smooth := func(t float64) float64 { return t * t * (3 - 2*t) }
+	for i := range board {
+		li, ti := i/perlinScale, smooth(float64(i%perlinScale)/perlinScale)
+		for j := range board[i] {
+			lj, tj := j/perlinScale, smooth(float64(j%perlinScale)/perlinScale)
+			top := lattice[li][lj] + (lattice[li][lj+1]-lattice[li][lj])*tj
+			bottom := lattice[li+1][lj] + (lattice[li+1][lj+1]-lattice[li+1][lj])*tj
+			noise := top + (bottom-top)*ti
+
+			board[i][j] = rng.Float64() < math.Min(1, 2*density*noise)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package gol
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestUniformFillMatchesTheDensity(t *testing.T) {
+	const length, width = 200, 300
+	for _, density := range []float64{0.05, 0.25, 0.5, 0.9} {
+		board, err := AmInstance.GetRandomBoard(context.Background(), GetRandomBoardInput{
+			Length:             length,
+			Width:              width,
+			RandomBoardOptions: RandomBoardOptions{Fill: FillUniform, Density: density, Seed: 53},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ratio := float64(board.Population()) / (length * width); math.Abs(ratio-density) > 0.01 {
+			t.Errorf("density %v filled %.4f of the board", density, ratio)
+		}
+
+		// Every quarter of the board gets its share, unlike the center fill
+		for _, quarter := range [][2]int{{0, 0}, {0, width / 2}, {length / 2, 0}, {length / 2, width / 2}} {
+			live := 0
+			for row := quarter[0]; row < quarter[0]+length/2; row++ {
+				for col := quarter[1]; col < quarter[1]+width/2; col++ {
+					if board[row][col] {
+						live++
+					}
+				}
+			}
+			if ratio := float64(live) / (length * width / 4); math.Abs(ratio-density) > 0.02 {
+				t.Errorf("density %v filled %.4f of the quarter at %v", density, ratio, quarter)
+			}
+		}
+	}
+}
+
+func TestUnknownFillIsRejected(t *testing.T) {
+	_, err := AmInstance.GetRandomBoard(context.Background(), GetRandomBoardInput{
+		Length:             10,
+		Width:              10,
+		RandomBoardOptions: RandomBoardOptions{Fill: "spiral"},
+	})
+	var applicationErr *temporal.ApplicationError
+	if !errors.As(err, &applicationErr) || !applicationErr.NonRetryable() {
+		t.Errorf("fill spiral failed with %v, want a non retryable error", err)
+	}
+}
+
+func TestCenterFillStaysWithinItsOptions(t *testing.T) {
+	const length, width = 100, 100
+	for seed := int64(1); seed <= 20; seed++ {
+		board, err := AmInstance.GetRandomBoard(context.Background(), GetRandomBoardInput{
+			Length: length,
+			Width:  width,
+			RandomBoardOptions: RandomBoardOptions{
+				Density:        1,
+				MinClusters:    1,
+				MaxClusters:    1,
+				MinRadius:      3,
+				MaxRadius:      3,
+				OffsetFraction: 0.1,
+				Seed:           seed,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A single full disc of radius 3, centered within ±10 cells of the middle
+		if population := board.Population(); population != 29 {
+			t.Errorf("seed %d: %d live cells, want the 29 of a single disc", seed, population)
+		}
+		for _, cell := range board.LiveCells() {
+			if cell[0] < 50-10-3 || cell[0] > 50+10+3 || cell[1] < 50-10-3 || cell[1] > 50+10+3 {
+				t.Errorf("seed %d: live cell %v is outside the offset and radius", seed, cell)
+			}
+		}
+	}
+}
+
+func TestRandomBoardOptionsAreValidated(t *testing.T) {
+	for _, options := range []RandomBoardOptions{
+		{MinClusters: 8, MaxClusters: 3},
+		{MinRadius: 6, MaxRadius: 2},
+		{MinRadius: -1},
+		{MaxClusters: -2},
+		{OffsetFraction: 0.7},
+	} {
+		if err := options.Validate(); err == nil {
+			t.Errorf("%+v is valid", options)
+		}
+	}
+	for _, options := range []RandomBoardOptions{
+		{},
+		{MinClusters: 20}, // The max follows the min above the default
+		{MaxRadius: 1},    // The min follows the max below the default
+		{MinRadius: 1, MaxRadius: 1, OffsetFraction: 0.5},
+	} {
+		if err := options.Validate(); err != nil {
+			t.Errorf("%+v is invalid: %v", options, err)
+		}
+	}
+}
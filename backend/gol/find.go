@@ -0,0 +1,175 @@
+package gol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Find Pattern                                */
+/* -------------------------------------------------------------------------- */
+
+// Finding a pattern slides its grid over the board and reports every offset where the
+// window matches cell for cell (dead cells of the pattern have to be dead on the board too).
+// With Transforms set the rotations and reflections of the pattern are searched as well,
+// orientations that look the same (a blinker turned 180°) are only searched once.
+//
+// A running game's board is searched with the findPattern query, any other board (e.g. a
+// recorded step or an archived game) with the FindPattern activity. Both share the same scan.
+
+// Pattern to look for with the findPattern query, either a grid or the name of a known pattern
+type FindPatternRequest struct {
+	Pattern    Board  `json:"pattern,omitempty"` // Dead cells of the pattern have to be dead on the board too
+	Name       string `json:"name,omitempty"`    // One of PatternNames, when there is no grid
+	Transforms bool   `json:"transforms"`        // Also search the 3 rotations and their reflections
+}
+
+// Occurrence of the pattern, the size tells which orientation matched
+type PatternMatch struct {
+	Row  int `json:"row"` // Top left corner on the board
+	Col  int `json:"col"`
+	Rows int `json:"rows"`
+	Cols int `json:"cols"`
+}
+
+// Occurrences of the pattern served by the findPattern query
+type PatternMatches struct {
+	Step    int            `json:"step"`
+	Matches []PatternMatch `json:"matches"`
+}
+
+// Board to search with the FindPattern activity and the pattern to look for
+type FindPatternInput struct {
+	Board *PackedBoard
+	FindPatternRequest
+}
+
+func (a *Am) FindPattern(ctx context.Context, input FindPatternInput) ([]PatternMatch, error) {
+	// Retrying can't fix the input
+	if input.Board == nil {
+		return nil, invalidBoardError(errors.New("find pattern requires a board"))
+	}
+	if err := input.Board.Validate(); err != nil {
+		return nil, invalidBoardError(err)
+	}
+	pattern, err := input.grid()
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError(err.Error(), "InvalidPattern", nil)
+	}
+	return FindPattern(input.Board.Unpack(), pattern, input.Transforms), nil
+}
+
+// grid returns the pattern to look for, checking it's a rectangle
+func (r FindPatternRequest) grid() (Board, error) {
+	if r.Pattern == nil {
+		pattern, ok := Pattern(r.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown pattern %q, send a grid or one of: %s", r.Name, patternList())
+		}
+		return pattern, nil
+	}
+	if _, _, err := r.Pattern.Dimensions(); err != nil {
+		return nil, err
+	}
+	return r.Pattern, nil
+}
+
+// FindPattern returns every occurrence of the pattern in row-major order of the top left corner
+// A pattern larger than the board (or an empty one) has no occurrences
+func FindPattern(board Board, pattern Board, transforms bool) []PatternMatch {
+	orientations := []Board{pattern}
+	if transforms {
+		orientations = patternOrientations(pattern)
+	}
+
+	matches := []PatternMatch{}
+	for i := range board {
+		for j := range board[i] {
+			for _, orientation := range orientations {
+				if matchesAt(board, orientation, i, j) {
+					matches = append(matches, PatternMatch{Row: i, Col: j, Rows: len(orientation), Cols: len(orientation[0])})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func matchesAt(board Board, pattern Board, row, col int) bool {
+	if len(pattern) == 0 || len(pattern[0]) == 0 || row+len(pattern) > len(board) || col+len(pattern[0]) > len(board[row]) {
+		return false
+	}
+	for i, line := range pattern {
+		for j, alive := range line {
+			if board[row+i][col+j] != alive {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// patternOrientations returns the distinct rotations and reflections of the pattern
+func patternOrientations(pattern Board) []Board {
+	var orientations []Board
+	seen := make(map[string]bool)
+	current := pattern
+	for range 4 {
+		for _, orientation := range []Board{current, mirror(current)} {
+			key := patternKey(orientation)
+			if !seen[key] {
+				seen[key] = true
+				orientations = append(orientations, orientation)
+			}
+		}
+		current = rotate(current)
+	}
+	return orientations
+}
+
+// rotate turns the pattern 90° clockwise
+func rotate(pattern Board) Board {
+	if len(pattern) == 0 {
+		return pattern
+	}
+	rows, cols := len(pattern), len(pattern[0])
+	rotated := make(Board, cols)
+	for i := range rotated {
+		rotated[i] = make([]bool, rows)
+		for j := range rotated[i] {
+			rotated[i][j] = pattern[rows-1-j][i]
+		}
+	}
+	return rotated
+}
+
+// mirror reflects the pattern left to right
+func mirror(pattern Board) Board {
+	reflected := make(Board, len(pattern))
+	for i, line := range pattern {
+		reflected[i] = make([]bool, len(line))
+		for j, alive := range line {
+			reflected[i][len(line)-1-j] = alive
+		}
+	}
+	return reflected
+}
+
+func patternKey(pattern Board) string {
+	var b strings.Builder
+	for _, line := range pattern {
+		for _, alive := range line {
+			if alive {
+				b.WriteByte('O')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package gol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+// Two horizontal blinkers and a vertical one, away from each other and the edges
+func blinkers() Board {
+	return parseBoard(
+		"..........",
+		".###......",
+		"..........",
+		"......#...",
+		"......#...",
+		"......#...",
+		"..........",
+		"..###.....",
+		"..........",
+	)
+}
+
+func TestFindPattern(t *testing.T) {
+	// The dead cells around it keep a blinker from matching inside a longer line
+	blinker := parseBoard(
+		".....",
+		".###.",
+		".....",
+	)
+	tests := []struct {
+		name       string
+		board      Board
+		pattern    Board
+		transforms bool
+		want       []PatternMatch
+	}{
+		{"horizontal blinkers", blinkers(), blinker, false, []PatternMatch{{0, 0, 3, 5}, {6, 1, 3, 5}}},
+		{"every blinker", blinkers(), blinker, true, []PatternMatch{{0, 0, 3, 5}, {2, 5, 5, 3}, {6, 1, 3, 5}}},
+		{"line of four", parseBoard("......", ".####.", "......"), blinker, true, []PatternMatch{}},
+		{"pattern larger than the board", parseBoard("###"), blinker, true, []PatternMatch{}},
+		{"empty pattern", blinkers(), Board{}, true, []PatternMatch{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FindPattern(test.board, test.pattern, test.transforms)
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("found %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindPatternActivity(t *testing.T) {
+	// A named pattern has no dead margin, so each blinker matches on its own 3 cells
+	matches, err := AmInstance.FindPattern(context.Background(), FindPatternInput{
+		Board:              blinkers().Pack(),
+		FindPatternRequest: FindPatternRequest{Name: "blinker", Transforms: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[{1 1 1 3} {3 6 3 1} {7 2 1 3}]"; fmt.Sprint(matches) != want {
+		t.Errorf("found %v, want %s", matches, want)
+	}
+
+	for _, input := range []FindPatternInput{
+		{FindPatternRequest: FindPatternRequest{Name: "blinker"}},
+		{Board: &PackedBoard{Rows: 4, Cols: 4}, FindPatternRequest: FindPatternRequest{Name: "blinker"}},
+		{Board: blinkers().Pack(), FindPatternRequest: FindPatternRequest{Name: "spaceship"}},
+		{Board: blinkers().Pack(), FindPatternRequest: FindPatternRequest{Pattern: Board{{true}, {true, true}}}},
+	} {
+		_, err := AmInstance.FindPattern(context.Background(), input)
+		var applicationErr *temporal.ApplicationError
+		if !errors.As(err, &applicationErr) || !applicationErr.NonRetryable() {
+			t.Errorf("board %v and pattern %q %v failed with %v, want a non retryable error", input.Board, input.Name, input.Pattern, err)
+		}
+	}
+}
+
+func TestPatternOrientations(t *testing.T) {
+	glider, _ := Pattern("glider")
+	block := parseBoard("##", "##")
+	blinker, _ := Pattern("blinker")
+	for _, test := range []struct {
+		pattern Board
+		want    int
+	}{
+		{glider, 8},
+		{blinker, 2},
+		{block, 1},
+	} {
+		if got := len(patternOrientations(test.pattern)); got != test.want {
+			t.Errorf("%s has %d orientations, want %d", patternKey(test.pattern), got, test.want)
+		}
+	}
+}
+
+func TestFindPatternQuery(t *testing.T) {
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: blinkers().Pack(), MaxSteps: 1, TickTime: time.Millisecond})
+
+	// A generation later the horizontal blinkers stand up and the vertical one lies down
+	var matches PatternMatches
+	game.query(t, "findPattern", &matches, FindPatternRequest{Pattern: parseBoard("#", "#", "#")})
+	if want := []PatternMatch{{0, 2, 3, 1}, {6, 3, 3, 1}}; matches.Step != 1 || fmt.Sprint(matches.Matches) != fmt.Sprint(want) {
+		t.Errorf("found %+v, want %v at step 1", matches, want)
+	}
+
+	game.query(t, "findPattern", &matches, FindPatternRequest{Name: "blinker", Transforms: true})
+	if len(matches.Matches) != 3 {
+		t.Errorf("found %d blinkers by name, want 3", len(matches.Matches))
+	}
+
+	for _, request := range []FindPatternRequest{
+		{Name: "spaceship"},
+		{Pattern: Board{{true, true}, {true}}},
+	} {
+		if _, err := game.env.QueryWorkflow("findPattern", request); err == nil {
+			t.Errorf("finding %+v didn't fail", request)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package gol
 import (
 	"context"
 	"fmt"
-	"log"
+	"slices"
 	"time"
 
 	"go.temporal.io/sdk/workflow"
@@ -21,51 +21,270 @@ const (
 	DefaultBoardLength   = 512
 	DefaultBoardWidth    = 512
 	DefaultStoreInterval = 50
+
+	// Full board keyframes bound how long a client that dropped a frame stays out of sync
+	DefaultKeyframeInterval = 100
+
+	// Recordings keep a full copy of the board per step (~256KB at 512x512),
+	// so cap the buffer to keep a recording workflow's memory around 32MB
+	MaxRecordedSteps = 128
+
+	// Continue-as-new carries the newest recorded boards packed within this budget, temporal
+	// caps a payload at 2MB. That is every recorded step up to ~256x256, 32 steps at 512x512
+	MaxCarriedHistoryBytes = 1 << 20
 )
 
 // true means alive, false means dead
 type Board [][]bool
 
-// In memory board
-var GolBoard Board
-
-// Global step counter
-var Steps int
-
 // State change object
 type StateChange struct {
+	Id                string        `json:"id"`
+	Paused            bool          `json:"paused"`
+	Step              int           `json:"step"`
+	TickTime          time.Duration `json:"tickTime"`
+	Flipped           [][2]int      `json:"flipped"`              // slice of [row, col] pairs
+	Cells             [][3]int      `json:"cells,omitempty"`      // Generations only, slice of [row, col, state] for every cell whose state changed
+	Full              bool          `json:"full,omitempty"`       // Flipped holds every live cell, clients reset their board before applying it
+	Terminated        bool          `json:"terminated,omitempty"` // The board settled and this is the game's last frame
+	Period            int           `json:"period,omitempty"`     // Period the board repeats with once terminated, 1 for a still life
+	Population        int           `json:"population"`           // Live cells after the change
+	EffectiveTickTime time.Duration `json:"effectiveTickTime"`    // Tick time after backpressure, equal to TickTime unless the game was slowed down
+	Walls             [][2]int      `json:"walls,omitempty"`      // Full frames only, every wall cell as [row, col]
+	Births            int           `json:"births,omitempty"`     // Cells that came alive in this change (also set on keyframes)
+	Deaths            int           `json:"deaths,omitempty"`     // Cells that died in this change (also set on keyframes)
+	Ended             string        `json:"ended,omitempty"`      // Only on the game's last message, why it ended (see EndedMaxSteps)
+	Rows              int           `json:"rows,omitempty"`       // Full frames only, the board's size
+	Cols              int           `json:"cols,omitempty"`       // Full frames only, also the width of the compact encoding
+	Born              [][2]int      `json:"born,omitempty"`       // Diff frames of games with SplitFlips only, the flipped cells that came alive
+	Died              [][2]int      `json:"died,omitempty"`       // The rest of the flipped cells, those that died
+	Teams             []uint8       `json:"teams,omitempty"`      // Team games only, the team of each flipped cell (base64, a byte per cell)
+	TeamPopulations   []int         `json:"teamPopulations,omitempty"`
+	Restarted         bool          `json:"restarted,omitempty"` // Full frame of a board reseeded after it died out (see restart.go)
+	Checksum          string        `json:"checksum,omitempty"`  // Games with Checksums only, Checksum (see cycle.go) of the board after the change
+	TraceId           string        `json:"traceId,omitempty"`   // Trace id of the game (see trace.go), set by the activity publishing the frame
+	Seq               int           `json:"seq"`                 // Position of the frame in the game's stream, on the board query the last frame the board includes
+
+	firstSeq int // Seq of the oldest frame merged into this one (see MergeStateChanges), 0 when it wasn't merged
+}
+
+// FirstSeq returns the Seq of the oldest frame the state change covers, merged frames cover several
+// A client whose board already includes that frame can't apply the state change on top of it
+func (s StateChange) FirstSeq() int {
+	if s.firstSeq > 0 {
+		return s.firstSeq
+	}
+	return s.Seq
+}
+
+// Most generations batched into a frame, they are all computed within a single workflow task
+const MaxTicksPerFrame = 64
+
+// ValidateTicksPerFrame checks the generations per frame, 0 is the default of 1
+func ValidateTicksPerFrame(ticks int) error {
+	if ticks < 0 || ticks > MaxTicksPerFrame {
+		return fmt.Errorf("ticks per frame must be between 1 and %d, got %d", MaxTicksPerFrame, ticks)
+	}
+	return nil
+}
+
+// Why a game ended, sent after its last frame so clients can tell it from a dropped connection
+const (
+	EndedMaxSteps   = "maxSteps"   // Ran all of its steps
+	EndedTerminated = "terminated" // The board settled into a still life or oscillator
+	EndedExtinct    = "extinct"    // The board settled with every cell dead
+)
+
+// Game state object (managed by the signal handlers)
+// The board and step counter are scoped to the workflow so games on the same worker don't interfere
+type GolState struct {
+	Id                   string
+	Paused               bool
+	TickTime             time.Duration
+	Step                 int
+	Board                Board
+	Changed              [][2]int // Cells changed since the last generation, only these and their neighbors can change next
+	FullScan             bool     // Forces the next generation to evaluate every cell (e.g. a fresh board)
+	Recording            bool
+	History              []BoardSnapshot
+	Neighborhood         Neighborhood
+	Birth                NeighborCounts     // Neighbor counts bringing a dead cell alive, zero with Survival for B3/S23
+	Survival             NeighborCounts     // Neighbor counts keeping a live cell alive
+	Generations          int                // Number of cell states, above 2 dying cells decay (see generations.go)
+	Ages                 [][]uint8          // Each cell's state when Generations is enabled
+	StoreInterval        int                // Steps between continue-as-new
+	KeyframeInterval     int                // Steps between full board keyframes
+	RandomBoard          RandomBoardOptions // Used whenever the board is reseeded
+	CycleWindow          int                // Recent boards compared against to detect oscillators
+	RecentHashes         []uint64           // Hashes of the last CycleWindow boards, newest last
+	Terminated           bool               // The board settled into a still life or oscillator
+	Period               int                // Period of the oscillator the board settled into
+	RemainingSteps       int                // Generations left before pausing, 0 runs until MaxSteps
+	MaxSteps             int                // The game ends at this step (the setMaxSteps signal moves it)
+	Backpressure         bool               // Slow down while subscribers drop frames (see backpressure.go)
+	BackpressureTickTime time.Duration      // Tick time backpressure slowed the game to, 0 when not slowed
+	congestedFrames      int                // Frames in a row that some subscriber dropped
+	pendingFlips         [][2]int           // Flipped by signal handlers since the last published frame (see compaction.go)
+	pendingFull          bool               // A signal handler needs the next published frame to be a full one
+	pendingStatus        bool               // A signal handler changed the pause state, publish a frame even without flips
+	flippedBuf           [][2]int           // Reused by full scans so they don't allocate the flipped cells every generation
+	scratch              bitScratch         // Bit boards reused by full scans so they don't allocate a board every generation
+	lastDiff             int                // Cells flipped by the last published diff frame, served by the debug query
+	liveHash             uint64             // Hash of the live cells kept up to date from the flips (see cycle.go)
+	population           int                // Live cells, kept up to date along with liveHash
+	tracked              bool               // liveHash and population match the board, false until they are counted
+	TicksPerFrame        int                // Generations computed per tick and published as one frame
+	Walls                Board              // Wall layer (see walls.go), nil when there are no walls
+	WallsAlive           bool               // Walls count as live neighbors instead of blocking
+	AdaptiveTick         bool               // Scale the tick time with the population (see adaptive.go)
+	adaptiveTickTime     time.Duration      // Tick time for the current population, 0 until the first generation
+	Headless             bool               // Nothing is published to the state stream
+	Wrap                 bool               // The board is a torus (see wrap.go)
+	SplitFlips           bool               // Diff frames also list the flipped cells as born and died
+	Teams                [][]uint8          // Each cell's team in a team game (see teams.go), nil otherwise
+	Checksums            bool               // Frames carry a checksum of the board
+	Seq                  int                // Frames published so far, the Seq of the last one
+	AutoRestart          bool               // Reseed the board once it died out instead of ending the game (see restart.go)
+	RestartAfter         int                // Steps the board stays empty before it is reseeded
+	DeadSteps            int                // Steps the board has been empty for
+	Range                int                // Radius of a Larger than Life neighborhood (see kernel.go), 0 uses the rule's counts
+	RangeBirth           CountRange         // Live cells within the range bringing a dead cell alive
+	RangeSurvival        CountRange         // Live cells within the range keeping a live cell alive
+}
+
+// Rule returns the rule the game's generations are computed with
+func (s GolState) Rule() Rule {
+	return Rule{Neighborhood: s.Neighborhood, Birth: s.Birth, Survival: s.Survival, Walls: s.Walls, WallsAlive: s.WallsAlive, Wrap: s.Wrap}
+}
+
+// Full board served by the fullBoard query
+type FullBoard struct {
 	Id       string        `json:"id"`
 	Paused   bool          `json:"paused"`
 	Step     int           `json:"step"`
 	TickTime time.Duration `json:"tickTime"`
-	Flipped  [][2]int      `json:"flipped"` // slice of [row, col] pairs
+	Board    *PackedBoard  `json:"board"`
 }
 
-// Game state object (managed by the signal handlers)
-type GolState struct {
-	Id       string
-	Paused   bool
-	TickTime time.Duration
+// Live cell count served by the population query
+type PopulationStats struct {
+	Step          int           `json:"step"`
+	Population    int           `json:"population"`
+	Paused        bool          `json:"paused"`
+	TickTime      time.Duration `json:"tickTime"`
+	CycleDetected bool          `json:"cycleDetected"` // The board repeated within the cycle window (see cycle.go)
+	Period        int           `json:"period"`        // Period of the cycle, 0 when none was detected
 }
 
-// Iniitial configuration object for the workflow
-type GameOfLifeInput struct {
-	MaxSteps         int
-	TickTime         time.Duration
-	UseExistingBoard bool
-	Paused           bool
+// Next generation's changes served by the preview query
+type Preview struct {
+	Step    int      `json:"step"`            // Step the changes would be published at
+	Flipped [][2]int `json:"flipped"`         // slice of [row, col] pairs
+	Cells   [][3]int `json:"cells,omitempty"` // Generations only, like StateChange.Cells
 }
 
-// TODO: Implement Signal handling
-const SplatterSignalName = "splatter"
+// Hash of the board served by the boardHash query
+type BoardHashResult struct {
+	Step int    `json:"step"`
+	Hash string `json:"hash"` // BoardHash as 16 hex digits, a JSON number would lose precision in browsers
+}
 
-type SplatterSignal struct {
-	X    int `json:"x"`
-	Y    int `json:"y"`
-	Size int `json:"size"`
+// Rectangle of the board requested by the region query
+type RegionRequest struct {
+	Row    int `json:"row"`
+	Col    int `json:"col"`
+	Height int `json:"height"`
+	Width  int `json:"width"`
 }
 
-const ToggleStatusSignal = "toggleStatus"
+// Live cells within a rectangle served by the region query
+// The rectangle is the requested one clamped to the board
+type Region struct {
+	Step   int      `json:"step"`
+	Row    int      `json:"row"`
+	Col    int      `json:"col"`
+	Height int      `json:"height"`
+	Width  int      `json:"width"`
+	Cells  [][2]int `json:"cells"` // [row, col] relative to the rectangle's top left corner
+}
+
+// Workflow internals served by the debug query, to line up glitches seen by clients with the server's state
+type DebugState struct {
+	Id                string        `json:"id"`
+	Step              int           `json:"step"`
+	MaxSteps          int           `json:"maxSteps"`
+	Paused            bool          `json:"paused"`
+	TickTime          time.Duration `json:"tickTime"`
+	EffectiveTickTime time.Duration `json:"effectiveTickTime"`
+	Rows              int           `json:"rows"`
+	Cols              int           `json:"cols"`
+	Population        int           `json:"population"`
+	LastDiff          int           `json:"lastDiff"` // Cells flipped by the last diff frame
+	Changed           int           `json:"changed"`  // Cells the next generation starts its scan from
+	FullScan          bool          `json:"fullScan"`
+	RemainingSteps    int           `json:"remainingSteps"`
+	Terminated        bool          `json:"terminated"`
+}
+
+// Full copy of the board at a given step (used for time-travel debugging)
+type BoardSnapshot struct {
+	Step  int   `json:"step"`
+	Board Board `json:"board"`
+}
+
+// Recorded board carried through continue-as-new
+type PackedSnapshot struct {
+	Step  int
+	Board *PackedBoard
+}
+
+// Iniitial configuration object for the workflow
+type GameOfLifeInput struct {
+	MaxSteps             int
+	Step                 int
+	TickTime             time.Duration
+	Board                *PackedBoard // Carried through continue-as-new, nil starts a random board
+	Paused               bool
+	Recording            bool
+	History              []PackedSnapshot   // Recorded boards carried through continue-as-new, oldest first
+	Neighborhood         Neighborhood       // Moore (default) or VonNeumann
+	Rule                 string             // Rulestring like B36/S23, defaults to B3/S23 (the setRule signal changes it)
+	Generations          int                // Number of cell states, 0 or 2 is the classic game
+	Ages                 []uint8            // Row-major cell states carried through continue-as-new
+	StoreInterval        int                // Steps between continue-as-new, trades history length for replay cost (defaults to 50)
+	KeyframeInterval     int                // Steps between full board keyframes (defaults to 100)
+	RandomBoard          RandomBoardOptions // Density and clusters of random boards
+	CycleWindow          int                // Recent boards compared against to detect oscillators, the game stops once the board repeats (defaults to 16)
+	RecentHashes         []uint64           // Carried through continue-as-new so cycles spanning a rollover are detected
+	RunSteps             int                // Generations to run before pausing, 0 runs until MaxSteps (continue-as-new carries what's left)
+	Backpressure         bool               // Slow the tick down while subscribers can't keep up
+	BackpressureTickTime time.Duration      // Carried through continue-as-new
+	Walls                *PackedBoard       // Wall layer, nil for no walls
+	WallsAlive           bool               // Walls count as live neighbors instead of blocking
+	AdaptiveTick         bool               // Run faster on sparse boards, TickTime is the tick of a dense board
+	Headless             bool               // Skip SendState entirely to measure raw generation throughput
+	Wrap                 bool               // Wrap the edges around so patterns leaving one side come back on the other
+	Rows                 int                // Size of a random board, ignored when Board is set (defaults to DefaultBoardLength)
+	Cols                 int                // Defaults to DefaultBoardWidth
+	SplitFlips           bool               // Also send the flipped cells split into Born and Died, so clients can animate them differently
+	RevealSteps          int                // Frames revealing the board from its center before the first generation (see reveal.go), not carried through continue-as-new
+	Checksums            bool               // Every frame carries a checksum of the board after it, so clients can tell they are out of sync
+	Seq                  int                // Carried through continue-as-new
+	AutoRestart          bool               // Reseed the board with a fresh random one when it dies out instead of ending the game
+	RestartAfter         int                // Steps the board stays empty before it is reseeded (defaults to 10)
+	DeadSteps            int                // Carried through continue-as-new
+	TeamGame             bool               // Cells belong to one of two teams, births join the majority of their neighbors
+	Teams                []uint8            // Row-major cell teams carried through continue-as-new
+	TicksPerFrame        int                // Generations per tick, published as a single frame of their net diff (defaults to 1)
+	Range                int                // Count the live cells within this radius instead of the rule's neighbors (Larger than Life), 0 keeps the rule
+	RangeBirth           CountRange         // Birth counts of a Range game
+	RangeSurvival        CountRange         // Survival counts of a Range game
+
+	// Test only, runs the whole game in one run so tests can drive it to MaxSteps and inspect the
+	// final state. Not for production: the history grows with every frame and signal, a long game
+	// slows down every replay and eventually hits temporal's history size limit
+	DisableContinueAsNew bool
+}
 
 // Main workflow function for the Game of Life
 func GameOfLife(ctx workflow.Context, input GameOfLifeInput) (err error) {
@@ -73,110 +292,564 @@ func GameOfLife(ctx workflow.Context, input GameOfLifeInput) (err error) {
 		input.MaxSteps = DefaultMaxSteps
 	}
 
+	// Activities and the next run inherit the trace id through the context (see trace.go)
+	ctx = withWorkflowTrace(ctx)
+
 	// Initialize the game of life
-	state := Init(ctx, input)
+	state, err := Init(ctx, input)
+	if err != nil {
+		return err
+	}
 
-	// Serve the board as a full from nothing
+	// Serve the board as a diff from an empty board
+	// Kept for clients that reconstruct the board by applying it like any other frame of the diff stream
 	workflow.SetQueryHandler(ctx, "board", func() (StateChange, error) {
-		return StateChangeFromNothing(state), nil
+		stateChange := StateChangeFromNothing(state)
+		stateChange.Seq = state.BoardSeq()
+		return stateChange, nil
 	})
 
-	splatterChannel := workflow.GetSignalChannel(ctx, SplatterSignalName)
-	toggleChannel := workflow.GetSignalChannel(ctx, ToggleStatusSignal)
+	// Serve the actual board (packed) along with the step it was taken at
+	workflow.SetQueryHandler(ctx, "fullBoard", func() (FullBoard, error) {
+		return FullBoard{
+			Id:       state.Id,
+			Paused:   state.Paused,
+			Step:     state.Step,
+			TickTime: state.TickTime,
+			Board:    state.Board.Pack(),
+		}, nil
+	})
 
-	// Setup the selector for concurrent future execution
-	selector := workflow.NewSelector(ctx)
+	// Serve everything needed to restore the game in a new workflow (see snapshot.go)
+	workflow.SetQueryHandler(ctx, "snapshot", func() (GameSnapshot, error) {
+		return state.Snapshot(), nil
+	})
 
-	selector.AddReceive(toggleChannel, func(c workflow.ReceiveChannel, more bool) {
-		c.Receive(ctx, nil)
-		state.Paused = !state.Paused
+	// Serve the workflow internals, everything in it is part of the deterministic state
+	workflow.SetQueryHandler(ctx, "debug", func() (DebugState, error) {
+		return DebugState{
+			Id:                state.Id,
+			Step:              state.Step,
+			MaxSteps:          state.MaxSteps,
+			Paused:            state.Paused,
+			TickTime:          state.TickTime,
+			EffectiveTickTime: state.EffectiveTickTime(),
+			Rows:              len(state.Board),
+			Cols:              len(state.Board[0]),
+			Population:        state.Population(),
+			LastDiff:          state.lastDiff,
+			Changed:           len(state.Changed),
+			FullScan:          state.FullScan,
+			RemainingSteps:    state.RemainingSteps,
+			Terminated:        state.Terminated,
+		}, nil
 	})
 
-	selector.AddReceive(splatterChannel, func(c workflow.ReceiveChannel, more bool) {
-		var signal SplatterSignal
-		c.Receive(ctx, &signal)
+	// Serve the live cells as an RLE pattern (trimmed to their bounding box)
+	workflow.SetQueryHandler(ctx, "exportRLE", func() (string, error) {
+		return EncodeRLE(state.Board, state.Rule()), nil
+	})
 
-		err = DoActivity(ctx, AmInstance.Splatter, SplatterInput{
-			Row:    signal.X,
-			Col:    signal.Y,
-			Radius: signal.Size,
-		})
+	// Serve the number of live cells without the board itself
+	workflow.SetQueryHandler(ctx, "population", func() (PopulationStats, error) {
+		return PopulationStats{
+			Step:          state.Step,
+			Population:    state.Population(),
+			Paused:        state.Paused,
+			TickTime:      state.TickTime,
+			CycleDetected: state.Terminated,
+			Period:        state.Period,
+		}, nil
+	})
+
+	// Serve the changes the next generation would make, computed on copies so the game doesn't advance
+	workflow.SetQueryHandler(ctx, "preview", func() (Preview, error) {
+		return state.Preview(), nil
+	})
+
+	// Serve a hash of the board to compare boards across runs without transferring them
+	workflow.SetQueryHandler(ctx, "boardHash", func() (BoardHashResult, error) {
+		return BoardHashResult{
+			Step: state.Step,
+			Hash: fmt.Sprintf("%016x", BoardHash(state.Board, state.Ages)),
+		}, nil
+	})
+
+	// Serve the live cells within a rectangle of the board (for zoomed in views)
+	workflow.SetQueryHandler(ctx, "region", func(request RegionRequest) (Region, error) {
+		region, err := state.Board.Region(request)
 		if err != nil {
-			log.Fatalf("Error splattering board: %v", err)
+			return Region{}, err
 		}
+		region.Step = state.Step
+		return region, nil
 	})
 
+	// Serve every occurrence of a pattern on the board (e.g. counting the gliders)
+	workflow.SetQueryHandler(ctx, "findPattern", func(request FindPatternRequest) (PatternMatches, error) {
+		pattern, err := request.grid()
+		if err != nil {
+			return PatternMatches{}, err
+		}
+		return PatternMatches{Step: state.Step, Matches: FindPattern(state.Board, pattern, request.Transforms)}, nil
+	})
+
+	// Serve the board's connected components and bounds (for analytics on long runs)
+	workflow.SetQueryHandler(ctx, "boardStats", func() (BoardStatsResult, error) {
+		stats := BoardStats(state.Board)
+		stats.Step = state.Step
+		return stats, nil
+	})
+
+	// Serve a recorded board by its index in the history buffer (oldest first)
+	workflow.SetQueryHandler(ctx, "history", func(index int) (BoardSnapshot, error) {
+		if index < 0 || index >= len(state.History) {
+			return BoardSnapshot{}, fmt.Errorf("history index %d out of range, %d steps recorded", index, len(state.History))
+		}
+		return state.History[index], nil
+	})
+
+	// Serve the cells that changed between two recorded steps
+	workflow.SetQueryHandler(ctx, "diffBetween", func(request DiffRequest) (StepDiff, error) {
+		return state.DiffBetween(request)
+	})
+
+	// A game started recording has its seed as the first snapshot, a continued one already recorded its board
+	if state.Recording && (len(state.History) == 0 || state.History[len(state.History)-1].Step != state.Step) {
+		RecordSnapshot(&state)
+	}
+
+	// Errors are logged and returned instead of exiting so one failing game doesn't take down the worker
+	logger := workflow.GetLogger(ctx)
+
+	// Signals arriving meanwhile wait in their channels for the loop
+	if input.RevealSteps > 0 {
+		if err := Reveal(ctx, &state, input.RevealSteps); err != nil {
+			logger.Error("Error revealing board", "Error", err)
+			return err
+		}
+	}
+
+	// Signal handlers can't fail the workflow themselves, the loop picks up their error after the select
+	var handlerErr error
+
+	// Setup the selector for concurrent future execution
+	// Signals only wake it up, their value stays in the channel until drainSignals handles it
+	selector := workflow.NewSelector(ctx)
+	for _, name := range signalOrder {
+		selector.AddReceive(workflow.GetSignalChannel(ctx, name), func(workflow.ReceiveChannel, bool) {})
+	}
+
+	// drainSignals handles every pending signal with its entry in Signals, going through the channels in
+	// signalOrder, and starts over while a pass handled any (an activity in a handler lets more signals
+	// arrive), so the signals handled together always run in the same order however temporal delivered them
+	drainSignals := func() {
+		for handled := true; handled && handlerErr == nil; {
+			handled = false
+			for _, name := range signalOrder {
+				channel := workflow.GetSignalChannel(ctx, name)
+				for handlerErr == nil && channel.Len() > 0 {
+					handlerErr = Signals[name].Receive(ctx, &state, channel)
+					handled = true
+				}
+			}
+		}
+	}
+
+	// Only one tick timer is pending at a time since signals also wake the selector
+	tickPending := false
+	ticked := false
+
 	// Steps through the generations
-	for Steps < input.MaxSteps {
+	for state.Step < state.MaxSteps {
 
-		if !state.Paused {
-			Steps++
+		if !state.Paused && !tickPending {
+			tickPending = true
 
-			// Add a timer tick to the selector
-			selector.AddFuture(workflow.NewTimer(ctx, state.TickTime), func(f workflow.Future) {
+			// Add a timer tick to the selector, a server side timer so no worker slot is held while
+			// waiting and each game keeps its own tick time however many games share the worker
+			selector.AddFuture(workflow.NewTimer(ctx, state.EffectiveTickTime()), func(f workflow.Future) {
 				f.Get(ctx, nil)
+				tickPending = false
+				ticked = true
 			})
 		}
 
-		// Will block until a future is ready (timer or other future)
+		// Will block until a future is ready (timer or signal)
+		// Then handles every signal that already arrived so their flips go out as one frame
 		selector.Select(ctx)
+		drainSignals()
+		for handlerErr == nil && selector.HasPending() {
+			selector.Select(ctx)
+			drainSignals()
+		}
+
+		// Fail just this workflow, Temporal has already retried the activity per its policy
+		if handlerErr != nil {
+			return handlerErr
+		}
 
-		// Next generation and send state
-		err = NextGenerationAndSendState(ctx, state)
+		err = PublishPending(ctx, &state)
 		if err != nil {
-			log.Fatalf("Error next generation and sending state: %v", err)
+			logger.Error("Error sending signal state", "Error", err)
+			return err
+		}
+
+		// Signals don't advance the generation, only a tick while running does
+		if !ticked {
+			continue
+		}
+		ticked = false
+		if state.Paused {
+			continue
+		}
+
+		// Next generations and send state
+		err = PublishGeneration(ctx, &state, state.AdvanceFrame())
+		if err != nil {
+			logger.Error("Error computing next generation", "Step", state.Step, "Error", err)
+			return err
+		}
+
+		if state.Terminated {
+			logger.Info("Board settled, ending the game", "Step", state.Step, "Period", state.Period)
+			break
+		}
+
+		if state.RestartDue() {
+			logger.Info("Board died out, reseeding it", "Step", state.Step)
+			if err := Restart(ctx, &state); err != nil {
+				logger.Error("Error restarting board", "Step", state.Step, "Error", err)
+				return err
+			}
 		}
 
 		// Avoid large workflow histories
 		// This is the main reason this is not the best use case for temporal
 		// lots of IO to communicate each frame of the gol means long workflow histories.
-		if Steps%DefaultStoreInterval == 0 {
+		if !input.DisableContinueAsNew && state.Step%state.StoreInterval == 0 {
 			return workflow.NewContinueAsNewError(ctx, GameOfLife, GameOfLifeInput{
-				MaxSteps:         input.MaxSteps,
-				TickTime:         state.TickTime,
-				UseExistingBoard: true,
-				Paused:           input.Paused,
+				MaxSteps:             state.MaxSteps,
+				Step:                 state.Step,
+				TickTime:             state.TickTime,
+				Board:                state.Board.Pack(),
+				Paused:               state.Paused,
+				Recording:            state.Recording,
+				History:              PackHistory(state.History),
+				Neighborhood:         state.Neighborhood,
+				Rule:                 state.Rule().Rulestring(),
+				Generations:          state.Generations,
+				Ages:                 PackAges(state.Ages),
+				StoreInterval:        state.StoreInterval,
+				KeyframeInterval:     state.KeyframeInterval,
+				RandomBoard:          state.RandomBoard,
+				CycleWindow:          state.CycleWindow,
+				RecentHashes:         state.RecentHashes,
+				RunSteps:             state.RemainingSteps,
+				Backpressure:         state.Backpressure,
+				BackpressureTickTime: state.BackpressureTickTime,
+				Walls:                packWalls(state.Walls),
+				WallsAlive:           state.WallsAlive,
+				AdaptiveTick:         state.AdaptiveTick,
+				Headless:             state.Headless,
+				Wrap:                 state.Wrap,
+				SplitFlips:           state.SplitFlips,
+				TicksPerFrame:        state.TicksPerFrame,
+				Checksums:            state.Checksums,
+				Seq:                  state.Seq,
+				AutoRestart:          state.AutoRestart,
+				RestartAfter:         state.RestartAfter,
+				DeadSteps:            state.DeadSteps,
+				TeamGame:             state.Teams != nil,
+				Teams:                PackAges(state.Teams),
+				Range:                state.Range,
+				RangeBirth:           state.RangeBirth,
+				RangeSurvival:        state.RangeSurvival,
 			})
 		}
 	}
 
-	// Cleanup the state stream (no more game or updates)
-	StateStream = nil
-
+	// The game is over either way, a failed publish or archive doesn't fail it
+	if err := SendGameEnded(ctx, &state); err != nil {
+		logger.Error("Error sending game ended", "Step", state.Step, "Error", err)
+	}
+	if err := ArchiveFinalState(ctx, state); err != nil {
+		logger.Error("Error archiving final state", "Step", state.Step, "Error", err)
+	}
 	return nil
 }
 
+// OnlyEnded reports whether the change just says the game ended, with no cells to apply
+// The hub may merge the game ended message into the last frame, that one still has to be applied
+func (s StateChange) OnlyEnded() bool {
+	return s.Ended != "" && !s.Full && len(s.Flipped) == 0 && len(s.Cells) == 0
+}
+
+// SendGameEnded tells the subscribers why the game ended with an empty change after its last frame
+func SendGameEnded(ctx workflow.Context, golState *GolState) error {
+	stateChange := golState.StateChange(nil)
+	stateChange.Terminated = golState.Terminated
+	stateChange.Period = golState.Period
+	switch {
+	case golState.Terminated && stateChange.Population == 0:
+		stateChange.Ended = EndedExtinct
+	case golState.Terminated:
+		stateChange.Ended = EndedTerminated
+	default:
+		stateChange.Ended = EndedMaxSteps
+	}
+	_, err := PublishState(ctx, golState, stateChange)
+	return err
+}
+
 /* -------------------------------------------------------------------------- */
 /*                                   Helpers                                  */
 /* -------------------------------------------------------------------------- */
 
-// Init enforces defaults for the game of life
-func Init(ctx workflow.Context, input GameOfLifeInput) GolState {
+// Init enforces defaults and validates the input for the game of life
+func Init(ctx workflow.Context, input GameOfLifeInput) (GolState, error) {
+	if err := input.Neighborhood.Validate(); err != nil {
+		return GolState{}, err
+	}
+	if err := ValidateGenerations(input.Generations); err != nil {
+		return GolState{}, err
+	}
+	var birth, survival NeighborCounts
+	if input.Rule != "" {
+		var err error
+		if birth, survival, err = ParseRule(input.Rule); err != nil {
+			return GolState{}, err
+		}
+	}
+	if input.StoreInterval < 0 {
+		return GolState{}, fmt.Errorf("store interval must be positive, got %d", input.StoreInterval)
+	}
+	if input.StoreInterval == 0 {
+		input.StoreInterval = DefaultStoreInterval
+	}
+	if input.KeyframeInterval < 0 {
+		return GolState{}, fmt.Errorf("keyframe interval must be positive, got %d", input.KeyframeInterval)
+	}
+	if input.KeyframeInterval == 0 {
+		input.KeyframeInterval = DefaultKeyframeInterval
+	}
+	if err := input.RandomBoard.Validate(); err != nil {
+		return GolState{}, err
+	}
+	if err := ValidateRange(input.Range, input.RangeBirth, input.RangeSurvival); err != nil {
+		return GolState{}, err
+	}
+	if input.Range > 0 && (input.Generations > 2 || input.Neighborhood != Moore) {
+		return GolState{}, fmt.Errorf("range only works with the Moore neighborhood and without generations")
+	}
+	if err := ValidateRevealSteps(input.RevealSteps); err != nil {
+		return GolState{}, err
+	}
+	if input.RunSteps < 0 {
+		return GolState{}, fmt.Errorf("run steps must be positive, got %d", input.RunSteps)
+	}
+	if err := ValidateTicksPerFrame(input.TicksPerFrame); err != nil {
+		return GolState{}, err
+	}
+	if input.TicksPerFrame == 0 {
+		input.TicksPerFrame = 1
+	}
+	if input.RestartAfter < 0 {
+		return GolState{}, fmt.Errorf("restart after must be positive, got %d", input.RestartAfter)
+	}
+	if input.RestartAfter == 0 {
+		input.RestartAfter = DefaultRestartAfter
+	}
+	if input.CycleWindow < 0 {
+		return GolState{}, fmt.Errorf("cycle window must be positive, got %d", input.CycleWindow)
+	}
+	if input.CycleWindow == 0 {
+		input.CycleWindow = DefaultCycleWindow
+	}
 	if input.MaxSteps == 0 {
 		input.MaxSteps = DefaultMaxSteps
 	}
 
-	// Get a random board
-	board, err := DoActivityWithOutput(ctx, AmInstance.GetInitialBoard, GetInitialBoardInput{
-		Length:           DefaultBoardLength,
-		Width:            DefaultBoardWidth,
-		UseInMemoryBoard: input.UseExistingBoard,
-	})
-	if err != nil {
-		log.Fatalf("Error getting random board: %v", err)
+	// Continue with the board from the previous run, otherwise get a random board
+	var board Board
+	if input.Board != nil {
+		if err := input.Board.Validate(); err != nil {
+			return GolState{}, invalidBoardError(err)
+		}
+		board = input.Board.Unpack()
+	} else {
+		if input.Rows == 0 {
+			input.Rows = DefaultBoardLength
+		}
+		if input.Cols == 0 {
+			input.Cols = DefaultBoardWidth
+		}
+		if err := ValidateBoardSize(input.Rows, input.Cols); err != nil {
+			return GolState{}, invalidBoardError(err)
+		}
+
+		var err error
+		board, err = DoActivityWithOutput(ctx, AmInstance.GetInitialBoard, GetInitialBoardInput{
+			Length:             input.Rows,
+			Width:              input.Cols,
+			RandomBoardOptions: input.RandomBoard,
+		})
+		if err != nil {
+			workflow.GetLogger(ctx).Error("Error getting random board", "Error", err)
+			return GolState{}, err
+		}
+	}
+
+	if err := board.Validate(); err != nil {
+		return GolState{}, invalidBoardError(err)
+	}
+	rows, cols := len(board), len(board[0])
+
+	// Track the cell states for Generations rules
+	var ages [][]uint8
+	if input.Generations > 2 {
+		if input.Ages != nil {
+			if len(input.Ages) != rows*cols {
+				return GolState{}, fmt.Errorf("ages has %d cells but the board has %d", len(input.Ages), rows*cols)
+			}
+			ages = UnpackAges(input.Ages, rows, cols)
+		} else {
+			ages = NewAges(board, input.Generations)
+		}
+	}
+
+	var teams [][]uint8
+	if input.TeamGame {
+		if input.Generations > 2 || input.Range > 0 {
+			return GolState{}, fmt.Errorf("team games don't work with generations or range")
+		}
+		if input.Teams != nil {
+			if len(input.Teams) != rows*cols {
+				return GolState{}, fmt.Errorf("teams has %d cells but the board has %d", len(input.Teams), rows*cols)
+			}
+			for _, team := range input.Teams {
+				if err := ValidateTeam(team); err != nil {
+					return GolState{}, err
+				}
+			}
+			teams = UnpackAges(input.Teams, rows, cols)
+		} else {
+			teams = NewTeams(rows, cols)
+		}
+	}
+
+	var walls Board
+	if input.Walls != nil {
+		if input.Walls.Rows != rows || input.Walls.Cols != cols {
+			return GolState{}, fmt.Errorf("walls are %dx%d but the board is %dx%d", input.Walls.Rows, input.Walls.Cols, rows, cols)
+		}
+		if err := input.Walls.Validate(); err != nil {
+			return GolState{}, err
+		}
+		walls = input.Walls.Unpack()
+	}
+
+	history := make([]BoardSnapshot, 0, len(input.History))
+	for _, snapshot := range input.History {
+		if snapshot.Board == nil || snapshot.Board.Rows != rows || snapshot.Board.Cols != cols {
+			return GolState{}, fmt.Errorf("recorded step %d doesn't match the %dx%d board", snapshot.Step, rows, cols)
+		}
+		if err := snapshot.Board.Validate(); err != nil {
+			return GolState{}, err
+		}
+		history = append(history, BoardSnapshot{Step: snapshot.Step, Board: snapshot.Board.Unpack()})
 	}
-	GolBoard = board
 
 	// Get the current workflows ID
 	workflowId := workflow.GetInfo(ctx).WorkflowExecution.ID
 
 	return GolState{
-		Id:       workflowId,
-		Paused:   input.Paused,
-		TickTime: input.TickTime,
+		Id:                   workflowId,
+		Paused:               input.Paused,
+		TickTime:             input.TickTime,
+		Step:                 input.Step, // Resume the step counter from the previous run (zero for a new game)
+		Board:                board,
+		FullScan:             true,
+		Recording:            input.Recording,
+		History:              history,
+		Neighborhood:         input.Neighborhood,
+		Birth:                birth,
+		Survival:             survival,
+		Generations:          input.Generations,
+		Ages:                 ages,
+		StoreInterval:        input.StoreInterval,
+		KeyframeInterval:     input.KeyframeInterval,
+		RandomBoard:          input.RandomBoard,
+		CycleWindow:          input.CycleWindow,
+		RecentHashes:         input.RecentHashes,
+		RemainingSteps:       input.RunSteps,
+		MaxSteps:             input.MaxSteps,
+		Backpressure:         input.Backpressure,
+		BackpressureTickTime: input.BackpressureTickTime,
+		Walls:                walls,
+		WallsAlive:           input.WallsAlive,
+		AdaptiveTick:         input.AdaptiveTick,
+		Headless:             input.Headless,
+		Wrap:                 input.Wrap,
+		SplitFlips:           input.SplitFlips,
+		TicksPerFrame:        input.TicksPerFrame,
+		Teams:                teams,
+		Checksums:            input.Checksums,
+		Seq:                  input.Seq,
+		AutoRestart:          input.AutoRestart,
+		RestartAfter:         input.RestartAfter,
+		DeadSteps:            input.DeadSteps,
+		Range:                input.Range,
+		RangeBirth:           input.RangeBirth,
+		RangeSurvival:        input.RangeSurvival,
+	}, nil
+}
+
+// ReplaceBoard swaps in a whole new board, resetting everything derived from the old one
+func (s *GolState) ReplaceBoard(board Board) {
+	s.Board = board
+	for i := range s.Walls {
+		for j := range s.Walls[i] {
+			if s.Walls[i][j] {
+				board[i][j] = false
+			}
+		}
+	}
+	s.Changed = nil
+	s.FullScan = true
+	s.DeadSteps = 0
+	s.tracked = false
+	if s.Generations > 2 {
+		s.Ages = NewAges(board, s.Generations)
+	}
+	if s.Teams != nil {
+		s.Teams = NewTeams(len(board), len(board[0]))
 	}
 }
 
+// RecordSnapshot appends a copy of the current board to the history, dropping the oldest when full
+func RecordSnapshot(state *GolState) {
+	if len(state.History) >= MaxRecordedSteps {
+		state.History = state.History[1:]
+	}
+	state.History = append(state.History, BoardSnapshot{Step: state.Step, Board: state.Board.Clone()})
+}
+
+// PackHistory packs the newest recorded boards that fit in MaxCarriedHistoryBytes, oldest first
+func PackHistory(history []BoardSnapshot) []PackedSnapshot {
+	var packed []PackedSnapshot
+	size := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		board := history[i].Board.Pack()
+		if size += len(board.Bits); size > MaxCarriedHistoryBytes {
+			break
+		}
+		packed = append(packed, PackedSnapshot{Step: history[i].Step, Board: board})
+	}
+	slices.Reverse(packed)
+	return packed
+}
+
 // Helper to print the board to the terminal (Only for debugging at LOW board sizes)
 func PrintBoard(board [][]bool) {
 	fmt.Print("\033[H\033[2J") // clear terminal
@@ -194,7 +867,7 @@ func PrintBoard(board [][]bool) {
 
 // Helper to add the activity options to the context and execute the activity
 func DoActivityWithOutput[Input any, Output any](ctx workflow.Context, activity func(context.Context, Input) (Output, error), input Input) (Output, error) {
-	activityCtx := workflow.WithActivityOptions(ctx, ao)
+	activityCtx := workflow.WithActivityOptions(ctx, activityOptions(activity))
 	var result Output
 	err := workflow.ExecuteActivity(activityCtx, activity, input).Get(activityCtx, &result)
 	if err != nil {
@@ -204,7 +877,7 @@ func DoActivityWithOutput[Input any, Output any](ctx workflow.Context, activity
 }
 
 func DoActivity[Input any](ctx workflow.Context, activity func(context.Context, Input) error, input Input) error {
-	activityCtx := workflow.WithActivityOptions(ctx, ao)
+	activityCtx := workflow.WithActivityOptions(ctx, activityOptions(activity))
 	err := workflow.ExecuteActivity(activityCtx, activity, input).Get(activityCtx, nil)
 	if err != nil {
 		return err
@@ -216,34 +889,44 @@ func DoActivity[Input any](ctx workflow.Context, activity func(context.Context,
 // Any live cell with two or three live neighbours lives on to the next generation.
 // Any live cell with more than three live neighbours dies, as if by overpopulation.
 // Any dead cell with exactly three live neighbours becomes a live cell, as if by reproduction.
-func NextGeneration(board Board) Board {
-	rows := len(board)
-	cols := len(board[0])
-	next := make(Board, rows)
-	for i := range next {
-		next[i] = make([]bool, cols)
+func NextGeneration(board Board, rule Rule) Board {
+	next := make(Board, len(board))
+	nextGenerationRows(board, next, rule, 0, len(board))
+	return next
+}
+
+// nextGenerationRows fills rows [start, end) of next from board
+func nextGenerationRows(board Board, next Board, rule Rule, start, end int) {
+	for i := start; i < end; i++ {
+		next[i] = make([]bool, len(board[i]))
 		for j := range next[i] {
-			aliveNeighbors := countAliveNeighbors(board, i, j)
-			if board[i][j] {
-				next[i][j] = aliveNeighbors == 2 || aliveNeighbors == 3
-			} else {
-				next[i][j] = aliveNeighbors == 3
+			if rule.isWall(i, j) {
+				continue
 			}
+			next[i][j] = rule.next(board[i][j], countAliveNeighbors(board, rule, i, j))
 		}
 	}
-	return next
 }
 
-func countAliveNeighbors(board Board, i, j int) int {
+func countAliveNeighbors(board Board, rule Rule, i, j int) int {
 	count := 0
 	for x := -1; x <= 1; x++ {
 		for y := -1; y <= 1; y++ {
 			if x == 0 && y == 0 {
 				continue
 			}
-			nx := i + x
-			ny := j + y
-			if nx < 0 || nx >= len(board) || ny < 0 || ny >= len(board[0]) {
+			// Von Neumann neighborhoods skip the diagonals
+			if rule.Neighborhood == VonNeumann && x != 0 && y != 0 {
+				continue
+			}
+			nx, ny, ok := rule.neighborCell(len(board), len(board[0]), i+x, j+y)
+			if !ok {
+				continue
+			}
+			if rule.isWall(nx, ny) {
+				if rule.WallsAlive {
+					count++
+				}
 				continue
 			}
 			if board[nx][ny] {
@@ -254,40 +937,238 @@ func countAliveNeighbors(board Board, i, j int) int {
 	return count
 }
 
+// StateChangeFromNothing returns a full frame, every live cell as if flipped from an empty board
 func StateChangeFromNothing(from GolState) StateChange {
-	board := make(Board, DefaultBoardLength)
-	for i := range board {
-		board[i] = make([]bool, DefaultBoardWidth)
+	live := from.Board.LiveCells()
+	stateChange := StateChange{
+		Id:                from.Id,
+		Paused:            from.Paused,
+		Step:              from.Step,
+		TickTime:          from.TickTime,
+		Flipped:           live,
+		Teams:             from.cellTeams(live),
+		TeamPopulations:   TeamPopulations(from.Board, from.Teams),
+		Full:              true,
+		Walls:             from.Walls.LiveCells(),
+		Rows:              len(from.Board),
+		Cols:              len(from.Board[0]),
+		Terminated:        from.Terminated,
+		Period:            from.Period,
+		Population:        from.Board.Population(),
+		EffectiveTickTime: from.EffectiveTickTime(),
 	}
-	return StateChange{
-		Id:       from.Id,
-		Paused:   from.Paused,
-		Step:     Steps,
-		TickTime: from.TickTime,
-		Flipped:  DiffFlipped(board, GolBoard),
+	if from.Checksums {
+		stateChange.Checksum = Checksum(from.Board)
 	}
+	return stateChange
 }
 
-func Tick(ctx workflow.Context, golState GolState) workflow.Future {
-	activityCtx := workflow.WithActivityOptions(ctx, ao)
-	return workflow.ExecuteActivity(activityCtx, AmInstance.Tick, golState.TickTime)
+// AdvanceFrame advances the board by the generations of a frame and returns the frame
+// A batch of TicksPerFrame generations stops early where the loop has to act on the game (it
+// paused, settled, reached its max steps or has to continue as new), and is published as the
+// net diff of its generations with their births and deaths added up
+func (s *GolState) AdvanceFrame() StateChange {
+	var stateChange StateChange
+	births, deaths := 0, 0
+	for tick := 0; tick < s.TicksPerFrame; tick++ {
+		// The workflow is the only owner of the step counter
+		s.Step++
+
+		// Pause with the last of the steps so its frame already reflects the pause
+		if s.RemainingSteps > 0 {
+			s.RemainingSteps--
+			if s.RemainingSteps == 0 {
+				s.Paused = true
+			}
+		}
+
+		change := s.AdvanceGeneration()
+		births, deaths = births+change.Births, deaths+change.Deaths
+		if s.TicksPerFrame == 1 {
+			stateChange = change
+		} else {
+			// Merging also copies the flips out of the buffer the next generation reuses
+			stateChange = MergeStateChanges(stateChange, change)
+		}
+
+		if s.Recording {
+			RecordSnapshot(s)
+		}
+
+		if s.Paused || s.Terminated || s.RestartDue() || s.Step >= s.MaxSteps || s.Step%s.StoreInterval == 0 {
+			break
+		}
+	}
+	stateChange.Births, stateChange.Deaths = births, deaths
+	return stateChange
 }
 
-func NextGenerationAndSendState(ctx workflow.Context, golState GolState) error {
-	nextGeneration := NextGeneration(GolBoard)
-	flipped := DiffFlipped(GolBoard, nextGeneration)
-	GolBoard = nextGeneration
-	return DoActivity(ctx, AmInstance.SendState, StateChange{
-		Id:       golState.Id,
-		Paused:   golState.Paused,
-		Step:     Steps,
-		TickTime: golState.TickTime,
-		Flipped:  flipped,
-	})
+// AdvanceGeneration advances the board by a generation and returns its frame without publishing it
+func (s *GolState) AdvanceGeneration() StateChange {
+	var stateChange StateChange
+	if s.Generations > 2 {
+		flipped, cells := NextGenerationDecay(s.Board, s.Ages, s.Rule(), s.Generations)
+		s.trackFlips(flipped)
+		stateChange = s.StateChange(flipped)
+		stateChange.Cells = cells
+	} else if s.Range > 0 {
+		next := NextGenerationKernel(s.Board, s.Rule(), s.Range, s.RangeBirth, s.RangeSurvival)
+		flipped := DiffFlippedInto(s.Board, next, s.flippedBuf)
+		s.flippedBuf = flipped
+		s.Board = next
+		s.Changed = nil // Only NextGenerationActive uses it
+		s.trackFlips(flipped)
+		stateChange = s.StateChange(flipped)
+	} else {
+		// The flipped cells are already encoded into the activity input by the time the buffer is reused
+		flipped := NextGenerationActive(s.Board, s.Rule(), s.Changed, s.FullScan, &s.scratch, s.flippedBuf)
+		s.flippedBuf = flipped
+		s.Changed = flipped
+		s.FullScan = false
+		if s.Teams != nil {
+			AssignTeams(s.Board, s.Teams, s.Rule(), flipped)
+		}
+		s.trackFlips(flipped)
+		stateChange = s.StateChange(flipped)
+	}
+
+	s.ApplyAdaptiveTick(stateChange.Population)
+	stateChange.EffectiveTickTime = s.EffectiveTickTime()
+
+	period := s.DetectCycle(s.cycleHash())
+
+	// An empty board is waiting to be reseeded rather than settled
+	s.countDeadSteps(stateChange.Population)
+	if s.AutoRestart && stateChange.Population == 0 {
+		period = 0
+	}
+
+	// Periodically send the full board so clients that dropped a frame converge again
+	// The generation's births and deaths still go out with it for the stats streams
+	if s.Step%s.KeyframeInterval == 0 && !s.Headless {
+		births, deaths := stateChange.Births, stateChange.Deaths
+		stateChange = StateChangeFromNothing(*s)
+		stateChange.Births, stateChange.Deaths = births, deaths
+	}
+
+	// A repeated board means the game won't change anymore, the caller stops the loop
+	if period > 0 {
+		s.Terminated = true
+		s.Period = period
+		stateChange.Terminated = true
+		stateChange.Period = period
+	}
+	return stateChange
+}
+
+// PublishGeneration publishes the frame of one or more generations
+func PublishGeneration(ctx workflow.Context, golState *GolState, stateChange StateChange) error {
+	if !stateChange.Full {
+		golState.lastDiff = len(stateChange.Flipped)
+	}
+	result, err := PublishState(ctx, golState, stateChange)
+	if err != nil {
+		return err
+	}
+	golState.ApplyBackpressure(result)
+	return nil
+}
+
+// SendState sends the flipped cells along with the current game state to the state stream
+func SendState(ctx workflow.Context, golState *GolState, flipped [][2]int) error {
+	_, err := PublishState(ctx, golState, golState.StateChange(flipped))
+	return err
 }
 
+// PublishState sends the state change to the state stream as the game's next frame (see BoardSeq)
+// Headless games skip the activity altogether, their board is only available through the queries
+// A change that doesn't leave clients with the game's board has to bring its own checksum
+func PublishState(ctx workflow.Context, golState *GolState, stateChange StateChange) (SendStateResult, error) {
+	if golState.Headless {
+		return SendStateResult{}, nil
+	}
+	if golState.Checksums && stateChange.Checksum == "" {
+		stateChange.Checksum = Checksum(golState.Board)
+	}
+	golState.Seq++
+	stateChange.Seq = golState.Seq
+	return DoActivityWithOutput(ctx, AmInstance.SendState, stateChange)
+}
+
+// BoardSeq returns the Seq of the last frame the game's board includes
+// Signal flips still pending (see compaction.go) go out with the next frame, so the board is already that one
+// A client given the board skips the frames up to it, Step can't tell since signal frames don't advance it
+func (s GolState) BoardSeq() int {
+	if s.pendingFull || s.pendingStatus || len(NetFlips(s.pendingFlips)) > 0 {
+		return s.Seq + 1
+	}
+	return s.Seq
+}
+
+// Preview computes the next generation without touching the game's board or step
+func (s GolState) Preview() Preview {
+	preview := Preview{Step: s.Step + 1}
+	if s.Generations > 2 {
+		ages := make([][]uint8, len(s.Ages))
+		for i := range s.Ages {
+			ages[i] = append([]uint8(nil), s.Ages[i]...)
+		}
+		preview.Flipped, preview.Cells = NextGenerationDecay(s.Board.Clone(), ages, s.Rule(), s.Generations)
+		return preview
+	}
+	if s.Range > 0 {
+		preview.Flipped = DiffFlipped(s.Board, NextGenerationKernel(s.Board, s.Rule(), s.Range, s.RangeBirth, s.RangeSurvival))
+		return preview
+	}
+	preview.Flipped = DiffFlipped(s.Board, NextGeneration(s.Board, s.Rule()))
+	return preview
+}
+
+// StateChange builds the state change for the flipped cells from the current game state
+func (s GolState) StateChange(flipped [][2]int) StateChange {
+	// The board already has the flips applied, so a live flipped cell was born
+	births := 0
+	var born, died [][2]int
+	for _, cell := range flipped {
+		alive := s.Board[cell[0]][cell[1]]
+		if alive {
+			births++
+		}
+		if s.SplitFlips {
+			if alive {
+				born = append(born, cell)
+			} else {
+				died = append(died, cell)
+			}
+		}
+	}
+
+	return StateChange{
+		Born:              born,
+		Died:              died,
+		Births:            births,
+		Deaths:            len(flipped) - births,
+		Id:                s.Id,
+		Paused:            s.Paused,
+		Step:              s.Step,
+		TickTime:          s.TickTime,
+		Flipped:           flipped,
+		Teams:             s.cellTeams(flipped),
+		TeamPopulations:   TeamPopulations(s.Board, s.Teams),
+		Population:        s.Population(),
+		EffectiveTickTime: s.EffectiveTickTime(),
+	}
+}
+
+// DiffFlipped returns the cells that differ between the boards in row-major order
 func DiffFlipped(prev, curr Board) [][2]int {
-	var flipped [][2]int
+	return DiffFlippedInto(prev, curr, nil)
+}
+
+// DiffFlippedInto is DiffFlipped appending to buf[:0], so a buffer reused across
+// generations stops allocating once it has grown to the board's churn
+func DiffFlippedInto(prev, curr Board, buf [][2]int) [][2]int {
+	flipped := buf[:0]
 	for i := range curr {
 		for j := range curr[i] {
 			if prev[i][j] != curr[i][j] {
@@ -0,0 +1,1083 @@
+package gol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+/* --------------------------------- Helpers -------------------------------- */
+
+// testGame runs GameOfLife in temporal's test environment and keeps the frames it publishes
+type testGame struct {
+	env     *testsuite.TestWorkflowEnvironment
+	frames  []StateChange
+	dropped int // Subscribers SendState reports as having dropped each frame
+}
+
+func newTestGame(t testing.TB) *testGame {
+	t.Helper()
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(GameOfLife)
+	env.RegisterActivity(AmInstance)
+
+	game := &testGame{env: env}
+	env.OnActivity(AmInstance.SendState, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, state StateChange) (SendStateResult, error) {
+			game.frames = append(game.frames, state)
+			return SendStateResult{Dropped: game.dropped}, nil
+		})
+	return game
+}
+
+// run runs the game to its end and fails the test when the workflow fails
+func (g *testGame) run(t testing.TB, input GameOfLifeInput) {
+	t.Helper()
+	g.env.ExecuteWorkflow(GameOfLife, input)
+	if !g.env.IsWorkflowCompleted() {
+		t.Fatal("workflow didn't complete")
+	}
+	if err := g.env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+}
+
+// runToContinueAsNew runs the game until it continues as new and returns the input it continued with
+func (g *testGame) runToContinueAsNew(t *testing.T, input GameOfLifeInput) GameOfLifeInput {
+	t.Helper()
+	g.env.ExecuteWorkflow(GameOfLife, input)
+	var continued *workflow.ContinueAsNewError
+	if !errors.As(g.env.GetWorkflowError(), &continued) {
+		t.Fatalf("game didn't continue as new, it ended with %v", g.env.GetWorkflowError())
+	}
+	var next GameOfLifeInput
+	if err := converter.GetDefaultDataConverter().FromPayloads(continued.Input, &next); err != nil {
+		t.Fatalf("decoding the continue-as-new input: %v", err)
+	}
+	return next
+}
+
+// query runs a query against the game and decodes its result
+func (g *testGame) query(t testing.TB, queryType string, result any, args ...any) {
+	t.Helper()
+	value, err := g.env.QueryWorkflow(queryType, args...)
+	if err != nil {
+		t.Fatalf("query %s: %v", queryType, err)
+	}
+	if err := value.Get(result); err != nil {
+		t.Fatalf("decoding query %s: %v", queryType, err)
+	}
+}
+
+// signalAt sends the signal once the game has run for the given time
+func (g *testGame) signalAt(after time.Duration, name string, payload any) {
+	g.env.RegisterDelayedCallback(func() {
+		g.env.SignalWorkflow(name, payload)
+	}, after)
+}
+
+// replay applies the game's frames to a copy of its starting board the way a client does
+func (g *testGame) replay(t *testing.T, start Board) Board {
+	t.Helper()
+	board := start.Clone()
+	for _, frame := range g.frames {
+		board = applyFrame(t, board, frame)
+	}
+	return board
+}
+
+// applyFrame applies a frame to the board like a client, a full frame replaces it
+func applyFrame(t *testing.T, board Board, frame StateChange) Board {
+	t.Helper()
+	if frame.Full {
+		board = emptyBoard(frame.Rows, frame.Cols)
+	}
+	for _, cell := range frame.Flipped {
+		if cell[0] < 0 || cell[0] >= len(board) || cell[1] < 0 || cell[1] >= len(board[cell[0]]) {
+			t.Fatalf("step %d: flipped cell %v is off the board", frame.Step, cell)
+		}
+	}
+	board.Toggle(frame.Flipped)
+	return board
+}
+
+// parseBoard builds a board from rows of '#' (alive) and '.' (dead)
+func parseBoard(rows ...string) Board {
+	board := make(Board, len(rows))
+	for i, row := range rows {
+		board[i] = make([]bool, len(row))
+		for j, cell := range row {
+			board[i][j] = cell == '#'
+		}
+	}
+	return board
+}
+
+// String draws the board like parseBoard reads it, for test failures
+func (b Board) String() string {
+	var sb strings.Builder
+	for _, row := range b {
+		for _, cell := range row {
+			if cell {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func emptyBoard(rows, cols int) Board {
+	board := make(Board, rows)
+	for i := range board {
+		board[i] = make([]bool, cols)
+	}
+	return board
+}
+
+// randomBoard returns a board with about density of its cells alive
+func randomBoard(random *rand.Rand, rows, cols int, density float64) Board {
+	board := emptyBoard(rows, cols)
+	for i := range board {
+		for j := range board[i] {
+			board[i][j] = random.Float64() < density
+		}
+	}
+	return board
+}
+
+// equalBoards reports whether the boards have the same size and cells
+func equalBoards(a, b Board) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if a[i][j] != b[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// evolve returns the board after the given number of generations of the classic rule
+func evolve(board Board, generations int) Board {
+	for range generations {
+		board = NextGeneration(board, Rule{})
+	}
+	return board
+}
+
+/* ---------------------------------- Tests --------------------------------- */
+
+func TestRecordedStepsMatchLiveEvolution(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(1)), 24, 24, 0.35)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Recording: true, MaxSteps: 30, TickTime: time.Millisecond})
+
+	for _, index := range []int{0, 1, 7, 30} {
+		var snapshot BoardSnapshot
+		game.query(t, "history", &snapshot, index)
+		if snapshot.Step != index {
+			t.Fatalf("history[%d] is step %d", index, snapshot.Step)
+		}
+		if want := evolve(seed, index); !equalBoards(snapshot.Board, want) {
+			t.Errorf("history[%d] =\n%vwant\n%v", index, snapshot.Board, want)
+		}
+	}
+
+	if _, err := game.env.QueryWorkflow("history", 31); err == nil {
+		t.Error("history past the recorded steps didn't fail")
+	}
+}
+
+func TestRecordingSurvivesContinueAsNew(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(517)), 24, 24, 0.35)
+	game := newTestGame(t)
+	next := game.runToContinueAsNew(t, GameOfLifeInput{Board: seed.Pack(), Recording: true, MaxSteps: 60, StoreInterval: 20, TickTime: time.Millisecond})
+	if len(next.History) != 21 || next.History[0].Step != 0 || next.History[20].Step != 20 {
+		t.Fatalf("continued with %d recorded steps, want steps 0 to 20", len(next.History))
+	}
+
+	// The next run keeps recording onto the carried steps, so the query still serves the first run's
+	next.StoreInterval = 100
+	game = newTestGame(t)
+	game.run(t, next)
+	for _, index := range []int{0, 13, 20, 21, 60} {
+		var snapshot BoardSnapshot
+		game.query(t, "history", &snapshot, index)
+		if snapshot.Step != index {
+			t.Fatalf("history[%d] is step %d", index, snapshot.Step)
+		}
+		if want := evolve(seed, index); !equalBoards(snapshot.Board, want) {
+			t.Errorf("history[%d] =\n%vwant\n%v", index, snapshot.Board, want)
+		}
+	}
+}
+
+func TestCarriedHistoryIsBounded(t *testing.T) {
+	// A 512x512 board packs to 32KB, so only the newest 32 of a full buffer fit the budget
+	board := emptyBoard(512, 512)
+	history := make([]BoardSnapshot, MaxRecordedSteps)
+	for i := range history {
+		history[i] = BoardSnapshot{Step: i, Board: board}
+	}
+	packed := PackHistory(history)
+	if len(packed) != 32 || packed[0].Step != MaxRecordedSteps-32 || packed[31].Step != MaxRecordedSteps-1 {
+		t.Errorf("carried %d steps, want the newest 32", len(packed))
+	}
+}
+
+func TestStepCountsTicks(t *testing.T) {
+	const ticks = 12
+	seed := randomBoard(rand.New(rand.NewSource(2)), 24, 24, 0.35)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: ticks, TickTime: time.Millisecond})
+
+	var debug DebugState
+	game.query(t, "debug", &debug)
+	if debug.Step != ticks {
+		t.Fatalf("Step = %d after %d ticks", debug.Step, ticks)
+	}
+
+	// Every tick publishes its own step exactly once, in order
+	step := 0
+	for _, frame := range game.frames {
+		if frame.Step == step {
+			continue
+		}
+		if frame.Step != step+1 {
+			t.Fatalf("frame for step %d follows step %d", frame.Step, step)
+		}
+		step = frame.Step
+	}
+	if step != ticks {
+		t.Fatalf("last frame is step %d, want %d", step, ticks)
+	}
+}
+
+func TestConcurrentGamesKeepTheirOwnBoards(t *testing.T) {
+	const steps = 20
+	for _, seed := range []int64{3, 4} {
+		t.Run(fmt.Sprint("seed ", seed), func(t *testing.T) {
+			t.Parallel()
+			board := randomBoard(rand.New(rand.NewSource(seed)), 24, 24, 0.35)
+			game := newTestGame(t)
+			game.run(t, GameOfLifeInput{Board: board.Pack(), MaxSteps: steps, TickTime: time.Millisecond})
+
+			want := evolve(board, steps)
+			var stateChange StateChange
+			game.query(t, "board", &stateChange)
+			got := emptyBoard(stateChange.Rows, stateChange.Cols)
+			got.Toggle(stateChange.Flipped)
+			if !equalBoards(got, want) {
+				t.Errorf("board =\n%vwant\n%v", got, want)
+			}
+			if replayed := game.replay(t, board); !equalBoards(replayed, want) {
+				t.Errorf("published frames replay to\n%vwant\n%v", replayed, want)
+			}
+		})
+	}
+}
+
+func TestFramesAreNumberedInOrder(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(10)), 16, 16, 0.35)
+	game := newTestGame(t)
+	// A toggle goes out as a frame of its own without advancing the step
+	game.signalAt(5*time.Millisecond+time.Microsecond, BatchToggleSignalName, BatchToggleSignal{Cells: [][2]int{{0, 0}}})
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 10, TickTime: time.Millisecond})
+
+	// Ten generations, the toggle and the game ended message
+	if len(game.frames) != 12 {
+		t.Fatalf("published %d frames, want 12", len(game.frames))
+	}
+	for i, frame := range game.frames {
+		if frame.Seq != i+1 {
+			t.Fatalf("frame %d (step %d) has Seq %d", i+1, frame.Step, frame.Seq)
+		}
+	}
+	var board StateChange
+	game.query(t, "board", &board)
+	if last := game.frames[len(game.frames)-1]; board.Seq != last.Seq {
+		t.Errorf("board query has Seq %d, want the last frame's %d", board.Seq, last.Seq)
+	}
+}
+
+func TestNeighborhoods(t *testing.T) {
+	full := parseBoard(
+		"###",
+		"###",
+		"###",
+	)
+	blinker := parseBoard(
+		".....",
+		"..#..",
+		"..#..",
+		"..#..",
+		".....",
+	)
+	tests := []struct {
+		neighborhood Neighborhood
+		neighbors    int   // Of the center of the full board
+		next         Board // The blinker's next generation
+	}{
+		{Moore, 8, parseBoard(
+			".....",
+			".....",
+			".###.",
+			".....",
+			".....",
+		)},
+		// Each end only has the center as a neighbor, the center has both ends
+		{VonNeumann, 4, parseBoard(
+			".....",
+			".....",
+			"..#..",
+			".....",
+			".....",
+		)},
+	}
+	for _, test := range tests {
+		rule := Rule{Neighborhood: test.neighborhood}
+		if got := countAliveNeighbors(full, rule, 1, 1); got != test.neighbors {
+			t.Errorf("neighborhood %d counts %d neighbors, want %d", test.neighborhood, got, test.neighbors)
+		}
+		if got := NextGeneration(blinker, rule); !equalBoards(got, test.next) {
+			t.Errorf("neighborhood %d turns the blinker into\n%vwant\n%v", test.neighborhood, got, test.next)
+		}
+	}
+}
+
+func TestUnknownNeighborhoodFailsTheGame(t *testing.T) {
+	game := newTestGame(t)
+	game.env.ExecuteWorkflow(GameOfLife, GameOfLifeInput{Board: emptyBoard(4, 4).Pack(), Neighborhood: 2})
+	if err := game.env.GetWorkflowError(); err == nil {
+		t.Fatal("game with an unknown neighborhood didn't fail")
+	}
+}
+
+func TestContinueAsNewAtTheStoreInterval(t *testing.T) {
+	for _, interval := range []int{5, 7} {
+		t.Run(fmt.Sprint("every ", interval), func(t *testing.T) {
+			seed := randomBoard(rand.New(rand.NewSource(11)), 16, 16, 0.35)
+			game := newTestGame(t)
+			next := game.runToContinueAsNew(t, GameOfLifeInput{Board: seed.Pack(), StoreInterval: interval, TickTime: time.Millisecond})
+
+			if next.Step != interval {
+				t.Errorf("continued as new at step %d, want %d", next.Step, interval)
+			}
+			if next.StoreInterval != interval {
+				t.Errorf("next run stores every %d steps, want %d", next.StoreInterval, interval)
+			}
+			if want := evolve(seed, interval); !equalBoards(next.Board.Unpack(), want) {
+				t.Errorf("next run starts from\n%vwant\n%v", next.Board.Unpack(), want)
+			}
+		})
+	}
+}
+
+func TestInvalidStoreIntervalFailsTheGame(t *testing.T) {
+	game := newTestGame(t)
+	game.env.ExecuteWorkflow(GameOfLife, GameOfLifeInput{Board: emptyBoard(4, 4).Pack(), StoreInterval: -1})
+	if err := game.env.GetWorkflowError(); err == nil {
+		t.Fatal("game with a negative store interval didn't fail")
+	}
+}
+
+func TestPauseSurvivesContinueAsNew(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(12)), 16, 16, 0.35)
+
+	// The game pauses itself with the step that hits the store interval
+	game := newTestGame(t)
+	next := game.runToContinueAsNew(t, GameOfLifeInput{Board: seed.Pack(), StoreInterval: 5, RunSteps: 5, TickTime: time.Millisecond})
+	if !next.Paused {
+		t.Fatal("next run isn't paused")
+	}
+
+	// Long after the next run started it is still paused at the same step, then it is ended
+	game = newTestGame(t)
+	var debug DebugState
+	game.env.RegisterDelayedCallback(func() {
+		game.query(t, "debug", &debug)
+		game.env.SignalWorkflow(SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: next.Step})
+	}, time.Minute)
+	game.run(t, next)
+
+	if !debug.Paused || debug.Step != 5 {
+		t.Errorf("a minute into the next run it is at step %d with paused %v, want paused at step 5", debug.Step, debug.Paused)
+	}
+}
+
+func TestKeyframesAtTheInterval(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(15)), 20, 20, 0.35)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), KeyframeInterval: 4, MaxSteps: 13, TickTime: time.Millisecond})
+
+	keyframes := 0
+	board := seed.Clone()
+	for _, frame := range game.frames {
+		if frame.Ended != "" {
+			continue
+		}
+		if want := frame.Step%4 == 0; frame.Full != want {
+			t.Errorf("step %d has Full %v, want %v", frame.Step, frame.Full, want)
+		}
+		if frame.Full {
+			keyframes++
+			// A keyframe is the whole board, applying it on an empty board gives the step's board
+			if got, want := applyFrame(t, emptyBoard(20, 20), frame), evolve(seed, frame.Step); !equalBoards(got, want) {
+				t.Errorf("keyframe of step %d =\n%vwant\n%v", frame.Step, got, want)
+			}
+			if frame.Births+frame.Deaths == 0 {
+				t.Errorf("keyframe of step %d lost the generation's births and deaths", frame.Step)
+			}
+		}
+		board = applyFrame(t, board, frame)
+	}
+	if keyframes != 3 {
+		t.Errorf("sent %d keyframes in 13 steps, want 3", keyframes)
+	}
+	if want := evolve(seed, 13); !equalBoards(board, want) {
+		t.Errorf("frames replay to\n%vwant\n%v", board, want)
+	}
+}
+
+func TestResetReseedsInPlace(t *testing.T) {
+	game := newTestGame(t)
+	game.env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: "reset"})
+
+	// A paused game with a dead board, resetting it while it waits fills it
+	var debug DebugState
+	var board StateChange
+	game.signalAt(time.Minute, ResetSignalName, nil)
+	game.env.RegisterDelayedCallback(func() {
+		game.query(t, "debug", &debug)
+		game.query(t, "board", &board)
+		game.env.SignalWorkflow(SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: 3})
+	}, 2*time.Minute)
+	game.run(t, GameOfLifeInput{Board: emptyBoard(20, 20).Pack(), Step: 3, Paused: true, TickTime: 40 * time.Millisecond})
+
+	if len(board.Flipped) == 0 {
+		t.Fatal("board is still empty after the reset")
+	}
+	if debug.Id != "reset" || !debug.Paused || debug.Step != 3 || debug.TickTime != 40*time.Millisecond {
+		t.Errorf("after the reset the game is %+v, want game reset still paused at step 3 with its tick time", debug)
+	}
+
+	// Clients converge on the new board from the full frame the reset sent
+	if len(game.frames) == 0 || !game.frames[0].Full {
+		t.Fatalf("the reset sent %v, want a full frame", game.frames)
+	}
+	for _, frame := range game.frames {
+		if frame.Id != "reset" {
+			t.Errorf("frame %d is for game %q", frame.Seq, frame.Id)
+		}
+	}
+	if replayed := game.replay(t, emptyBoard(20, 20)); !equalBoards(replayed, applyFrame(t, emptyBoard(20, 20), board)) {
+		t.Errorf("frames replay to\n%v, not the reset board", replayed)
+	}
+}
+
+func TestActivityErrorFailsOnlyItsGame(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(16)), 16, 16, 0.35)
+	// Each failing activity's mock, fail returns the injected error
+	tests := []struct {
+		activity any
+		input    GameOfLifeInput
+		failing  func(fail func() error) any
+	}{
+		// Starting on a random board
+		{AmInstance.GetInitialBoard, GameOfLifeInput{Rows: 16, Cols: 16}, func(fail func() error) any {
+			return func(context.Context, GetInitialBoardInput) (Board, error) { return nil, fail() }
+		}},
+		// A signal handler's activity
+		{AmInstance.Splatter, GameOfLifeInput{Board: seed.Pack()}, func(fail func() error) any {
+			return func(context.Context, SplatterInput) ([][2]int, error) { return nil, fail() }
+		}},
+		// Publishing a generation
+		{AmInstance.SendState, GameOfLifeInput{Board: seed.Pack()}, func(fail func() error) any {
+			return func(context.Context, StateChange) (SendStateResult, error) { return SendStateResult{}, fail() }
+		}},
+	}
+	for _, test := range tests {
+		name := activityName(test.activity)
+		t.Run(name, func(t *testing.T) {
+			var suite testsuite.WorkflowTestSuite
+			env := suite.NewTestWorkflowEnvironment()
+			env.RegisterWorkflow(GameOfLife)
+			env.RegisterActivity(AmInstance)
+
+			attempts := 0
+			env.OnActivity(test.activity, mock.Anything, mock.Anything).Return(test.failing(func() error {
+				attempts++
+				return fmt.Errorf("%s is down", name)
+			}))
+			if name != "SendState" {
+				env.OnActivity(AmInstance.SendState, mock.Anything, mock.Anything).Return(SendStateResult{}, nil)
+			}
+			env.RegisterDelayedCallback(func() {
+				env.SignalWorkflow(SplatterSignalName, SplatterSignal{X: 8, Y: 8, Size: 3})
+			}, 5*time.Millisecond)
+
+			input := test.input
+			input.TickTime = 10 * time.Millisecond
+			input.MaxSteps = 1000
+			env.ExecuteWorkflow(GameOfLife, input)
+
+			// Temporal retried the activity per its policy, then only the workflow failed
+			if !env.IsWorkflowCompleted() {
+				t.Fatal("workflow didn't complete")
+			}
+			if err := env.GetWorkflowError(); err == nil || !strings.Contains(err.Error(), name+" is down") {
+				t.Fatalf("workflow ended with %v, want the activity's error", err)
+			}
+			if want := activityOptions(test.activity).RetryPolicy.MaximumAttempts; attempts != int(want) {
+				t.Errorf("activity ran %d times, want %d", attempts, want)
+			}
+		})
+	}
+
+	// The process is still around to run the next game
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 3, TickTime: time.Millisecond})
+}
+
+func TestTransientActivityErrorIsRetried(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(21)), 16, 16, 0.35)
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(GameOfLife)
+	env.RegisterActivity(AmInstance)
+
+	// Every publish fails twice before it goes through, like a flaky connection to redis
+	attempts := map[int]int{}
+	var published []int
+	env.OnActivity(AmInstance.SendState, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, state StateChange) (SendStateResult, error) {
+			if state.Ended != "" {
+				return SendStateResult{}, nil
+			}
+			if attempts[state.Step]++; attempts[state.Step] <= 2 {
+				return SendStateResult{}, errors.New("connection reset")
+			}
+			published = append(published, state.Step)
+			return SendStateResult{}, nil
+		})
+	env.ExecuteWorkflow(GameOfLife, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 5, TickTime: time.Millisecond})
+
+	if !env.IsWorkflowCompleted() {
+		t.Fatal("workflow didn't complete")
+	}
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	if len(published) == 0 {
+		t.Fatal("nothing was published")
+	}
+	for i, step := range published {
+		if attempts[step] != 3 {
+			t.Errorf("step %d was published on attempt %d, want the third", step, attempts[step])
+		}
+		if i > 0 && step < published[i-1] {
+			t.Errorf("step %d was published after step %d", step, published[i-1])
+		}
+	}
+	if published[len(published)-1] != 5 {
+		t.Errorf("last published step %d, want the game to reach 5", published[len(published)-1])
+	}
+}
+
+func TestActivitiesHaveTheirOwnOptions(t *testing.T) {
+	publish, record, splatter := activityOptions(AmInstance.SendState), activityOptions(AmInstance.RecordRun), activityOptions(AmInstance.Splatter)
+	if publish.StartToCloseTimeout >= record.StartToCloseTimeout {
+		t.Errorf("publishing times out after %v, not sooner than recording's %v", publish.StartToCloseTimeout, record.StartToCloseTimeout)
+	}
+	if publish.RetryPolicy.InitialInterval >= DefaultActivityOptions.RetryPolicy.InitialInterval {
+		t.Errorf("publishing retries after %v, not sooner than the default %v", publish.RetryPolicy.InitialInterval, DefaultActivityOptions.RetryPolicy.InitialInterval)
+	}
+	if !reflect.DeepEqual(splatter, DefaultActivityOptions) {
+		t.Errorf("splatter has options %+v, want the defaults", splatter)
+	}
+}
+
+func TestRunStepsPausesAfterThem(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(20)), 16, 16, 0.35)
+
+	// Ten generations from step 3, the last one's frame already shows the pause
+	game := newTestGame(t)
+	var debug DebugState
+	game.env.RegisterDelayedCallback(func() {
+		game.query(t, "debug", &debug)
+		game.env.SignalWorkflow(SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: debug.Step})
+	}, time.Minute)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Step: 3, RunSteps: 10, TickTime: time.Millisecond})
+
+	if !debug.Paused || debug.Step != 13 {
+		t.Errorf("a minute in the game is at step %d with paused %v, want paused at step 13", debug.Step, debug.Paused)
+	}
+	for _, frame := range game.frames {
+		if frame.Ended == "" && frame.Paused != (frame.Step == 13) {
+			t.Errorf("frame of step %d has paused %v", frame.Step, frame.Paused)
+		}
+	}
+
+	// Across a rollover the next run gets the generations that are left
+	game = newTestGame(t)
+	next := game.runToContinueAsNew(t, GameOfLifeInput{Board: seed.Pack(), StoreInterval: 4, RunSteps: 10, TickTime: time.Millisecond})
+	if next.Step != 4 || next.RunSteps != 6 || next.Paused {
+		t.Errorf("continued at step %d with %d run steps and paused %v, want 6 run steps left at step 4", next.Step, next.RunSteps, next.Paused)
+	}
+}
+
+func TestStalledConsumerSlowsTheGame(t *testing.T) {
+	// A glider on a torus never settles
+	seed := emptyBoard(16, 16)
+	glider, _ := Pattern("glider")
+	seed.Stamp(glider, 2, 2)
+	const tickTime = 50 * time.Millisecond
+
+	// A subscriber stalls for 20 seconds, then catches up again
+	game := newTestGame(t)
+	game.dropped = 1
+	var stalled, recovered StateChange
+	var stalledDebug DebugState
+	game.env.RegisterDelayedCallback(func() {
+		game.query(t, "board", &stalled)
+		game.query(t, "debug", &stalledDebug)
+		game.dropped = 0
+	}, 20*time.Second)
+	game.env.RegisterDelayedCallback(func() {
+		game.query(t, "board", &recovered)
+		game.env.SignalWorkflow(SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: recovered.Step})
+	}, 40*time.Second)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Wrap: true, Backpressure: true, TickTime: tickTime, DisableContinueAsNew: true})
+
+	// At the full tick time 20 seconds would be 400 generations
+	if stalledDebug.Step > 40 {
+		t.Errorf("computed %d generations in 20 seconds of a stalled consumer", stalledDebug.Step)
+	}
+	if stalled.EffectiveTickTime != MaxBackpressureTickTime {
+		t.Errorf("stalled consumer slowed the tick to %v, want %v", stalled.EffectiveTickTime, MaxBackpressureTickTime)
+	}
+	if recovered.EffectiveTickTime != tickTime {
+		t.Errorf("caught up consumer left the tick at %v, want %v", recovered.EffectiveTickTime, tickTime)
+	}
+	if recovered.Step-stalledDebug.Step < 200 {
+		t.Errorf("computed %d generations in 20 seconds once caught up", recovered.Step-stalledDebug.Step)
+	}
+}
+
+func TestPreviewDoesNotAdvanceTheGame(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(56)), 24, 24, 0.35)
+	for name, input := range map[string]GameOfLifeInput{
+		"conway":      {Board: seed.Pack()},
+		"generations": {Board: seed.Pack(), Rule: "B2/S", Generations: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			game := newTestGame(t)
+			var first, second Preview
+			var before, after BoardHashResult
+			game.env.RegisterDelayedCallback(func() {
+				game.query(t, "boardHash", &before)
+				game.query(t, "preview", &first)
+				game.query(t, "preview", &second)
+				game.query(t, "boardHash", &after)
+			}, 35*time.Millisecond)
+			input.MaxSteps = 10
+			input.TickTime = 10 * time.Millisecond
+			game.run(t, input)
+
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("previews differ:\n%+v\n%+v", first, second)
+			}
+			if before != after {
+				t.Errorf("previewing changed the board from %+v to %+v", before, after)
+			}
+			if first.Step != before.Step+1 {
+				t.Errorf("preview is of step %d on step %d", first.Step, before.Step)
+			}
+
+			// The previewed generation is the one the game went on to publish
+			for _, frame := range game.frames {
+				if frame.Step == first.Step && frame.Ended == "" {
+					if fmt.Sprint(frame.Flipped, frame.Cells) != fmt.Sprint(first.Flipped, first.Cells) {
+						t.Errorf("step %d flipped %v %v, the preview %v %v", frame.Step, frame.Flipped, frame.Cells, first.Flipped, first.Cells)
+					}
+					return
+				}
+			}
+			t.Errorf("the game never published the previewed step %d", first.Step)
+		})
+	}
+}
+
+func TestBirthsAndDeathsSplitTheFlippedCells(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(63)), 24, 24, 0.35)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 30, KeyframeInterval: 10, TickTime: time.Millisecond})
+
+	board := seed.Clone()
+	diffs := 0
+	for _, frame := range game.frames {
+		if !frame.Full && frame.Ended == "" {
+			diffs++
+			if frame.Births+frame.Deaths != len(frame.Flipped) {
+				t.Errorf("step %d has %d births and %d deaths but flipped %d cells", frame.Step, frame.Births, frame.Deaths, len(frame.Flipped))
+			}
+			born := 0
+			for _, cell := range frame.Flipped {
+				if !board[cell[0]][cell[1]] {
+					born++
+				}
+			}
+			if frame.Births != born {
+				t.Errorf("step %d has %d births, %d flipped cells came alive", frame.Step, frame.Births, born)
+			}
+		}
+		board = applyFrame(t, board, frame)
+	}
+	if diffs == 0 {
+		t.Fatal("the game published no diff frames")
+	}
+}
+
+func TestStartsFromTheGivenBoard(t *testing.T) {
+	seed := parseBoard(
+		"........",
+		"..#.....",
+		"...#....",
+		".###....",
+		"........",
+		"......##",
+	)
+	game := newTestGame(t)
+	game.env.OnActivity(AmInstance.GetInitialBoard, mock.Anything, mock.Anything).Return(
+		func(context.Context, GetInitialBoardInput) (Board, error) {
+			t.Error("a random board was fetched")
+			return nil, errors.New("no random boards")
+		})
+	var first StateChange
+	game.env.RegisterDelayedCallback(func() { game.query(t, "board", &first) }, 10*time.Millisecond)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 2, TickTime: 50 * time.Millisecond})
+
+	// Clients start from the board query before the first generation
+	if !first.Full || first.Step != 0 || first.Rows != 6 || first.Cols != 8 {
+		t.Fatalf("first board is step %d %dx%d with full %v, want the full seed", first.Step, first.Rows, first.Cols, first.Full)
+	}
+	if board := applyFrame(t, nil, first); !equalBoards(board, seed) {
+		t.Errorf("first board is\n%vwant the seed\n%v", board, seed)
+	}
+	if board := game.replay(t, seed); !equalBoards(board, evolve(seed, 2)) {
+		t.Errorf("two generations from the seed are\n%vwant\n%v", board, evolve(seed, 2))
+	}
+}
+
+func TestHeadlessGamePublishesNothing(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(74)), 32, 32, 0.3)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Headless: true, MaxSteps: 20, TickTime: time.Millisecond})
+
+	if len(game.frames) != 0 {
+		t.Errorf("headless game published %d frames", len(game.frames))
+	}
+	// The board query still answers for spot checks
+	var board StateChange
+	game.query(t, "board", &board)
+	if want := evolve(seed, board.Step); board.Step == 0 || fmt.Sprint(applyFrame(t, nil, board)) != fmt.Sprint(want) {
+		t.Errorf("board at step %d doesn't match the evolved seed", board.Step)
+	}
+}
+
+// Compare the time per game, the difference is what streaming the frames costs
+func BenchmarkHeadlessSteps(b *testing.B) {
+	seed := randomBoard(rand.New(rand.NewSource(74)), 128, 128, 0.3).Pack()
+	for _, headless := range []bool{false, true} {
+		name := "streaming"
+		if headless {
+			name = "headless"
+		}
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				game := newTestGame(b)
+				game.run(b, GameOfLifeInput{Board: seed, Headless: headless, MaxSteps: 100, StoreInterval: 200, TickTime: time.Millisecond})
+			}
+			b.ReportMetric(float64(100*b.N)/b.Elapsed().Seconds(), "steps/s")
+		})
+	}
+}
+
+func TestGamesStepAtTheirOwnRate(t *testing.T) {
+	glider, _ := Pattern("glider")
+	seed := emptyBoard(32, 32)
+	seed.Stamp(glider, 2, 2)
+
+	// stepsIn returns the step the game is at after running for the given time
+	stepsIn := func(tickTime, after time.Duration) int {
+		game := newTestGame(t)
+		var debug DebugState
+		game.env.RegisterDelayedCallback(func() {
+			game.query(t, "debug", &debug)
+			game.env.SignalWorkflow(SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: debug.Step})
+		}, after)
+		game.run(t, GameOfLifeInput{Board: seed.Pack(), Wrap: true, MaxSteps: 1000, StoreInterval: 1000, TickTime: tickTime})
+		return debug.Step
+	}
+
+	// The tick is a workflow timer, nothing of one game's wait holds up the other
+	fast, slow := stepsIn(10*time.Millisecond, time.Second+5*time.Millisecond), stepsIn(50*time.Millisecond, time.Second+5*time.Millisecond)
+	if fast < 95 || fast > 100 || slow < 19 || slow > 20 {
+		t.Errorf("a second in, the 10ms game is at step %d and the 50ms game at %d, want about 100 and 20", fast, slow)
+	}
+	if _, ok := reflect.TypeOf(AmInstance).MethodByName("Tick"); ok {
+		t.Error("the blocking Tick activity is back")
+	}
+}
+
+func TestEndedIsTheLastFrame(t *testing.T) {
+	glider, _ := Pattern("glider")
+	running := emptyBoard(16, 16)
+	running.Stamp(glider, 2, 2)
+	tests := []struct {
+		reason string
+		board  Board
+	}{
+		{EndedMaxSteps, running},
+		{EndedTerminated, parseBoard("....", ".##.", ".##.", "....")},
+		{EndedExtinct, parseBoard("...", ".#.", "...")},
+	}
+	for _, test := range tests {
+		t.Run(test.reason, func(t *testing.T) {
+			game := newTestGame(t)
+			game.run(t, GameOfLifeInput{Board: test.board.Pack(), Wrap: true, MaxSteps: 10, TickTime: time.Millisecond})
+
+			for i, frame := range game.frames {
+				last := i == len(game.frames)-1
+				if last && frame.Ended != test.reason {
+					t.Errorf("last frame ended %q, want %q", frame.Ended, test.reason)
+				}
+				if !last && frame.Ended != "" {
+					t.Errorf("frame %d of %d already ended %q", i+1, len(game.frames), frame.Ended)
+				}
+			}
+		})
+	}
+}
+
+func TestBornAndDiedPartitionTheFlippedCells(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(97)), 24, 24, 0.35)
+	for _, split := range []bool{false, true} {
+		game := newTestGame(t)
+		game.run(t, GameOfLifeInput{Board: seed.Pack(), SplitFlips: split, MaxSteps: 20, TickTime: time.Millisecond})
+
+		board := seed.Clone()
+		for _, frame := range game.frames {
+			if frame.Full || frame.Ended != "" {
+				board = applyFrame(t, board, frame)
+				continue
+			}
+			// Without the flag the wire format stays as it was
+			if !split {
+				if frame.Born != nil || frame.Died != nil {
+					t.Fatalf("step %d split its flips without SplitFlips", frame.Step)
+				}
+				board = applyFrame(t, board, frame)
+				continue
+			}
+
+			var born, died [][2]int
+			for _, cell := range frame.Flipped {
+				if board[cell[0]][cell[1]] {
+					died = append(died, cell)
+				} else {
+					born = append(born, cell)
+				}
+			}
+			if fmt.Sprint(frame.Born) != fmt.Sprint(born) || fmt.Sprint(frame.Died) != fmt.Sprint(died) {
+				t.Errorf("step %d has born %v and died %v, want %v and %v", frame.Step, frame.Born, frame.Died, born, died)
+			}
+			board = applyFrame(t, board, frame)
+		}
+	}
+}
+
+func TestDebugQuery(t *testing.T) {
+	seed := emptyBoard(12, 16)
+	glider, _ := Pattern("glider")
+	seed.Stamp(glider, 1, 1)
+
+	// While paused the game sits on its seed
+	game := newTestGame(t)
+	var paused DebugState
+	game.env.RegisterDelayedCallback(func() {
+		game.query(t, "debug", &paused)
+		game.env.SignalWorkflow(SetPausedSignalName, SetPausedSignal{Paused: false})
+	}, 20*time.Millisecond)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Paused: true, Step: 1, MaxSteps: 4, TickTime: 7 * time.Millisecond})
+	want := DebugState{Step: 1, MaxSteps: 4, Paused: true, TickTime: 7 * time.Millisecond, EffectiveTickTime: 7 * time.Millisecond, Rows: 12, Cols: 16, Population: 5}
+	want.Id, want.Changed, want.FullScan, want.RemainingSteps = paused.Id, paused.Changed, paused.FullScan, paused.RemainingSteps
+	if paused != want {
+		t.Errorf("paused game's debug state is\n%+v\nwant\n%+v", paused, want)
+	}
+
+	// Once it ran to the end, the last diff is the glider's move from step 3 to 4
+	var ended DebugState
+	game.query(t, "debug", &ended)
+	want.Step, want.Paused = 4, false
+	want.LastDiff = len(DiffFlipped(evolve(seed, 2), evolve(seed, 3)))
+	want.Changed, want.FullScan, want.RemainingSteps = ended.Changed, ended.FullScan, ended.RemainingSteps
+	if ended != want {
+		t.Errorf("ended game's debug state is\n%+v\nwant\n%+v", ended, want)
+	}
+	if population := game.replay(t, seed).Population(); ended.Population != population {
+		t.Errorf("debug reports a population of %d, the frames replay to %d", ended.Population, population)
+	}
+}
+
+func TestTicksPerFrameStreamsEveryFifthBoard(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(603)), 30, 30, 0.35)
+	single := newTestGame(t)
+	single.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 22, StoreInterval: 100, TickTime: time.Millisecond})
+	batched := newTestGame(t)
+	batched.run(t, GameOfLifeInput{Board: seed.Pack(), TicksPerFrame: 5, MaxSteps: 22, StoreInterval: 100, TickTime: time.Millisecond})
+
+	// The single tick game's boards, births and deaths by step
+	boards, births, deaths := map[int]Board{}, map[int]int{}, map[int]int{}
+	board := seed.Clone()
+	for _, frame := range single.frames {
+		if frame.Ended != "" {
+			continue
+		}
+		board = applyFrame(t, board, frame)
+		boards[frame.Step] = board.Clone()
+		births[frame.Step], deaths[frame.Step] = births[frame.Step-1]+frame.Births, deaths[frame.Step-1]+frame.Deaths
+	}
+
+	// A frame every 5 steps, the last one cut short by the max steps
+	board, previous := seed.Clone(), 0
+	var steps []int
+	for _, frame := range batched.frames {
+		if frame.Ended != "" {
+			continue
+		}
+		board = applyFrame(t, board, frame)
+		steps = append(steps, frame.Step)
+		if !equalBoards(board, boards[frame.Step]) {
+			t.Errorf("step %d streamed\n%vwant the board of 5 single ticks\n%v", frame.Step, board, boards[frame.Step])
+		}
+		if frame.Births != births[frame.Step]-births[previous] || frame.Deaths != deaths[frame.Step]-deaths[previous] {
+			t.Errorf("step %d has %d births and %d deaths, want the %d and %d of its ticks", frame.Step, frame.Births, frame.Deaths,
+				births[frame.Step]-births[previous], deaths[frame.Step]-deaths[previous])
+		}
+		previous = frame.Step
+	}
+	if fmt.Sprint(steps) != "[5 10 15 20 22]" {
+		t.Errorf("frames were streamed at steps %v, want [5 10 15 20 22]", steps)
+	}
+}
+
+func TestTicksPerFrameIsValidated(t *testing.T) {
+	for _, ticks := range []int{-1, MaxTicksPerFrame + 1} {
+		if err := ValidateTicksPerFrame(ticks); err == nil {
+			t.Errorf("%d ticks per frame are valid", ticks)
+		}
+	}
+}
+
+func TestBoardQueryMatchesTheStreamSoFar(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(607)), 25, 25, 0.35)
+	game := newTestGame(t)
+	var midway StateChange
+	var streamed []StateChange
+	game.env.RegisterDelayedCallback(func() {
+		game.query(t, "board", &midway)
+		streamed = append(streamed, game.frames...)
+	}, 20*time.Millisecond)
+	game.signalAt(10*time.Millisecond, BatchToggleSignalName, BatchToggleSignal{Cells: [][2]int{{0, 0}, {12, 12}}})
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 60, StoreInterval: 100, TickTime: time.Millisecond})
+
+	// The query answers with the board the client reaches by applying every frame streamed up to it,
+	// the frame sent right before the query may still be in flight, so it can be ahead of the frames recorded by then
+	if len(streamed) == 0 || midway.Step == 0 {
+		t.Fatalf("queried the board at step %d after %d frames", midway.Step, len(streamed))
+	}
+	last := streamed[len(streamed)-1]
+	if !midway.Full || midway.Seq < last.Seq || midway.Step < last.Step {
+		t.Errorf("queried frame %d of step %d, the stream was already at frame %d of step %d", midway.Seq, midway.Step, last.Seq, last.Step)
+	}
+	board := seed.Clone()
+	reached := false
+	for _, frame := range game.frames {
+		if frame.Seq > midway.Seq {
+			break
+		}
+		board = applyFrame(t, board, frame)
+		reached = frame.Seq == midway.Seq && frame.Step == midway.Step
+	}
+	if !reached {
+		t.Fatalf("the stream has no frame %d of step %d", midway.Seq, midway.Step)
+	}
+	if !equalBoards(applyFrame(t, nil, midway), board) {
+		t.Errorf("board query answered\n%vthe stream reconstructs\n%v", applyFrame(t, nil, midway), board)
+	}
+}
+
+func TestDisableContinueAsNewRunsTheWholeGame(t *testing.T) {
+	// A glider on a torus never settles, so the game runs to its max steps
+	seed := emptyBoard(20, 20)
+	glider, _ := Pattern("glider")
+	seed.Stamp(glider, 1, 1)
+	input := GameOfLifeInput{Board: seed.Pack(), Wrap: true, MaxSteps: 200, TickTime: time.Millisecond}
+
+	// Without it the game rolls over at the default store interval
+	rolled := newTestGame(t)
+	if next := rolled.runToContinueAsNew(t, input); next.Step != DefaultStoreInterval {
+		t.Fatalf("game continued as new at step %d, want %d", next.Step, DefaultStoreInterval)
+	}
+
+	input.DisableContinueAsNew = true
+	game := newTestGame(t)
+	game.run(t, input)
+
+	var debug DebugState
+	game.query(t, "debug", &debug)
+	if debug.Step != 200 {
+		t.Fatalf("game ended at step %d, want 200", debug.Step)
+	}
+	want := seed
+	for range 200 {
+		want = NextGeneration(want, Rule{Wrap: true})
+	}
+	board := seed.Clone()
+	step := 0
+	for _, frame := range game.frames {
+		if frame.Ended != "" {
+			continue
+		}
+		if frame.Step != step+1 {
+			t.Fatalf("frame of step %d follows step %d", frame.Step, step)
+		}
+		step = frame.Step
+		board = applyFrame(t, board, frame)
+	}
+	if step != 200 || !equalBoards(board, want) {
+		t.Errorf("frames reach step %d with\n%vwant step 200 with\n%v", step, board, want)
+	}
+}
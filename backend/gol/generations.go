@@ -0,0 +1,108 @@
+package gol
+
+import "fmt"
+
+/* -------------------------------------------------------------------------- */
+/*                             Generations (Decay)                            */
+/* -------------------------------------------------------------------------- */
+
+// Generations rules give every cell a state alongside the alive/dead board:
+// 0 is dead, states-1 is alive (just born or surviving) and everything in between is decaying.
+// A cell that dies passes through the decaying states one generation at a time before it is fully dead.
+// Only alive cells count as neighbors and a decaying cell can't be reborn until it is fully dead.
+// With 2 states (or 0, the default) this is the classic game and no ages are tracked.
+
+// Most states a cell can have (ages are stored as bytes)
+const MaxGenerationsStates = 256
+
+func ValidateGenerations(states int) error {
+	if states == 0 || (states >= 2 && states <= MaxGenerationsStates) {
+		return nil
+	}
+	return fmt.Errorf("generations states must be between 2 and %d, got %d", MaxGenerationsStates, states)
+}
+
+// NewAges returns the ages for a board where every alive cell was just born
+func NewAges(board Board, states int) [][]uint8 {
+	ages := make([][]uint8, len(board))
+	for i := range board {
+		ages[i] = make([]uint8, len(board[i]))
+		for j, alive := range board[i] {
+			if alive {
+				ages[i][j] = uint8(states - 1)
+			}
+		}
+	}
+	return ages
+}
+
+// PackAges flattens the ages row-major (carried through continue-as-new)
+func PackAges(ages [][]uint8) []uint8 {
+	var packed []uint8
+	for _, row := range ages {
+		packed = append(packed, row...)
+	}
+	return packed
+}
+
+// UnpackAges expands flattened ages back to the board's dimensions
+func UnpackAges(packed []uint8, rows, cols int) [][]uint8 {
+	ages := make([][]uint8, rows)
+	for i := range ages {
+		ages[i] = append([]uint8(nil), packed[i*cols:(i+1)*cols]...)
+	}
+	return ages
+}
+
+// NextGenerationDecay advances a Generations board and its ages in place
+// Returns the alive flips (same meaning as Flipped) and every [row, col, state] whose state changed
+func NextGenerationDecay(board Board, ages [][]uint8, rule Rule, states int) (flipped [][2]int, cells [][3]int) {
+	alive := uint8(states - 1)
+
+	// Cells can be set alive or killed outside of a generation (e.g. a splatter), so catch the ages up first
+	for i := range board {
+		for j := range board[i] {
+			if board[i][j] && ages[i][j] != alive {
+				ages[i][j] = alive
+			} else if !board[i][j] && ages[i][j] == alive {
+				ages[i][j] = 0
+			}
+		}
+	}
+
+	// Evaluate every cell against the current board before applying anything
+	for i := range board {
+		for j := range board[i] {
+			if rule.isWall(i, j) {
+				continue
+			}
+			age := ages[i][j]
+			next := age
+			switch {
+			case board[i][j]:
+				if !rule.next(true, countAliveNeighbors(board, rule, i, j)) {
+					next = age - 1
+				}
+			case age == 0:
+				if rule.next(false, countAliveNeighbors(board, rule, i, j)) {
+					next = alive
+				}
+			default:
+				next = age - 1
+			}
+			if next == age {
+				continue
+			}
+			cells = append(cells, [3]int{i, j, int(next)})
+			if (age == alive) != (next == alive) {
+				flipped = append(flipped, [2]int{i, j})
+			}
+		}
+	}
+
+	for _, cell := range cells {
+		ages[cell[0]][cell[1]] = uint8(cell[2])
+	}
+	board.Toggle(flipped)
+	return flipped, cells
+}
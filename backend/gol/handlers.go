@@ -0,0 +1,191 @@
+package gol
+
+import (
+	"go.temporal.io/sdk/workflow"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Signal Handlers                              */
+/* -------------------------------------------------------------------------- */
+
+// The workflow runs the Handler of a signal's entry in Signals on its game, so a new signal is an
+// entry in the registry plus its handler (and its place in signalOrder). Handlers run in the
+// workflow and have to be deterministic, anything else goes through an activity. A handler's
+// error fails the workflow, a signal that can't apply is logged and ignored instead.
+
+// Handles a signal on the game, payload is what the entry's Payload returned (nil without one)
+type SignalHandler func(ctx workflow.Context, state *GolState, payload any) error
+
+// Receive receives the next signal from its channel and handles it
+func (s SignalSpec) Receive(ctx workflow.Context, state *GolState, c workflow.ReceiveChannel) error {
+	var payload any
+	if s.Payload != nil {
+		payload = s.Payload()
+	}
+	c.Receive(ctx, payload)
+	return s.Handler(ctx, state, payload)
+}
+
+func handleToggleStatus(ctx workflow.Context, state *GolState, _ any) error {
+	state.Paused = !state.Paused
+	state.queueStatus()
+	return nil
+}
+
+func handleSetPaused(ctx workflow.Context, state *GolState, payload any) error {
+	signal := payload.(*SetPausedSignal)
+	if state.Paused != signal.Paused {
+		state.Paused = signal.Paused
+		state.queueStatus()
+	}
+	return nil
+}
+
+func handleStartRecording(ctx workflow.Context, state *GolState, _ any) error {
+	state.Recording = true
+	state.History = nil
+
+	// Start the history with the current board so diffs can go back to the step recording began at
+	RecordSnapshot(state)
+	return nil
+}
+
+func handleStopRecording(ctx workflow.Context, state *GolState, _ any) error {
+	state.Recording = false
+	return nil
+}
+
+func handleSplatter(ctx workflow.Context, state *GolState, payload any) error {
+	signal := payload.(*SplatterSignal)
+
+	// The activity only picks the cells, the board itself is mutated here in the workflow
+	// The signal's X is the column and its Y the row
+	cells, err := DoActivityWithOutput(ctx, AmInstance.Splatter, SplatterInput{
+		Rows:    len(state.Board),
+		Cols:    len(state.Board[0]),
+		Row:     signal.Y,
+		Col:     signal.X,
+		Radius:  signal.Size,
+		Density: signal.Density,
+	})
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Error splattering board", "Error", err)
+		return err
+	}
+
+	flipped := state.keepOffWalls(state.Board.SetAlive(cells))
+	state.claimCells(flipped, signal.Team)
+	state.Changed = append(state.Changed, flipped...)
+	state.queueFlips(flipped)
+	return nil
+}
+
+func handleReset(ctx workflow.Context, state *GolState, _ any) error {
+	// Reseed in place, the id, step, pause state and tick time all carry on
+	board, err := DoActivityWithOutput(ctx, AmInstance.GetRandomBoard, GetRandomBoardInput{
+		Length:             len(state.Board),
+		Width:              len(state.Board[0]),
+		RandomBoardOptions: state.RandomBoard,
+	})
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Error resetting board", "Error", err)
+		return err
+	}
+	state.ReplaceBoard(board)
+
+	// Send the whole board so clients converge on the new one
+	state.queueFullFrame()
+	return nil
+}
+
+func handlePlacePattern(ctx workflow.Context, state *GolState, payload any) error {
+	signal := payload.(*PlacePatternSignal)
+
+	// The client validates the name, but the signal can be sent without going through it
+	pattern, ok := Pattern(signal.Name)
+	if !ok {
+		workflow.GetLogger(ctx).Warn("Ignoring unknown pattern", "Name", signal.Name)
+		return nil
+	}
+
+	pattern = Headed(pattern, signal.Heading)
+	var flipped [][2]int
+	if state.Wrap {
+		flipped = state.keepOffWalls(state.Board.StampWrapped(pattern, signal.Row, signal.Col))
+	} else {
+		flipped = state.keepOffWalls(state.Board.Stamp(pattern, signal.Row, signal.Col))
+	}
+	state.claimCells(flipped, signal.Team)
+	state.Changed = append(state.Changed, flipped...)
+	state.queueFlips(flipped)
+	return nil
+}
+
+func handleSetRule(ctx workflow.Context, state *GolState, payload any) error {
+	signal := payload.(*SetRuleSignal)
+	logger := workflow.GetLogger(ctx)
+
+	// The client validates the rule, but the signal can be sent without going through it
+	// Range games count with their ranges, the rule's counts would go unused
+	if state.Range > 0 {
+		logger.Warn("Ignoring rule of a range game", "Rule", signal.Rule)
+		return nil
+	}
+	birth, survival, err := ParseRule(signal.Rule)
+	if err != nil {
+		logger.Warn("Ignoring invalid rule", "Rule", signal.Rule, "Error", err)
+		return nil
+	}
+	state.Birth, state.Survival = birth, survival
+
+	// Cells that were stable under the old rule can change under the new one
+	state.FullScan = true
+	logger.Info("Switched rule", "Rule", state.Rule().Rulestring(), "Step", state.Step)
+	return nil
+}
+
+func handleSetMaxSteps(ctx workflow.Context, state *GolState, payload any) error {
+	signal := payload.(*SetMaxStepsSignal)
+	logger := workflow.GetLogger(ctx)
+
+	if err := signal.Validate(); err != nil {
+		logger.Warn("Ignoring invalid max steps", "Error", err)
+		return nil
+	}
+	state.MaxSteps = signal.MaxSteps
+
+	// The loop ends the game before the next generation when it is already past the new max
+	if state.MaxSteps <= state.Step {
+		logger.Info("Max steps reached, ending the game", "Step", state.Step, "MaxSteps", state.MaxSteps)
+	}
+	return nil
+}
+
+func handleSetWall(ctx workflow.Context, state *GolState, payload any) error {
+	signal := payload.(*SetWallSignal)
+
+	// Cells next to a changed wall see a different neighbor count
+	changed, killed := state.SetWalls(signal.Cells, signal.Wall)
+	state.Changed = append(state.Changed, changed...)
+	state.Changed = append(state.Changed, killed...)
+
+	// Walls only go out in full frames, so send the whole board
+	state.queueFullFrame()
+	return nil
+}
+
+func handleShift(ctx workflow.Context, state *GolState, payload any) error {
+	flipped := state.Shift(*payload.(*ShiftSignal))
+	state.queueFlips(flipped)
+	return nil
+}
+
+func handleBatchToggle(ctx workflow.Context, state *GolState, payload any) error {
+	signal := payload.(*BatchToggleSignal)
+
+	flipped := state.ToggleCells(signal.Cells)
+	state.claimCells(flipped, signal.Team)
+	state.Changed = append(state.Changed, flipped...)
+	state.queueFlips(flipped)
+	return nil
+}
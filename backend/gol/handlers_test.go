@@ -0,0 +1,89 @@
+package gol
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestSignalOrderCoversTheRegistry(t *testing.T) {
+	ordered := make(map[string]int)
+	for _, name := range signalOrder {
+		ordered[name]++
+	}
+	for _, name := range SignalNames() {
+		if ordered[name] != 1 {
+			t.Errorf("signal %s is in signalOrder %d times, want once", name, ordered[name])
+		}
+		if Signals[name].Handler == nil {
+			t.Errorf("signal %s has no handler", name)
+		}
+	}
+	if len(ordered) != len(Signals) {
+		t.Errorf("signalOrder has %d signals, the registry %d", len(ordered), len(Signals))
+	}
+}
+
+func TestEachSignalIsDispatchedToItsHandler(t *testing.T) {
+	payloads := map[string]any{
+		SplatterSignalName:     SplatterSignal{X: 3, Y: 4, Size: 2},
+		ToggleStatusSignal:     nil,
+		SetPausedSignalName:    SetPausedSignal{Paused: false},
+		StartRecordingSignal:   nil,
+		StopRecordingSignal:    nil,
+		ResetSignalName:        nil,
+		PlacePatternSignalName: PlacePatternSignal{Name: "glider", Row: 1, Col: 1},
+		SetWallSignalName:      SetWallSignal{Cells: [][2]int{{0, 0}}, Wall: true},
+		BatchToggleSignalName:  BatchToggleSignal{Cells: [][2]int{{5, 5}}},
+		SetRuleSignalName:      SetRuleSignal{Rule: "B36/S23"},
+		ShiftSignalName:        ShiftSignal{DRow: 1, DCol: 1},
+		SetMaxStepsSignalName:  SetMaxStepsSignal{MaxSteps: 1},
+	}
+	// call describes a handler call with the payload sent, handlers get a pointer to it
+	call := func(name string, payload any) string {
+		if payload == nil {
+			return fmt.Sprintf("%s %T", name, nil)
+		}
+		return fmt.Sprintf("%s *%T", name, payload)
+	}
+
+	for _, name := range signalOrder {
+		t.Run(name, func(t *testing.T) {
+			payload, ok := payloads[name]
+			if !ok {
+				t.Fatalf("no payload to send %s with", name)
+			}
+
+			// Wrap every handler to record which ones the game calls, and with what
+			var calls []string
+			original := Signals
+			t.Cleanup(func() { Signals = original })
+			Signals = make(map[string]SignalSpec, len(original))
+			for registered, spec := range original {
+				handler := spec.Handler
+				spec.Handler = func(ctx workflow.Context, state *GolState, received any) error {
+					calls = append(calls, fmt.Sprintf("%s %T", registered, received))
+					return handler(ctx, state, received)
+				}
+				Signals[registered] = spec
+			}
+
+			game := newTestGame(t)
+			game.signalAt(time.Millisecond, name, payload)
+			// Ended by setting the max steps to the step it is paused at, unless that is the signal sent
+			wantCalls := []string{call(SetMaxStepsSignalName, SetMaxStepsSignal{})}
+			if name != SetMaxStepsSignalName {
+				game.signalAt(2*time.Millisecond, SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: 1})
+				wantCalls = append([]string{call(name, payload)}, wantCalls...)
+			}
+			game.run(t, GameOfLifeInput{Board: emptyBoard(10, 10).Pack(), Paused: true, Step: 1, MaxSteps: 10, TickTime: time.Millisecond})
+
+			// The handler got the signal's payload decoded into its own type, and nothing else ran
+			if fmt.Sprint(calls) != fmt.Sprint(wantCalls) {
+				t.Errorf("handlers called %q, want %q", calls, wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package gol
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                  State Hub                                 */
+/* -------------------------------------------------------------------------- */
+
+// The hub fans state changes out to every subscriber of a game
+//
+// Drop semantics: each subscriber has a single pending frame. When a subscriber is still
+// busy with the previous frame, the new frame is merged into the pending one (flipped cells
+// that flip twice cancel out), so a slow client skips intermediate frames but still converges
+// on the latest board. Publishing never blocks, so a disconnected client can't stall SendState.
+//
+// A subscriber whose pending frame waited longer than the lag threshold falls back to keyframes:
+// the diffs after the pending frame are dropped rather than merged (merging costs the publisher
+// and the client more the further it falls behind), and it only gets the full frames the workflow
+// sends every KeyframeInterval steps. Its board stays a consistent but older one in the meantime.
+// Once it took a keyframe before the next one arrives it caught up and gets the diffs again.
+//
+// Subscribers report how long sending each frame on to their client took (ObserveSend). A
+// subscriber whose smoothed send latency is above the lag threshold falls back to keyframes as
+// soon as it is busy, and stays on them until its sends are fast again, so a client on a slow
+// connection doesn't have to fall a whole threshold behind first.
+type StateHub struct {
+	mu            sync.Mutex
+	subscribers   map[string]map[chan StateChange]*subscriber
+	droppedFrames uint64        // Frames merged into a pending one because the subscriber was busy, or skipped while it falls back to keyframes
+	lagThreshold  time.Duration // How long a frame can stay pending before its subscriber falls back to keyframes
+	now           func() time.Time
+}
+
+// Pending frames older than this make their subscriber fall back to keyframes
+const DefaultLagThreshold = time.Second
+
+// Weight of the newest send in a subscriber's send latency
+const latencySmoothing = 0.2
+
+type subscriber struct {
+	queuedAt  time.Time     // When the frame pending in the channel was queued, the lag is how long it has been waiting
+	keyframes bool          // Falling back to keyframes, diffs are dropped
+	latency   time.Duration // Smoothed time sending a frame on to the client took, 0 until one was reported
+}
+
+// lagging reports whether the subscriber is too slow for every diff, with its frame pending since queuedAt
+func (h *StateHub) lagging(sub *subscriber, now time.Time) bool {
+	return now.Sub(sub.queuedAt) > h.lagThreshold || sub.latency > h.lagThreshold
+}
+
+func NewStateHub() *StateHub {
+	return &StateHub{
+		subscribers:  make(map[string]map[chan StateChange]*subscriber),
+		lagThreshold: DefaultLagThreshold,
+		now:          time.Now,
+	}
+}
+
+// Subscribe returns a channel of state changes for the game and a function to unsubscribe
+func (h *StateHub) Subscribe(id string) (<-chan StateChange, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan StateChange, 1)
+	if h.subscribers[id] == nil {
+		h.subscribers[id] = make(map[chan StateChange]*subscriber)
+	}
+	h.subscribers[id][ch] = &subscriber{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[id][ch]; !ok {
+			return
+		}
+		delete(h.subscribers[id], ch)
+		if len(h.subscribers[id]) == 0 {
+			delete(h.subscribers, id)
+		}
+		close(ch)
+	}
+}
+
+// Publish sends the state change to every subscriber of its game without blocking
+// Returns the number of subscribers that were still busy with their pending frame
+func (h *StateHub) Publish(state StateChange) (dropped int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	for ch, sub := range h.subscribers[state.Id] {
+		if sub.keyframes {
+			if h.publishKeyframe(ch, sub, state, now) {
+				dropped++
+			}
+			continue
+		}
+
+		// Only the publisher sends (under the lock), so after draining the pending frame there is room
+		select {
+		case ch <- state:
+			sub.queuedAt = now
+		default:
+			select {
+			case pending := <-ch:
+				h.droppedFrames++
+				dropped++
+				ch <- MergeStateChanges(pending, state)
+				if h.lagging(sub, now) {
+					sub.keyframes = true
+				}
+			default:
+				// The subscriber took the pending frame in the meantime
+				ch <- state
+				sub.queuedAt = now
+			}
+		}
+	}
+	return dropped
+}
+
+// publishKeyframe sends the state change to a subscriber falling back to keyframes
+// Returns whether the subscriber was still busy with its pending frame or missed the state change
+func (h *StateHub) publishKeyframe(ch chan StateChange, sub *subscriber, state StateChange, now time.Time) bool {
+	var pending StateChange
+	busy := false
+	select {
+	case pending = <-ch:
+		busy = true
+	default:
+	}
+
+	switch {
+	case state.Full:
+		// It caught up when it took the previous frame before this one and sends fast enough again
+		ch <- state
+		sub.queuedAt = now
+		sub.keyframes = busy || sub.latency > h.lagThreshold
+	case busy && pending.Full:
+		// The keyframe it hasn't taken yet can still take the diff
+		ch <- MergeStateChanges(pending, state)
+	case state.OnlyEnded():
+		// The game ended, the board it has is the last one it gets
+		if busy {
+			pending.Ended = state.Ended
+			state = pending
+		}
+		ch <- state
+	default:
+		if busy {
+			ch <- pending
+		}
+		h.droppedFrames++
+		return true
+	}
+
+	if busy {
+		h.droppedFrames++
+	}
+	return busy
+}
+
+// ObserveSend records how long sending a frame taken from states on to its client took
+// Does nothing once the subscriber unsubscribed
+func (h *StateHub) ObserveSend(id string, states <-chan StateChange, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch, sub := range h.subscribers[id] {
+		if (<-chan StateChange)(ch) != states {
+			continue
+		}
+		if sub.latency == 0 {
+			sub.latency = latency
+		} else {
+			sub.latency += time.Duration(latencySmoothing * float64(latency-sub.latency))
+		}
+		return
+	}
+}
+
+// Stats returns the number of subscribers across every game, how many of them fell back to
+// keyframes and the frames dropped so far
+func (h *StateHub) Stats() (subscribers, lagging int, droppedFrames uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, game := range h.subscribers {
+		subscribers += len(game)
+		for _, sub := range game {
+			if sub.keyframes {
+				lagging++
+			}
+		}
+	}
+	return subscribers, lagging, h.droppedFrames
+}
+
+// MergeStateChanges combines two consecutive state changes into one
+// Cells flipped in both cancel out, everything else is taken from the newer state change
+// A full frame followed by a diff is still a full frame (of the board after the diff)
+// The merged frame covers the Seqs of both (see StateChange.FirstSeq)
+func MergeStateChanges(older, newer StateChange) StateChange {
+	if newer.Full {
+		return newer
+	}
+
+	flipped := NetFlips(older.Flipped, newer.Flipped)
+
+	// Generations cell states are absolute, so the newer state wins
+	var cells [][3]int
+	if older.Cells != nil || newer.Cells != nil {
+		states := make(map[[2]int]int, len(older.Cells)+len(newer.Cells))
+		for _, cell := range older.Cells {
+			states[[2]int{cell[0], cell[1]}] = cell[2]
+		}
+		for _, cell := range newer.Cells {
+			states[[2]int{cell[0], cell[1]}] = cell[2]
+		}
+		for cell, state := range states {
+			cells = append(cells, [3]int{cell[0], cell[1], state})
+		}
+		sort.Slice(cells, func(a, b int) bool {
+			if cells[a][0] != cells[b][0] {
+				return cells[a][0] < cells[b][0]
+			}
+			return cells[a][1] < cells[b][1]
+		})
+	}
+
+	merged := newer
+	merged.firstSeq = older.FirstSeq()
+	merged.Flipped = flipped
+	merged.Cells = cells
+	merged.Full = older.Full
+	merged.Restarted = older.Restarted || newer.Restarted
+	merged.Born, merged.Died = nil, nil
+	if older.Full {
+		merged.Walls = older.Walls
+		merged.Rows, merged.Cols = older.Rows, older.Cols
+	} else if older.Born != nil || older.Died != nil || newer.Born != nil || newer.Died != nil {
+		merged.Born, merged.Died = mergeBornDied(older, newer, flipped)
+	}
+	if older.Teams != nil || newer.Teams != nil {
+		merged.Teams = mergeTeams(older, newer, flipped)
+	}
+	return merged
+}
+
+// mergeBornDied splits the merged flipped cells into born and died
+// A cell flipped in both frames nets out, so each one left was flipped by exactly one of them
+func mergeBornDied(older, newer StateChange, flipped [][2]int) (born, died [][2]int) {
+	wasBorn := make(map[[2]int]bool, len(older.Born)+len(newer.Born))
+	for _, cell := range older.Born {
+		wasBorn[cell] = true
+	}
+	for _, cell := range newer.Born {
+		wasBorn[cell] = true
+	}
+	for _, cell := range flipped {
+		if wasBorn[cell] {
+			born = append(born, cell)
+		} else {
+			died = append(died, cell)
+		}
+	}
+	return born, died
+}
@@ -0,0 +1,155 @@
+package gol
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// diffFrames returns the diff frames of the given number of generations from the seed, and the last board
+func diffFrames(id string, seed Board, generations int) ([]StateChange, Board) {
+	frames := make([]StateChange, 0, generations)
+	board := seed
+	for step := 1; step <= generations; step++ {
+		next := NextGeneration(board, Rule{})
+		frames = append(frames, StateChange{Id: id, Step: step, Flipped: DiffFlipped(board, next)})
+		board = next
+	}
+	return frames, board
+}
+
+func TestSlowSubscriberConvergesOnTheLatestBoard(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(8)), 32, 32, 0.35)
+	frames, final := diffFrames("slow", seed, 40)
+
+	hub := NewStateHub()
+	states, unsubscribe := hub.Subscribe("slow")
+	defer unsubscribe()
+
+	// The subscriber only takes a frame every third publish, the frames in between get merged
+	board := seed.Clone()
+	dropped := 0
+	for i, frame := range frames {
+		dropped += hub.Publish(frame)
+		if i%3 == 1 {
+			board = applyFrame(t, board, <-states)
+		}
+	}
+	last := <-states
+	board = applyFrame(t, board, last)
+
+	if dropped == 0 {
+		t.Fatal("no frame was merged, the subscriber wasn't slow")
+	}
+	if want := frames[len(frames)-1].Step; last.Step != want {
+		t.Errorf("last frame is step %d, want %d", last.Step, want)
+	}
+	if !equalBoards(board, final) {
+		t.Errorf("subscriber ended on\n%vwant\n%v", board, final)
+	}
+}
+
+func TestPublishDoesNotBlockOnAnAbandonedSubscriber(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(9)), 16, 16, 0.35)
+	frames, _ := diffFrames("abandoned", seed, 20)
+
+	hub := NewStateHub()
+	_, unsubscribe := hub.Subscribe("abandoned")
+	defer unsubscribe()
+
+	// Nobody reads the channel, every publish past the first has to merge instead of blocking
+	for _, frame := range frames {
+		hub.Publish(frame)
+	}
+	if _, _, dropped := hub.Stats(); dropped != uint64(len(frames)-1) {
+		t.Errorf("dropped %d frames, want %d", dropped, len(frames)-1)
+	}
+}
+
+func TestSlowSenderFallsBackToKeyframesAndConverges(t *testing.T) {
+	// 120 generations with a keyframe every 10 steps, published every 100ms
+	const generations, keyframeInterval = 120, 10
+	seed := randomBoard(rand.New(rand.NewSource(615)), 32, 32, 0.35)
+	frames, final := diffFrames("mixed", seed, generations)
+	boards := []Board{seed}
+	for i, frame := range frames {
+		boards = append(boards, applyFrame(t, boards[i].Clone(), frame))
+		if frame.Step%keyframeInterval == 0 {
+			frames[i] = StateChange{Id: "mixed", Step: frame.Step, Full: true, Rows: 32, Cols: 32, Flipped: boards[i+1].LiveCells()}
+		}
+	}
+
+	now := time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC)
+	hub := NewStateHub()
+	hub.now = func() time.Time { return now }
+	fast, unsubscribeFast := hub.Subscribe("mixed")
+	defer unsubscribeFast()
+	slow, unsubscribeSlow := hub.Subscribe("mixed")
+	defer unsubscribeSlow()
+
+	// take applies the subscriber's pending frame and reports sending it, false when nothing is pending
+	take := func(states <-chan StateChange, board Board, latency time.Duration) (Board, StateChange, bool) {
+		select {
+		case frame := <-states:
+			hub.ObserveSend("mixed", states, latency)
+			return applyFrame(t, board, frame), frame, true
+		default:
+			return board, StateChange{}, false
+		}
+	}
+
+	fastBoard, slowBoard := seed.Clone(), seed.Clone()
+	var fastSteps []int
+	recoveredDiffs := 0
+	var taken StateChange
+	var ok bool
+	for i, frame := range frames {
+		hub.Publish(frame)
+		now = now.Add(100 * time.Millisecond)
+
+		// The fast subscriber sends every frame within a millisecond
+		if fastBoard, taken, ok = take(fast, fastBoard, time.Millisecond); ok {
+			fastSteps = append(fastSteps, taken.Step)
+		}
+		if frame.Step == 40 {
+			if _, lagging, _ := hub.Stats(); lagging != 1 {
+				t.Errorf("%d subscribers fell back to keyframes by step 40, want the slow one", lagging)
+			}
+		}
+
+		// The slow one checks every fifth frame and takes 2s to send one, after step 50 its connection
+		// recovers and its smoothed latency drops below the threshold by the keyframe at step 90
+		latency, every := 2*time.Second, 5
+		if frame.Step > 50 {
+			latency, every = time.Millisecond, 1
+		}
+		if i%every != every-1 {
+			continue
+		}
+		if slowBoard, taken, ok = take(slow, slowBoard, latency); !ok {
+			continue
+		}
+		if !equalBoards(slowBoard, boards[taken.Step]) {
+			t.Fatalf("slow subscriber's board at step %d is\n%vwant\n%v", taken.Step, slowBoard, boards[taken.Step])
+		}
+		switch {
+		case !taken.Full && taken.Step > 20 && taken.Step <= 50:
+			t.Errorf("slow subscriber got a diff at step %d while falling behind", taken.Step)
+		case !taken.Full && taken.Step > 90:
+			recoveredDiffs++
+		}
+	}
+
+	// The fast subscriber got every frame, and both end on the last board
+	for i, step := range fastSteps {
+		if step != i+1 {
+			t.Fatalf("fast subscriber got steps %v, want every step", fastSteps)
+		}
+	}
+	if len(fastSteps) != generations || !equalBoards(fastBoard, final) || !equalBoards(slowBoard, final) {
+		t.Errorf("subscribers ended on\n%vand\n%vwant\n%v", fastBoard, slowBoard, final)
+	}
+	if _, lagging, _ := hub.Stats(); lagging != 0 || recoveredDiffs == 0 {
+		t.Errorf("%d subscribers on keyframes and %d diffs once the slow one recovered, want diffs again", lagging, recoveredDiffs)
+	}
+}
@@ -0,0 +1,103 @@
+package gol
+
+import "fmt"
+
+/* -------------------------------------------------------------------------- */
+/*                               Larger Than Life                             */
+/* -------------------------------------------------------------------------- */
+
+// Larger than Life rules count the live cells within a Chebyshev radius (the (2R+1)x(2R+1)
+// square around a cell, the cell itself left out) and bring a cell alive or keep it alive
+// when the count falls within a range. At radius 1 this is the Moore neighborhood, so B3/S23
+// is birth 3..3 and survival 2..3. The counts come from a summed-area table of the board,
+// so a generation costs the same whatever the radius.
+
+// Largest radius, a radius 10 square already has 440 neighbors
+const MaxRange = 10
+
+// Inclusive range of neighbor counts
+type CountRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Has reports whether n neighbors are in the range
+func (c CountRange) Has(n int) bool {
+	return n >= c.Min && n <= c.Max
+}
+
+// Validate checks the range fits the neighbors of a radius
+func (c CountRange) Validate(radius int) error {
+	neighbors := (2*radius+1)*(2*radius+1) - 1
+	if c.Min < 0 || c.Min > c.Max || c.Max > neighbors {
+		return fmt.Errorf("count range %d..%d must be within 0..%d", c.Min, c.Max, neighbors)
+	}
+	return nil
+}
+
+// ValidateRange checks a radius and its birth and survival ranges, a radius of 0 is the classic game
+func ValidateRange(radius int, birth, survival CountRange) error {
+	if radius == 0 {
+		return nil
+	}
+	if radius < 0 || radius > MaxRange {
+		return fmt.Errorf("range must be between 1 and %d, got %d", MaxRange, radius)
+	}
+	if err := birth.Validate(radius); err != nil {
+		return fmt.Errorf("invalid birth: %w", err)
+	}
+	if err := survival.Validate(radius); err != nil {
+		return fmt.Errorf("invalid survival: %w", err)
+	}
+	// Like ParseRule, birth on 0 neighbors would bring the whole empty board alive at once
+	if birth.Min == 0 {
+		return fmt.Errorf("birth can't start at 0 neighbors")
+	}
+	return nil
+}
+
+// NextGenerationKernel computes the next generation counting the live cells within the radius
+// The rule's walls and wrapping apply like they do for NextGeneration, its counts and neighborhood don't
+func NextGenerationKernel(board Board, rule Rule, radius int, birth, survival CountRange) Board {
+	rows, cols := len(board), len(board[0])
+
+	// sums[i][j] is the number of live cells above and left of (i, j) on the board padded by the
+	// radius on every side, the padding is the other side of the board when it wraps and dead otherwise
+	sums := make([][]int, rows+2*radius+1)
+	sums[0] = make([]int, cols+2*radius+1)
+	for i := 1; i < len(sums); i++ {
+		sums[i] = make([]int, cols+2*radius+1)
+		for j := 1; j < len(sums[i]); j++ {
+			alive := 0
+			if x, y, ok := rule.neighborCell(rows, cols, i-1-radius, j-1-radius); ok {
+				if rule.isWall(x, y) {
+					if rule.WallsAlive {
+						alive = 1
+					}
+				} else if board[x][y] {
+					alive = 1
+				}
+			}
+			sums[i][j] = alive + sums[i-1][j] + sums[i][j-1] - sums[i-1][j-1]
+		}
+	}
+
+	next := make(Board, rows)
+	side := 2*radius + 1
+	for i := range next {
+		next[i] = make([]bool, cols)
+		for j := range next[i] {
+			if rule.isWall(i, j) {
+				continue
+			}
+			// The square around (i, j) starts at (i, j) on the padded board
+			count := sums[i+side][j+side] - sums[i][j+side] - sums[i+side][j] + sums[i][j]
+			if board[i][j] {
+				next[i][j] = survival.Has(count - 1)
+			} else {
+				next[i][j] = birth.Has(count)
+			}
+		}
+	}
+	return next
+}
@@ -0,0 +1,94 @@
+package gol
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestKernelAtRadiusOneMatchesNextGeneration(t *testing.T) {
+	random := rand.New(rand.NewSource(600))
+	walls := emptyBoard(24, 32)
+	walls.Toggle([][2]int{{0, 5}, {7, 7}, {7, 8}, {23, 31}})
+	for _, rule := range []Rule{{}, {Wrap: true}, {Walls: walls}, {Walls: walls, WallsAlive: true}} {
+		board := randomBoard(random, 24, 32, 0.4)
+		for step := 1; step <= 30; step++ {
+			want := NextGeneration(board, rule)
+			got := NextGenerationKernel(board, rule, 1, CountRange{3, 3}, CountRange{2, 3})
+			if !equalBoards(got, want) {
+				t.Fatalf("rule %+v, step %d: kernel gave\n%vwant\n%v", rule, step, got, want)
+			}
+			board = want
+		}
+	}
+}
+
+func TestKernelAtRadiusTwo(t *testing.T) {
+	// A lone cell brings the whole 5x5 square around it alive, itself dying with no neighbor
+	board := emptyBoard(9, 9)
+	board.Toggle([][2]int{{4, 4}})
+	next := NextGenerationKernel(board, Rule{}, 2, CountRange{1, 1}, CountRange{1, 24})
+	want := emptyBoard(9, 9)
+	for i := 2; i <= 6; i++ {
+		for j := 2; j <= 6; j++ {
+			if i != 4 || j != 4 {
+				want.Toggle([][2]int{{i, j}})
+			}
+		}
+	}
+	if !equalBoards(next, want) {
+		t.Fatalf("lone cell became\n%vwant\n%v", next, want)
+	}
+
+	// The square's cells all keep enough neighbors to survive, and only the board's corners
+	// see a single live cell of it within radius 2
+	next = NextGenerationKernel(next, Rule{}, 2, CountRange{1, 1}, CountRange{1, 24})
+	for _, cell := range [][2]int{{2, 2}, {6, 6}, {3, 5}} {
+		if !next[cell[0]][cell[1]] {
+			t.Errorf("square cell %v died", cell)
+		}
+	}
+	for _, cell := range [][2]int{{0, 0}, {8, 8}, {0, 8}, {8, 0}} {
+		if !next[cell[0]][cell[1]] {
+			t.Errorf("corner %v with a single neighbor in range wasn't born", cell)
+		}
+	}
+	if next[1][4] {
+		t.Error("cell (1, 4) with many neighbors in range was born")
+	}
+}
+
+func TestRangeGameFollowsTheKernel(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(601)), 20, 20, 0.3)
+	birth, survival := CountRange{5, 7}, CountRange{4, 9}
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Range: 2, RangeBirth: birth, RangeSurvival: survival, MaxSteps: 5, TickTime: time.Millisecond})
+
+	want := seed
+	for step := 0; step < 5; step++ {
+		want = NextGenerationKernel(want, Rule{}, 2, birth, survival)
+	}
+	if got := game.replay(t, seed); !equalBoards(got, want) {
+		t.Errorf("frames replay to\n%vwant\n%v", got, want)
+	}
+}
+
+func TestRangeIsValidated(t *testing.T) {
+	for _, test := range []struct {
+		radius          int
+		birth, survival CountRange
+	}{
+		{-1, CountRange{1, 1}, CountRange{1, 1}},
+		{MaxRange + 1, CountRange{1, 1}, CountRange{1, 1}},
+		{1, CountRange{0, 3}, CountRange{2, 3}},
+		{1, CountRange{3, 9}, CountRange{2, 3}},
+		{2, CountRange{5, 4}, CountRange{2, 3}},
+	} {
+		if err := ValidateRange(test.radius, test.birth, test.survival); err == nil {
+			t.Errorf("%+v is valid", test)
+		}
+	}
+	if err := ValidateRange(2, CountRange{1, 24}, CountRange{0, 24}); err != nil {
+		t.Errorf("the widest radius 2 ranges are invalid: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package gol
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                   Metrics                                  */
+/* -------------------------------------------------------------------------- */
+
+// The achieved frame rate is the wall-clock rate frames at a new step are published at,
+// smoothed with an exponential moving average so a single slow activity doesn't swing it.
+// The target is the rate the game's tick asks for, a game falling behind it has a worker
+// that can't keep up. Pauses aren't counted, the rate picks up again from the next frame.
+//
+// A game's stats are dropped once it ends so the ids of finished games don't pile up on
+// /metrics. Games that never say they ended (terminated, or moved to another worker) are
+// dropped once they haven't published anything for GameMetricsTTL.
+
+// How long a game that stopped publishing through this process keeps its stats
+const GameMetricsTTL = 10 * time.Minute
+
+// Weight of the newest frame in the achieved frame rate
+const fpsSmoothing = 0.2
+
+// Per game stats gathered from the state changes published by this process
+type GameStats struct {
+	Id          string
+	Steps       uint64  // Generations published
+	Population  int     // Live cells in the last published frame
+	AchievedFPS float64 // Smoothed rate frames at a new step were published at, 0 until there were two
+	TargetFPS   float64 // Rate the game's effective tick time asks for
+}
+
+type GameMetrics struct {
+	mu     sync.Mutex
+	games  map[string]*GameStats
+	steps  map[string]int       // Last step seen per game, a frame at a new step is a new generation
+	frames map[string]time.Time // When the last frame at a new step of a running game was seen
+	seen   map[string]time.Time // When the last frame of any kind was seen, for GameMetricsTTL
+	now    func() time.Time
+}
+
+func NewGameMetrics() *GameMetrics {
+	return &GameMetrics{
+		games:  make(map[string]*GameStats),
+		steps:  make(map[string]int),
+		frames: make(map[string]time.Time),
+		seen:   make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Observe records a published state change
+func (m *GameMetrics) Observe(state StateChange) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The game's last message, nothing more is coming for its stats
+	if state.Ended != "" {
+		m.forget(state.Id)
+		return
+	}
+	m.seen[state.Id] = m.now()
+
+	game, ok := m.games[state.Id]
+	if !ok {
+		game = &GameStats{Id: state.Id}
+		m.games[state.Id] = game
+	}
+
+	// Signals publish at the current step, a restarted game goes back to an earlier one
+	last, seen := m.steps[state.Id]
+	if seen && state.Step != last {
+		game.Steps++
+	}
+	m.steps[state.Id] = state.Step
+	game.Population = state.Population
+
+	if state.EffectiveTickTime > 0 {
+		game.TargetFPS = float64(time.Second) / float64(state.EffectiveTickTime)
+	}
+	m.observeFrameRate(game, state, seen && state.Step > last)
+}
+
+// observeFrameRate folds the time since the game's last new step into its achieved frame rate
+func (m *GameMetrics) observeFrameRate(game *GameStats, state StateChange, newStep bool) {
+	now := m.now()
+	if state.Paused {
+		delete(m.frames, state.Id)
+		return
+	}
+	if !newStep {
+		return
+	}
+
+	previous, ok := m.frames[state.Id]
+	m.frames[state.Id] = now
+	if !ok || !now.After(previous) {
+		return
+	}
+	fps := float64(time.Second) / float64(now.Sub(previous))
+	if game.AchievedFPS == 0 {
+		game.AchievedFPS = fps
+	} else {
+		game.AchievedFPS += fpsSmoothing * (fps - game.AchievedFPS)
+	}
+}
+
+// forget drops everything kept about the game
+func (m *GameMetrics) forget(id string) {
+	delete(m.games, id)
+	delete(m.steps, id)
+	delete(m.frames, id)
+	delete(m.seen, id)
+}
+
+// expire forgets the games that haven't published within GameMetricsTTL
+func (m *GameMetrics) expire() {
+	now := m.now()
+	for id, seen := range m.seen {
+		if now.Sub(seen) > GameMetricsTTL {
+			m.forget(id)
+		}
+	}
+}
+
+// Game returns the stats of the game, false when it hasn't published anything through this process lately
+func (m *GameMetrics) Game(id string) (GameStats, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire()
+	game, ok := m.games[id]
+	if !ok {
+		return GameStats{}, false
+	}
+	return *game, true
+}
+
+// Games returns the stats of every observed game sorted by id
+func (m *GameMetrics) Games() []GameStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire()
+	games := make([]GameStats, 0, len(m.games))
+	for _, game := range m.games {
+		games = append(games, *game)
+	}
+	sort.Slice(games, func(a, b int) bool { return games[a].Id < games[b].Id })
+	return games
+}
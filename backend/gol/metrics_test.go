@@ -0,0 +1,95 @@
+package gol
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGameMetrics(t *testing.T) {
+	now := time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC)
+	metrics := NewGameMetrics()
+	metrics.now = func() time.Time { return now }
+
+	// A generation every 200ms while the game asks for one every 100ms, a signal frame in between
+	for step := range 4 {
+		metrics.Observe(StateChange{Id: "slow", Step: step, Population: step, EffectiveTickTime: 100 * time.Millisecond})
+		now = now.Add(200 * time.Millisecond)
+	}
+	metrics.Observe(StateChange{Id: "slow", Step: 3, Population: 7, EffectiveTickTime: 100 * time.Millisecond})
+
+	game, ok := metrics.Game("slow")
+	if !ok {
+		t.Fatal("no stats for the game")
+	}
+	if game.Steps != 3 || game.Population != 7 || game.TargetFPS != 10 || game.AchievedFPS != 5 {
+		t.Errorf("stats = %+v, want 3 steps, population 7, target 10 fps and achieved 5 fps", game)
+	}
+}
+
+func TestGameMetricsForgetFinishedGames(t *testing.T) {
+	now := time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC)
+	metrics := NewGameMetrics()
+	metrics.now = func() time.Time { return now }
+
+	metrics.Observe(StateChange{Id: "ended", Step: 1})
+	metrics.Observe(StateChange{Id: "gone", Step: 1})
+	metrics.Observe(StateChange{Id: "running", Step: 1})
+
+	// One says it ended, one stops publishing (e.g. terminated) and one keeps going
+	metrics.Observe(StateChange{Id: "ended", Step: 1, Ended: EndedMaxSteps})
+	for range 3 {
+		now = now.Add(GameMetricsTTL / 2)
+		metrics.Observe(StateChange{Id: "running", Step: 2})
+	}
+
+	games := metrics.Games()
+	if len(games) != 1 || games[0].Id != "running" {
+		t.Errorf("games = %+v, want only the running one", games)
+	}
+	if len(metrics.steps) != 1 || len(metrics.seen) != 1 || len(metrics.frames) > 1 {
+		t.Errorf("kept %d steps, %d seen times and %d frame times, want only the running game's", len(metrics.steps), len(metrics.seen), len(metrics.frames))
+	}
+}
+
+func TestAchievedFPSFollowsTheFrameRate(t *testing.T) {
+	now := time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC)
+	metrics := NewGameMetrics()
+	metrics.now = func() time.Time { return now }
+	observe := func(step int, paused bool) {
+		metrics.Observe(StateChange{Id: "paced", Step: step, Paused: paused, EffectiveTickTime: 20 * time.Millisecond})
+	}
+
+	// 50 frames a second with a few milliseconds of jitter either way
+	step := 0
+	for i := range 40 {
+		step++
+		observe(step, false)
+		now = now.Add(time.Duration(20+3*(i%3-1)) * time.Millisecond)
+	}
+	if game, _ := metrics.Game("paced"); game.TargetFPS != 50 || math.Abs(game.AchievedFPS-50) > 3 {
+		t.Errorf("target %v and achieved %v fps, want both close to 50", game.TargetFPS, game.AchievedFPS)
+	}
+
+	// A long pause isn't counted as a slow frame
+	observe(step, true)
+	now = now.Add(10 * time.Second)
+	for range 3 {
+		step++
+		observe(step, false)
+		now = now.Add(20 * time.Millisecond)
+	}
+	if game, _ := metrics.Game("paced"); math.Abs(game.AchievedFPS-50) > 3 {
+		t.Errorf("achieved %v fps after a pause, want close to 50", game.AchievedFPS)
+	}
+
+	// The worker falls behind to 25 frames a second, the average gets there within a few dozen frames
+	for range 30 {
+		step++
+		observe(step, false)
+		now = now.Add(40 * time.Millisecond)
+	}
+	if game, _ := metrics.Game("paced"); math.Abs(game.AchievedFPS-25) > 1 {
+		t.Errorf("achieved %v fps once falling behind, want close to 25", game.AchievedFPS)
+	}
+}
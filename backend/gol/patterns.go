@@ -0,0 +1,109 @@
+package gol
+
+import (
+	"sort"
+	"strings"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                  Patterns                                  */
+/* -------------------------------------------------------------------------- */
+
+// Built-in patterns placed by the placePattern signal, 'O' is alive and '.' is dead
+var patternGrids = map[string][]string{
+	"glider": {
+		".O.",
+		"..O",
+		"OOO",
+	},
+	"blinker": {
+		"OOO",
+	},
+	"lwss": {
+		".O..O",
+		"O....",
+		"O...O",
+		"OOOO.",
+	},
+	"gosperGun": {
+		"........................O...........",
+		"......................O.O...........",
+		"............OO......OO............OO",
+		"...........O...O....OO............OO",
+		"OO........O.....O...OO..............",
+		"OO........O...O.OO....O.O...........",
+		"..........O.....O.......O...........",
+		"...........O...O....................",
+		"............OO......................",
+	},
+	"pulsar": {
+		"..OOO...OOO..",
+		".............",
+		"O....O.O....O",
+		"O....O.O....O",
+		"O....O.O....O",
+		"..OOO...OOO..",
+		".............",
+		"..OOO...OOO..",
+		"O....O.O....O",
+		"O....O.O....O",
+		"O....O.O....O",
+		".............",
+		"..OOO...OOO..",
+	},
+}
+
+// Pattern returns a fresh copy of the named built-in pattern
+func Pattern(name string) (Board, bool) {
+	grid, ok := patternGrids[name]
+	if !ok {
+		return nil, false
+	}
+
+	pattern := make(Board, len(grid))
+	for i, line := range grid {
+		pattern[i] = make([]bool, len(line))
+		for j, c := range line {
+			pattern[i][j] = c == 'O'
+		}
+	}
+	return pattern, true
+}
+
+// PatternNames returns the names of the built-in patterns in sorted order
+func PatternNames() []string {
+	names := make([]string, 0, len(patternGrids))
+	for name := range patternGrids {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func patternList() string {
+	return strings.Join(PatternNames(), ", ")
+}
+
+// Stamp copies the pattern onto the board with its top left corner at (row, col)
+// Dead cells of the pattern are cleared too, the part falling outside the board is dropped
+// Returns the cells that flipped
+func (b Board) Stamp(pattern Board, row, col int) [][2]int {
+	var flipped [][2]int
+	for i, line := range pattern {
+		r := row + i
+		if r < 0 || r >= len(b) {
+			continue
+		}
+		for j, alive := range line {
+			c := col + j
+			if c < 0 || c >= len(b[r]) {
+				continue
+			}
+			if b[r][c] != alive {
+				b[r][c] = alive
+				flipped = append(flipped, [2]int{r, c})
+			}
+		}
+	}
+	return flipped
+}
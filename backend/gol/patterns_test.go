@@ -0,0 +1,72 @@
+package gol
+
+import (
+	"testing"
+	"time"
+)
+
+// stamped returns an empty board with the pattern stamped at (row, col)
+func stamped(t *testing.T, rows, cols int, name string, row, col int) Board {
+	t.Helper()
+	pattern, ok := Pattern(name)
+	if !ok {
+		t.Fatalf("no pattern %s", name)
+	}
+	board := emptyBoard(rows, cols)
+	board.Stamp(pattern, row, col)
+	return board
+}
+
+func TestPatternsAreRectangular(t *testing.T) {
+	for _, name := range PatternNames() {
+		pattern, _ := Pattern(name)
+		for i, line := range pattern {
+			if len(line) != len(pattern[0]) {
+				t.Errorf("pattern %s row %d has %d cells, the first has %d", name, i, len(line), len(pattern[0]))
+			}
+		}
+	}
+}
+
+func TestGliderMovesDiagonally(t *testing.T) {
+	// Four generations take the glider one cell down and to the right
+	board := stamped(t, 12, 12, "glider", 2, 3)
+	if got, want := evolve(board, 4), stamped(t, 12, 12, "glider", 3, 4); !equalBoards(got, want) {
+		t.Errorf("glider after 4 generations =\n%vwant\n%v", got, want)
+	}
+}
+
+func TestPlacePatternSignal(t *testing.T) {
+	game := newTestGame(t)
+	game.signalAt(time.Microsecond, PlacePatternSignalName, PlacePatternSignal{Name: "glider", Row: 2, Col: 3})
+	game.signalAt(time.Microsecond, PlacePatternSignalName, PlacePatternSignal{Name: "spaceship", Row: 8, Col: 8})
+	game.run(t, GameOfLifeInput{Board: emptyBoard(12, 12).Pack(), MaxSteps: 4, TickTime: time.Millisecond})
+
+	// The unknown pattern is ignored, the glider moved as it does on its own
+	if got, want := game.replay(t, emptyBoard(12, 12)), stamped(t, 12, 12, "glider", 3, 4); !equalBoards(got, want) {
+		t.Errorf("board =\n%vwant\n%v", got, want)
+	}
+}
+
+func TestStampClampsToTheBoard(t *testing.T) {
+	board := parseBoard(
+		"####",
+		"####",
+	)
+	blinker, _ := Pattern("blinker")
+	flipped := board.Stamp(blinker, 1, 2)
+
+	// Only the part on the board is stamped, it already had those cells alive
+	if len(flipped) != 0 {
+		t.Errorf("stamping live cells flipped %v", flipped)
+	}
+	glider, _ := Pattern("glider")
+	flipped = board.Stamp(glider, -1, -1)
+	want := parseBoard(
+		".###",
+		"####",
+	)
+	if !equalBoards(board, want) || len(flipped) != 1 {
+		t.Errorf("stamping a glider off the corner flipped %v and left\n%vwant\n%v", flipped, board, want)
+	}
+}
@@ -0,0 +1,80 @@
+package gol
+
+import "time"
+
+/* -------------------------------------------------------------------------- */
+/*                               State Publisher                              */
+/* -------------------------------------------------------------------------- */
+
+// Carries state changes from SendState to the SSE streams
+// The in-memory publisher only reaches streams served by the worker's own process, the redis
+// publisher reaches every backend instance subscribed to the same redis server
+type StatePublisher interface {
+	// Publish sends the state change to the game's subscribers
+	// Returns the number of local subscribers that dropped a frame (see StateHub.Publish)
+	Publish(id string, state StateChange) (int, error)
+
+	// Subscribe returns a channel of the game's state changes and a function to unsubscribe
+	Subscribe(id string) (<-chan StateChange, func())
+
+	// SubscribeWithSnapshot also returns the game's cached board (see SnapshotCache.Subscribe)
+	SubscribeWithSnapshot(id string) (StateChange, bool, <-chan StateChange, func())
+
+	// ObserveSend records how long a local subscriber took to send a frame on to its client (see StateHub.ObserveSend)
+	ObserveSend(id string, states <-chan StateChange, latency time.Duration)
+
+	// Stats returns the local subscriber count, how many fell back to keyframes and the frames they dropped
+	Stats() (subscribers, lagging int, droppedFrames uint64)
+
+	// Scrollback returns up to n of the game's last frames delivered locally, oldest first
+	Scrollback(id string, n int) []StateChange
+}
+
+// Delivers state changes to this process's subscribers, keeping the snapshot cache and scrollback in step
+type localFanout struct {
+	hub        *StateHub
+	cache      *SnapshotCache
+	scrollback *Scrollback
+}
+
+func newLocalFanout() localFanout {
+	return localFanout{hub: NewStateHub(), cache: NewSnapshotCache(DefaultSnapshotTTL), scrollback: NewScrollback()}
+}
+
+func (f localFanout) deliver(state StateChange) int {
+	f.scrollback.Add(state)
+	return f.cache.Publish(f.hub, state)
+}
+
+func (f localFanout) Subscribe(id string) (<-chan StateChange, func()) {
+	return f.hub.Subscribe(id)
+}
+
+func (f localFanout) SubscribeWithSnapshot(id string) (StateChange, bool, <-chan StateChange, func()) {
+	return f.cache.Subscribe(f.hub, id)
+}
+
+func (f localFanout) ObserveSend(id string, states <-chan StateChange, latency time.Duration) {
+	f.hub.ObserveSend(id, states, latency)
+}
+
+func (f localFanout) Stats() (int, int, uint64) {
+	return f.hub.Stats()
+}
+
+func (f localFanout) Scrollback(id string, n int) []StateChange {
+	return f.scrollback.Last(id, n)
+}
+
+// Publishes within the process, the worker and the http server have to share it
+type MemoryPublisher struct {
+	localFanout
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{localFanout: newLocalFanout()}
+}
+
+func (p *MemoryPublisher) Publish(id string, state StateChange) (int, error) {
+	return p.deliver(state), nil
+}
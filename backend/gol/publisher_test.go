@@ -0,0 +1,114 @@
+package gol
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Every publisher SendState can be configured with
+var (
+	_ StatePublisher = (*MemoryPublisher)(nil)
+	_ StatePublisher = (*RedisPublisher)(nil)
+	_ StatePublisher = (*BreakerPublisher)(nil)
+)
+
+// receive waits for the next frame on the channel, publishers may deliver asynchronously
+func receive(t *testing.T, states <-chan StateChange) StateChange {
+	t.Helper()
+	select {
+	case state, ok := <-states:
+		if !ok {
+			t.Fatal("channel closed before the frame arrived")
+		}
+		return state
+	case <-time.After(5 * time.Second):
+		t.Fatal("no frame arrived")
+	}
+	return StateChange{}
+}
+
+// testPublisher runs the behavior SendState and the SSE streams rely on against the publisher
+func testPublisher(t *testing.T, publisher StatePublisher) {
+	seed := randomBoard(rand.New(rand.NewSource(55)), 16, 16, 0.35)
+	frames, final := diffFrames("conformance", seed, 5)
+	keyframe := StateChange{Id: "conformance", Full: true, Flipped: seed.LiveCells(), Rows: 16, Cols: 16}
+
+	states, unsubscribe := publisher.Subscribe("conformance")
+	other, unsubscribeOther := publisher.Subscribe("other")
+	defer unsubscribeOther()
+	if subscribers, _, _ := publisher.Stats(); subscribers != 2 {
+		t.Errorf("Stats has %d subscribers, want 2", subscribers)
+	}
+
+	// Frames reach the game's subscribers in order, and only them
+	board := emptyBoard(16, 16)
+	for _, frame := range append([]StateChange{keyframe}, frames...) {
+		if _, err := publisher.Publish(frame.Id, frame); err != nil {
+			t.Fatal(err)
+		}
+		received := receive(t, states)
+		if received.Step != frame.Step {
+			t.Fatalf("received step %d, published %d", received.Step, frame.Step)
+		}
+		board = applyFrame(t, board, received)
+	}
+	if !equalBoards(board, final) {
+		t.Errorf("subscriber ended on\n%vwant\n%v", board, final)
+	}
+	select {
+	case state := <-other:
+		t.Errorf("subscriber of another game received step %d", state.Step)
+	default:
+	}
+
+	// A late subscriber starts from the cached board
+	snapshot, ok, late, unsubscribeLate := publisher.SubscribeWithSnapshot("conformance")
+	defer unsubscribeLate()
+	if !ok || snapshot.Step != frames[len(frames)-1].Step {
+		t.Fatalf("snapshot is step %d (cached %v), want the last published step", snapshot.Step, ok)
+	}
+	if !equalBoards(applyFrame(t, emptyBoard(16, 16), snapshot), final) {
+		t.Error("snapshot isn't the last published board")
+	}
+	if _, err := publisher.Publish("conformance", StateChange{Id: "conformance", Step: 6}); err != nil {
+		t.Fatal(err)
+	}
+	if state := receive(t, late); state.Step != 6 {
+		t.Errorf("late subscriber received step %d, want 6", state.Step)
+	}
+	receive(t, states)
+
+	// Scrollback has the last frames delivered, oldest first
+	scrollback := publisher.Scrollback("conformance", 2)
+	if len(scrollback) != 2 || scrollback[0].Step != 5 || scrollback[1].Step != 6 {
+		t.Errorf("scrollback of 2 is %+v, want steps 5 and 6", scrollback)
+	}
+
+	// Unsubscribing closes the channel
+	unsubscribe()
+	if _, ok := <-states; ok {
+		t.Error("channel is still open after unsubscribing")
+	}
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	testPublisher(t, NewMemoryPublisher())
+}
+
+func TestMemoryPublisherReportsDrops(t *testing.T) {
+	publisher := NewMemoryPublisher()
+	_, unsubscribe := publisher.Subscribe("dropped")
+	defer unsubscribe()
+
+	// The subscriber never reads, the second frame merges into the pending first one
+	if dropped, err := publisher.Publish("dropped", StateChange{Id: "dropped", Step: 1}); dropped != 0 || err != nil {
+		t.Fatalf("first publish dropped %d with %v", dropped, err)
+	}
+	if dropped, err := publisher.Publish("dropped", StateChange{Id: "dropped", Step: 2}); dropped != 1 || err != nil {
+		t.Errorf("second publish dropped %d with %v, want 1", dropped, err)
+	}
+	if _, _, droppedFrames := publisher.Stats(); droppedFrames != 1 {
+		t.Errorf("Stats has %d dropped frames, want 1", droppedFrames)
+	}
+}
@@ -0,0 +1,156 @@
+package gol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                 Record Run                                 */
+/* -------------------------------------------------------------------------- */
+
+// Recording a run computes the generations back to back in a single activity, without the
+// tick timer or the state stream, for exporting a run offline (e.g. to a GIF). The frames are
+// the same diffs the live loop publishes, so replaying them onto the seed gives the same boards.
+//
+// Activity results are capped at 2MB by temporal and a busy board easily flips more than that
+// over a few hundred generations, so a recording stops early once its frames reach
+// MaxRecordFrameBytes. The rest of the run is recorded by starting again from its Final board.
+
+// Generations a single recording can cover
+const MaxRecordRunSteps = 1000
+
+// Type of the application error failing a recording with an invalid input
+const InvalidRecordErrorType = "InvalidRecord"
+
+// Most bytes of JSON frames a recording returns, the rest of the 2MB is left for the final board
+// (a 512x512 board packs to 32KB) and the encoding's overhead
+const MaxRecordFrameBytes = 1 << 20
+
+type RecordInput struct {
+	Board        *PackedBoard // Seed the run starts from
+	Steps        int          // Generations to compute
+	Neighborhood Neighborhood
+	Rule         string // Rulestring like B36/S23, defaults to B3/S23
+	Wrap         bool   // Wrap the edges around (see wrap.go)
+	Workers      int    // Goroutines computing each generation, defaults to runtime.NumCPU()
+}
+
+type RecordOutput struct {
+	Frames    [][][2]int   `json:"frames"`              // Flipped [row, col] cells of each generation, the first one is step 1
+	Final     *PackedBoard `json:"final"`               // Board after the last recorded generation
+	Truncated bool         `json:"truncated,omitempty"` // The frames reached MaxRecordFrameBytes before all the steps were recorded
+}
+
+// Validate checks the input before a recording is started
+func (input RecordInput) Validate() error {
+	_, err := input.rule()
+	return err
+}
+
+// rule validates the input and returns the rule of the run
+func (input RecordInput) rule() (Rule, error) {
+	if input.Board == nil {
+		return Rule{}, errors.New("record run requires a seed board")
+	}
+	if err := input.Board.Validate(); err != nil {
+		return Rule{}, err
+	}
+	if input.Steps <= 0 || input.Steps > MaxRecordRunSteps {
+		return Rule{}, fmt.Errorf("record steps must be between 1 and %d, got %d", MaxRecordRunSteps, input.Steps)
+	}
+	if err := input.Neighborhood.Validate(); err != nil {
+		return Rule{}, err
+	}
+	rule := Rule{Neighborhood: input.Neighborhood, Wrap: input.Wrap}
+	if input.Rule != "" {
+		var err error
+		if rule.Birth, rule.Survival, err = ParseRule(input.Rule); err != nil {
+			return Rule{}, err
+		}
+	}
+	return rule, nil
+}
+
+func (a *Am) RecordRun(ctx context.Context, input RecordInput) (RecordOutput, error) {
+	// Retrying can't fix the input
+	rule, err := input.rule()
+	if err != nil {
+		return RecordOutput{}, temporal.NewNonRetryableApplicationError(err.Error(), InvalidRecordErrorType, nil)
+	}
+
+	workers := input.Workers
+	if workers <= 0 {
+		// Only affects how fast a generation is computed, never its result
+		workers = runtime.NumCPU()
+	}
+	return RecordRun(input.Board.Unpack(), rule, input.Steps, workers), nil
+}
+
+// RecordRunWorkflow records a run with the RecordRun activity, for clients outside the worker
+func RecordRunWorkflow(ctx workflow.Context, input RecordInput) (RecordOutput, error) {
+	return DoActivityWithOutput(ctx, AmInstance.RecordRun, input)
+}
+
+// RecordRun computes the generations from the seed and returns their diffs
+// Stops before the generation that would take the frames past MaxRecordFrameBytes
+// Each generation is computed by the given number of goroutines, so it must only run in activities
+func RecordRun(board Board, rule Rule, steps int, workers int) RecordOutput {
+	output := RecordOutput{Frames: make([][][2]int, 0, steps)}
+
+	size := 2 // The brackets of the frames array
+	for step := 0; step < steps; step++ {
+		next := NextGenerationParallel(board, rule, workers)
+		flipped := DiffFlipped(board, next)
+		size += frameBytes(flipped) + 1
+		if size > MaxRecordFrameBytes {
+			output.Truncated = true
+			break
+		}
+		output.Frames = append(output.Frames, flipped)
+		board = next
+	}
+
+	output.Final = board.Pack()
+	return output
+}
+
+// frameBytes returns the length of the flipped cells encoded as a JSON array of [row, col] pairs
+func frameBytes(flipped [][2]int) int {
+	size := 2 + max(len(flipped)-1, 0) // Brackets and commas
+	for _, cell := range flipped {
+		size += 3 + len(strconv.Itoa(cell[0])) + len(strconv.Itoa(cell[1]))
+	}
+	return size
+}
+
+// NextGenerationParallel computes NextGeneration with the rows split into bands computed concurrently
+// Each band only reads the previous board and writes its own rows, so the result is identical to NextGeneration
+// Goroutines aren't deterministic workflow code, the workflow computes its generations serially
+func NextGenerationParallel(board Board, rule Rule, workers int) Board {
+	rows := len(board)
+	if workers <= 1 || rows < 2*workers {
+		return NextGeneration(board, rule)
+	}
+
+	next := make(Board, rows)
+	band := (rows + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < rows; start += band {
+		end := min(start+band, rows)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nextGenerationRows(board, next, rule, start, end)
+		}()
+	}
+	wg.Wait()
+	return next
+}
@@ -0,0 +1,134 @@
+package gol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestNextGenerationParallelMatchesSerial(t *testing.T) {
+	random := rand.New(rand.NewSource(7))
+	board := randomBoard(random, 37, 50, 0.35)
+	rule := Rule{Wrap: true, Walls: randomBoard(random, 37, 50, 0.05)}
+	board.Toggle(keepOnWalls(board, rule.Walls))
+
+	want := NextGeneration(board, rule)
+	for _, workers := range []int{1, 2, 3, 8, 64} {
+		if got := NextGenerationParallel(board, rule, workers); !equalBoards(got, want) {
+			t.Errorf("%d workers =\n%vwant\n%v", workers, got, want)
+		}
+	}
+}
+
+// Compare with BenchmarkNextGeneration, the serial version on the same board
+func BenchmarkNextGenerationParallel(b *testing.B) {
+	for _, workers := range []int{2, runtime.NumCPU()} {
+		b.Run(fmt.Sprint(workers, " workers"), func(b *testing.B) {
+			board := randomBoard(rand.New(rand.NewSource(6)), 512, 512, 0.3)
+			b.ReportAllocs()
+			for b.Loop() {
+				board = NextGenerationParallel(board, Rule{}, workers)
+			}
+		})
+	}
+}
+
+func TestRecordedRunMatchesTickedRun(t *testing.T) {
+	const steps = 40
+	seed := randomBoard(rand.New(rand.NewSource(24)), 32, 32, 0.35)
+	input := GameOfLifeInput{Board: seed.Pack(), Rule: "B36/S23", Wrap: true, MaxSteps: steps, TickTime: time.Millisecond}
+
+	// The live game publishes a diff per tick, keyframes left out of the way
+	game := newTestGame(t)
+	ticked := input
+	ticked.KeyframeInterval = steps + 1
+	game.run(t, ticked)
+
+	// The recording goes through the activity the worker runs for /record
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(RecordRunWorkflow)
+	env.RegisterActivity(AmInstance)
+	env.ExecuteWorkflow(RecordRunWorkflow, RecordInput{Board: seed.Pack(), Steps: steps, Rule: "B36/S23", Wrap: true, Workers: 3})
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatal(err)
+	}
+	var recorded RecordOutput
+	if err := env.GetWorkflowResult(&recorded); err != nil {
+		t.Fatal(err)
+	}
+
+	var frames [][][2]int
+	for _, frame := range game.frames {
+		if frame.Ended == "" {
+			frames = append(frames, frame.Flipped)
+		}
+	}
+	if len(recorded.Frames) != len(frames) || recorded.Truncated {
+		t.Fatalf("recorded %d frames (truncated %v), the game ticked %d", len(recorded.Frames), recorded.Truncated, len(frames))
+	}
+	for i := range frames {
+		if fmt.Sprint(recorded.Frames[i]) != fmt.Sprint(frames[i]) {
+			t.Fatalf("step %d recorded %v, ticked %v", i+1, recorded.Frames[i], frames[i])
+		}
+	}
+	if final := game.replay(t, seed); !equalBoards(recorded.Final.Unpack(), final) {
+		t.Errorf("recording ends on\n%vthe game on\n%v", recorded.Final.Unpack(), final)
+	}
+}
+
+func TestRecordRunStaysWithinThePayloadLimit(t *testing.T) {
+	// A dense soup on a big board flips far more than the limit over the maximum steps
+	seed := randomBoard(rand.New(rand.NewSource(25)), 512, 512, 0.5)
+	output := RecordRun(seed, Rule{Wrap: true}, MaxRecordRunSteps, 2)
+	if !output.Truncated || len(output.Frames) == MaxRecordRunSteps {
+		t.Fatalf("recorded %d frames without truncating", len(output.Frames))
+	}
+
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encoded) > 2<<20 {
+		t.Errorf("recording encodes to %d bytes, over temporal's 2MB", len(encoded))
+	}
+	frames, _ := json.Marshal(output.Frames)
+	if len(frames) > MaxRecordFrameBytes {
+		t.Errorf("frames encode to %d bytes, over the %d cap", len(frames), MaxRecordFrameBytes)
+	}
+
+	// The final board is where the recorded frames end, so recording from it continues the run
+	board := seed.Clone()
+	for _, frame := range output.Frames {
+		board.Toggle(frame)
+	}
+	if !equalBoards(board, output.Final.Unpack()) {
+		t.Error("the final board isn't the seed with the recorded frames applied")
+	}
+}
+
+func TestRecordRunRejectsInvalidInput(t *testing.T) {
+	board := emptyBoard(8, 8).Pack()
+	for _, input := range []RecordInput{
+		{Steps: 10},
+		{Board: board, Steps: 0},
+		{Board: board, Steps: MaxRecordRunSteps + 1},
+		{Board: board, Steps: 10, Rule: "conway"},
+		{Board: board, Steps: 10, Neighborhood: 7},
+		{Board: &PackedBoard{Rows: 8, Cols: 8}, Steps: 10},
+	} {
+		_, err := AmInstance.RecordRun(context.Background(), input)
+		var applicationErr *temporal.ApplicationError
+		if !errors.As(err, &applicationErr) || !applicationErr.NonRetryable() || applicationErr.Type() != InvalidRecordErrorType {
+			t.Errorf("recording %+v failed with %v, want a non retryable %s", input, err, InvalidRecordErrorType)
+		}
+	}
+}
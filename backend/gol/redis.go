@@ -0,0 +1,255 @@
+package gol
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Redis Publisher                              */
+/* -------------------------------------------------------------------------- */
+
+// Publishes state changes on redis pub/sub so any backend instance can serve a game's stream.
+// Every instance pattern-subscribes to all games and fans the frames out to its own
+// subscribers, so a frame reaches its local subscribers (and snapshot cache) the same way
+// whether the game runs on this instance's worker or another one.
+//
+// Only PUBLISH and PSUBSCRIBE are needed, so this speaks just enough RESP for those two.
+
+// Channels are named gol:state:{id}
+const redisChannelPrefix = "gol:state:"
+
+// Wait between attempts to reconnect the subscription
+const redisReconnectDelay = time.Second
+
+// Time allowed to connect, and for each publish to write its command and read the reply
+const redisTimeout = 5 * time.Second
+
+// Publishing connections kept open between publishes, any more are closed after their publish
+const redisIdleConns = 4
+
+type RedisPublisher struct {
+	localFanout
+	addr string
+
+	// Idle publishing connections, a publish takes one (or dials a new one) so publishes
+	// of different games don't wait on each other's round trips
+	idle chan *redisConn
+
+	timeout        time.Duration // Time allowed to connect, and for each publish's round trip
+	reconnectDelay time.Duration // Wait between attempts to reconnect the subscription
+
+	done chan struct{}
+}
+
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// NewRedisPublisher subscribes to the redis server at addr (host:port) in the background
+// Publishing connects lazily, so a redis outage fails SendState (which temporal retries) rather than startup
+func NewRedisPublisher(addr string) *RedisPublisher {
+	return newRedisPublisher(addr, redisTimeout, redisReconnectDelay)
+}
+
+func newRedisPublisher(addr string, timeout time.Duration, reconnectDelay time.Duration) *RedisPublisher {
+	p := &RedisPublisher{
+		localFanout:    newLocalFanout(),
+		addr:           addr,
+		idle:           make(chan *redisConn, redisIdleConns),
+		timeout:        timeout,
+		reconnectDelay: reconnectDelay,
+		done:           make(chan struct{}),
+	}
+	go p.subscribe()
+	return p
+}
+
+// Close stops the subscription and closes the idle publishing connections
+func (p *RedisPublisher) Close() error {
+	close(p.done)
+	for {
+		select {
+		case conn := <-p.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Publish publishes the state change on the game's channel
+// Drops happen on the subscribing instances, so there are none to report here
+func (p *RedisPublisher) Publish(id string, state StateChange) (int, error) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return 0, err
+	}
+
+	var conn *redisConn
+	select {
+	case conn = <-p.idle:
+	default:
+		c, err := net.DialTimeout("tcp", p.addr, p.timeout)
+		if err != nil {
+			return 0, err
+		}
+		conn = &redisConn{Conn: c, r: bufio.NewReader(c)}
+	}
+
+	// A redis that stopped answering fails the publish (which temporal retries) rather than hang it
+	err = conn.SetDeadline(time.Now().Add(p.timeout))
+	if err == nil {
+		_, err = conn.Write(encodeCommand("PUBLISH", redisChannelPrefix+id, string(payload)))
+	}
+	if err == nil {
+		_, err = readReply(conn.r)
+	}
+	if err != nil {
+		// Dial a new one next time rather than reuse a connection in an unknown state
+		conn.Close()
+		return 0, err
+	}
+	p.release(conn)
+	return 0, nil
+}
+
+// release keeps the connection for the next publish, unless enough are idle or the publisher is closed
+func (p *RedisPublisher) release(conn *redisConn) {
+	select {
+	case <-p.done:
+		conn.Close()
+		return
+	default:
+	}
+	select {
+	case p.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// subscribe relays every game's frames to the local fanout until Close, reconnecting on errors
+func (p *RedisPublisher) subscribe() {
+	for {
+		err := p.relay()
+		select {
+		case <-p.done:
+			return
+		case <-time.After(p.reconnectDelay):
+			log.Printf("Redis subscription lost, reconnecting: %v", err)
+		}
+	}
+}
+
+func (p *RedisPublisher) relay() error {
+	conn, err := net.DialTimeout("tcp", p.addr, p.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read below on Close, the goroutine ends with the connection
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-p.done:
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	if _, err := conn.Write(encodeCommand("PSUBSCRIBE", redisChannelPrefix+"*")); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return err
+		}
+
+		// Messages are [pmessage, pattern, channel, payload]
+		message, ok := reply.([]any)
+		if !ok || len(message) != 4 || message[0] != "pmessage" {
+			continue
+		}
+		payload, ok := message[3].(string)
+		if !ok {
+			continue
+		}
+
+		var state StateChange
+		if err := json.Unmarshal([]byte(payload), &state); err != nil {
+			log.Printf("Error decoding state from redis: %v", err)
+			continue
+		}
+		p.deliver(state)
+	}
+}
+
+// encodeCommand encodes the command as a RESP array of bulk strings
+func encodeCommand(args ...string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return []byte(b.String())
+}
+
+// readReply reads a RESP reply as a string, int64, []any or nil (null bulk string or array)
+// Error replies are returned as errors
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		items := make([]any, count)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected redis reply %q", line)
+}
@@ -0,0 +1,192 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis speaks the PUBLISH and PSUBSCRIBE subset of RESP the publisher uses
+type fakeRedis struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	subscribers map[net.Conn]string // Connection to its pattern, without the trailing *
+	subscribed  chan struct{}       // Receives on every PSUBSCRIBE
+	stalled     bool                // PUBLISH never replies
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{listener: listener, subscribers: make(map[net.Conn]string), subscribed: make(chan struct{}, 16)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			f.mu.Lock()
+			delete(f.subscribers, conn)
+			f.mu.Unlock()
+			return
+		}
+		command, _ := reply.([]any)
+		if len(command) == 0 {
+			return
+		}
+
+		f.mu.Lock()
+		switch command[0] {
+		case "PSUBSCRIBE":
+			pattern := command[1].(string)
+			f.subscribers[conn] = strings.TrimSuffix(pattern, "*")
+			fmt.Fprintf(conn, "*3\r\n$10\r\npsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(pattern), pattern)
+			f.subscribed <- struct{}{}
+		case "PUBLISH":
+			if f.stalled {
+				break
+			}
+			channel, payload := command[1].(string), command[2].(string)
+			receivers := 0
+			for subscriber, prefix := range f.subscribers {
+				if strings.HasPrefix(channel, prefix) {
+					subscriber.Write(encodeCommand("pmessage", prefix+"*", channel, payload))
+					receivers++
+				}
+			}
+			fmt.Fprintf(conn, ":%d\r\n", receivers)
+		}
+		f.mu.Unlock()
+	}
+}
+
+// dropSubscribers closes the subscription connections, like a redis restart
+func (f *fakeRedis) dropSubscribers() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for conn := range f.subscribers {
+		conn.Close()
+		delete(f.subscribers, conn)
+	}
+}
+
+// awaitSubscription waits for the publisher to (re)subscribe
+func (f *fakeRedis) awaitSubscription(t *testing.T) {
+	t.Helper()
+	select {
+	case <-f.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publisher didn't subscribe")
+	}
+}
+
+func TestRedisPublisher(t *testing.T) {
+	redis := newFakeRedis(t)
+	publisher := newRedisPublisher(redis.listener.Addr().String(), time.Second, 10*time.Millisecond)
+	defer publisher.Close()
+	redis.awaitSubscription(t)
+
+	testPublisher(t, publisher)
+
+	// Publishes reuse their connections rather than dial one each
+	if idle := len(publisher.idle); idle != 1 {
+		t.Errorf("%d idle connections after publishing one frame at a time, want 1", idle)
+	}
+}
+
+func TestRedisPublishTimesOut(t *testing.T) {
+	redis := newFakeRedis(t)
+	redis.stalled = true
+	publisher := newRedisPublisher(redis.listener.Addr().String(), 50*time.Millisecond, 10*time.Millisecond)
+	defer publisher.Close()
+
+	start := time.Now()
+	if _, err := publisher.Publish("stalled", StateChange{Id: "stalled"}); err == nil {
+		t.Fatal("publishing to a redis that never replies succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("publish gave up after %v, want the 50ms timeout", elapsed)
+	}
+	if idle := len(publisher.idle); idle != 0 {
+		t.Errorf("the timed out connection was kept for the next publish")
+	}
+}
+
+func TestRedisPublishesDoNotWaitOnEachOther(t *testing.T) {
+	redis := newFakeRedis(t)
+	redis.stalled = true
+	publisher := newRedisPublisher(redis.listener.Addr().String(), 200*time.Millisecond, 10*time.Millisecond)
+	defer publisher.Close()
+
+	// With a single connection the publishes would time out one after the other
+	const publishes = 4
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := range publishes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			publisher.Publish(fmt.Sprint("game-", i), StateChange{})
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed >= publishes*200*time.Millisecond {
+		t.Errorf("%d stalled publishes took %v, they waited on each other", publishes, elapsed)
+	}
+}
+
+func TestRedisSubscriptionReconnects(t *testing.T) {
+	redis := newFakeRedis(t)
+	publisher := newRedisPublisher(redis.listener.Addr().String(), time.Second, 10*time.Millisecond)
+	defer publisher.Close()
+	redis.awaitSubscription(t)
+	states, unsubscribe := publisher.Subscribe("reconnect")
+	defer unsubscribe()
+
+	// The publishing connection stays open throughout
+	if _, err := publisher.Publish("reconnect", StateChange{Id: "reconnect"}); err != nil {
+		t.Fatal(err)
+	}
+	receive(t, states)
+
+	goroutines := runtime.NumGoroutine()
+	for step := 1; step <= 5; step++ {
+		redis.dropSubscribers()
+		redis.awaitSubscription(t)
+
+		if _, err := publisher.Publish("reconnect", StateChange{Id: "reconnect", Step: step}); err != nil {
+			t.Fatal(err)
+		}
+		if state := receive(t, states); state.Step != step {
+			t.Fatalf("received step %d after reconnecting, want %d", state.Step, step)
+		}
+	}
+
+	// Each lost subscription took its goroutines with it
+	time.Sleep(50 * time.Millisecond)
+	if leaked := runtime.NumGoroutine() - goroutines; leaked > 0 {
+		t.Errorf("%d goroutines leaked over 5 reconnects", leaked)
+	}
+}
@@ -0,0 +1,54 @@
+package gol
+
+import (
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Auto Restart                                */
+/* -------------------------------------------------------------------------- */
+
+// Boards often die out long before MaxSteps, which leaves an empty grid (or ends the game, as an
+// empty board settles into a still life). A game with AutoRestart doesn't end when it dies out,
+// it stays empty for RestartAfter steps and is then reseeded with a fresh random board, like the
+// reset signal but with a new seed so a seeded game doesn't die out the same way again. The
+// frame of the new board is a full frame marked Restarted.
+
+// Steps a board stays empty before it is reseeded
+const DefaultRestartAfter = 10
+
+// RestartDue reports whether the board stayed empty long enough to be reseeded
+func (s GolState) RestartDue() bool {
+	return s.AutoRestart && s.DeadSteps >= s.RestartAfter
+}
+
+// countDeadSteps tracks how long the board has been empty after a generation
+func (s *GolState) countDeadSteps(population int) {
+	if population == 0 {
+		s.DeadSteps++
+	} else {
+		s.DeadSteps = 0
+	}
+}
+
+// Restart reseeds the board and publishes it as a full frame
+func Restart(ctx workflow.Context, golState *GolState) error {
+	options := golState.RandomBoard
+	options.Seed = 0
+	board, err := DoActivityWithOutput(ctx, AmInstance.GetRandomBoard, GetRandomBoardInput{
+		Length:             len(golState.Board),
+		Width:              len(golState.Board[0]),
+		RandomBoardOptions: options,
+	})
+	if err != nil {
+		return fmt.Errorf("reseeding extinct board: %w", err)
+	}
+	golState.ReplaceBoard(board)
+
+	stateChange := StateChangeFromNothing(*golState)
+	stateChange.Restarted = true
+	_, err = PublishState(ctx, golState, stateChange)
+	return err
+}
@@ -0,0 +1,63 @@
+package gol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExtinctBoardIsReseeded(t *testing.T) {
+	// A lone cell dies with the first generation
+	seed := emptyBoard(30, 30)
+	seed.Toggle([][2]int{{15, 15}})
+	// The fresh board is a glider, which doesn't settle within the game
+	reseeded := emptyBoard(30, 30)
+	glider, _ := Pattern("glider")
+	reseeded.Stamp(glider, 2, 2)
+	game := newTestGame(t)
+	game.env.OnActivity(AmInstance.GetRandomBoard, mock.Anything, mock.Anything).Return(reseeded, nil)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), AutoRestart: true, RestartAfter: 3, MaxSteps: 12, StoreInterval: 100, TickTime: time.Millisecond})
+
+	var restarts []StateChange
+	for _, frame := range game.frames {
+		if frame.Restarted {
+			restarts = append(restarts, frame)
+		}
+	}
+	if len(restarts) != 1 {
+		t.Fatalf("board was reseeded %d times, want once", len(restarts))
+	}
+	restart := restarts[0]
+	if !restart.Full || restart.Step != 3 || !equalBoards(applyFrame(t, nil, restart), reseeded) {
+		t.Errorf("reseeded with %+v, want a full frame of the fresh board after 3 empty steps", restart)
+	}
+
+	// The game goes on from the new board instead of ending as settled
+	var board StateChange
+	game.query(t, "board", &board)
+	if board.Step != 12 {
+		t.Errorf("game ended at step %d, want 12", board.Step)
+	}
+	if replayed := game.replay(t, seed); !equalBoards(replayed, evolve(reseeded, 9)) {
+		t.Errorf("game ended on\n%vwant the reseeded board 9 generations on", replayed)
+	}
+}
+
+func TestExtinctBoardEndsWithoutAutoRestart(t *testing.T) {
+	seed := emptyBoard(30, 30)
+	seed.Toggle([][2]int{{15, 15}})
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 12, StoreInterval: 100, TickTime: time.Millisecond})
+
+	for _, frame := range game.frames {
+		if frame.Restarted {
+			t.Errorf("step %d reseeded the board", frame.Step)
+		}
+	}
+	var board StateChange
+	game.query(t, "board", &board)
+	if board.Step >= 12 || board.Population != 0 {
+		t.Errorf("game ended at step %d with %d cells, want it to end early on the empty board", board.Step, board.Population)
+	}
+}
@@ -0,0 +1,87 @@
+package gol
+
+import (
+	"fmt"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                   Reveal                                   */
+/* -------------------------------------------------------------------------- */
+
+// A reveal uncovers a new game's board like a wipe before its first generation (for
+// presentations). Each of its frames is a full frame holding the live cells within a rectangle
+// growing from the center of the board, one tick apart, the last one shows the whole board.
+// The frames before the last one are sent as paused since the game isn't running yet.
+
+// Bounds how long a game can take to start, at the default tick this is 25 seconds
+const MaxRevealSteps = 100
+
+// ValidateRevealSteps checks the number of frames of a reveal, 0 skips it
+func ValidateRevealSteps(steps int) error {
+	if steps < 0 || steps > MaxRevealSteps {
+		return fmt.Errorf("reveal steps must be between 0 and %d, got %d", MaxRevealSteps, steps)
+	}
+	return nil
+}
+
+// RevealedCells returns the live cells shown by frame step (1 to steps) of a reveal in row-major order
+func (b Board) RevealedCells(step, steps int) [][2]int {
+	rows, cols := len(b), len(b[0])
+
+	// Half the size of the rectangle rounded up, so the last step covers odd sizes too
+	halfRows := (rows*step + 2*steps - 1) / (2 * steps)
+	halfCols := (cols*step + 2*steps - 1) / (2 * steps)
+	top, bottom := max(rows/2-halfRows, 0), min(rows/2+halfRows, rows)
+	left, right := max(cols/2-halfCols, 0), min(cols/2+halfCols, cols)
+
+	var cells [][2]int
+	for i := top; i < bottom; i++ {
+		for j := left; j < right; j++ {
+			if b[i][j] {
+				cells = append(cells, [2]int{i, j})
+			}
+		}
+	}
+	return cells
+}
+
+// Reveal publishes the frames of a reveal of the game's board
+func Reveal(ctx workflow.Context, golState *GolState, steps int) error {
+	if golState.Headless {
+		return nil
+	}
+
+	for step := 1; step <= steps; step++ {
+		stateChange := StateChangeFromNothing(*golState)
+		if step < steps {
+			stateChange.Flipped = golState.Board.RevealedCells(step, steps)
+			stateChange.Population = len(stateChange.Flipped)
+			stateChange.Paused = true
+			if golState.Checksums {
+				stateChange.Checksum = Checksum(revealedBoard(golState.Board, stateChange.Flipped))
+			}
+		}
+		if _, err := PublishState(ctx, golState, stateChange); err != nil {
+			return err
+		}
+
+		if step < steps {
+			if err := workflow.Sleep(ctx, golState.EffectiveTickTime()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// revealedBoard returns the board clients have after applying a frame of the reveal
+func revealedBoard(board Board, revealed [][2]int) Board {
+	partial := make(Board, len(board))
+	for i := range partial {
+		partial[i] = make([]bool, len(board[i]))
+	}
+	partial.SetAlive(revealed)
+	return partial
+}
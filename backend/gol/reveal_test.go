@@ -0,0 +1,59 @@
+package gol
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestRevealComesBeforeTheFirstGeneration(t *testing.T) {
+	const steps = 5
+	seed := randomBoard(rand.New(rand.NewSource(98)), 21, 31, 0.35)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), RevealSteps: steps, MaxSteps: 3, TickTime: time.Millisecond})
+
+	if len(game.frames) < steps+3 {
+		t.Fatalf("published %d frames, want the %d of the reveal and the game's", len(game.frames), steps)
+	}
+	previous := 0
+	for i, frame := range game.frames[:steps] {
+		if !frame.Full || frame.Step != 0 {
+			t.Fatalf("reveal frame %d is %+v, want a full frame of step 0", i+1, frame)
+		}
+		// Each frame uncovers more of the board, paused until the last one shows all of it
+		board := applyFrame(t, nil, frame)
+		if population := board.Population(); population < previous {
+			t.Errorf("reveal frame %d shows %d cells, fewer than the %d before it", i+1, population, previous)
+		}
+		previous = board.Population()
+		last := i == steps-1
+		if frame.Paused == last {
+			t.Errorf("reveal frame %d has paused %v", i+1, frame.Paused)
+		}
+		if last && !equalBoards(board, seed) {
+			t.Errorf("the last reveal frame shows\n%vnot the whole board", board)
+		}
+		if !last && equalBoards(board, seed) {
+			t.Errorf("reveal frame %d already shows the whole board", i+1)
+		}
+	}
+	if first := game.frames[steps]; first.Full || first.Step != 1 {
+		t.Errorf("the frame after the reveal is %+v, want the diff of step 1", first)
+	}
+	if got := game.replay(t, emptyBoard(21, 31)); !equalBoards(got, evolve(seed, 3)) {
+		t.Errorf("frames replay to\n%vwant\n%v", got, evolve(seed, 3))
+	}
+}
+
+func TestRevealStepsAreValidated(t *testing.T) {
+	for _, steps := range []int{-1, MaxRevealSteps + 1} {
+		if err := ValidateRevealSteps(steps); err == nil {
+			t.Errorf("%d reveal steps are valid", steps)
+		}
+	}
+	for _, steps := range []int{0, 1, MaxRevealSteps} {
+		if err := ValidateRevealSteps(steps); err != nil {
+			t.Errorf("%d reveal steps are invalid: %v", steps, err)
+		}
+	}
+}
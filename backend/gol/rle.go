@@ -0,0 +1,182 @@
+package gol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                RLE Patterns                                */
+/* -------------------------------------------------------------------------- */
+
+// Standard Life RLE: a header like "x = 3, y = 3, rule = B3/S23" followed by runs of
+// dead (b) and alive (o) cells, $ ending each row and ! ending the pattern
+
+// Lines in the RLE body are wrapped at this length (the usual convention)
+const rleLineLength = 70
+
+// String returns the rule in rulestring notation
+func (r Rule) String() string {
+	rulestring := r.Rulestring()
+	if r.Neighborhood == VonNeumann {
+		return rulestring + "V"
+	}
+	return rulestring
+}
+
+// EncodeRLE serializes the live cells of the board as RLE, trimmed to their bounding box
+func EncodeRLE(board Board, rule Rule) string {
+	minRow, minCol, maxRow, maxCol := len(board), -1, -1, -1
+	for i, row := range board {
+		for j, cell := range row {
+			if !cell {
+				continue
+			}
+			minRow, maxRow = min(minRow, i), max(maxRow, i)
+			if minCol < 0 || j < minCol {
+				minCol = j
+			}
+			maxCol = max(maxCol, j)
+		}
+	}
+	if maxRow < 0 {
+		return fmt.Sprintf("x = 0, y = 0, rule = %s\n!\n", rule)
+	}
+
+	// Each token is a run like "3o", "b" or "2$"
+	var tokens []string
+	run := func(count int, tag byte) {
+		if count == 1 {
+			tokens = append(tokens, string(tag))
+		} else {
+			tokens = append(tokens, strconv.Itoa(count)+string(tag))
+		}
+	}
+
+	pendingRows := 0
+	for i := minRow; i <= maxRow; i++ {
+		var cells []bool
+		for j := minCol; j <= maxCol; j++ {
+			cells = append(cells, board[i][j])
+		}
+
+		// Trailing dead cells are implied by the end of the row
+		for len(cells) > 0 && !cells[len(cells)-1] {
+			cells = cells[:len(cells)-1]
+		}
+		if len(cells) == 0 {
+			pendingRows++
+			continue
+		}
+		if pendingRows > 0 {
+			run(pendingRows, '$')
+			pendingRows = 0
+		}
+
+		for j := 0; j < len(cells); {
+			k := j
+			for k < len(cells) && cells[k] == cells[j] {
+				k++
+			}
+			if cells[j] {
+				run(k-j, 'o')
+			} else {
+				run(k-j, 'b')
+			}
+			j = k
+		}
+		pendingRows = 1
+	}
+
+	var rle strings.Builder
+	fmt.Fprintf(&rle, "x = %d, y = %d, rule = %s\n", maxCol-minCol+1, maxRow-minRow+1, rule)
+	lineLength := 0
+	for _, token := range append(tokens, "!") {
+		if lineLength+len(token) > rleLineLength {
+			rle.WriteString("\n")
+			lineLength = 0
+		}
+		rle.WriteString(token)
+		lineLength += len(token)
+	}
+	rle.WriteString("\n")
+	return rle.String()
+}
+
+// ParseRLE parses an RLE pattern into a board sized by its header
+func ParseRLE(rle string) (Board, error) {
+	var width, height int
+	headerFound := false
+	var body strings.Builder
+	for _, line := range strings.Split(rle, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case !headerFound && strings.HasPrefix(line, "x"):
+			for _, field := range strings.Split(line, ",") {
+				key, value, ok := strings.Cut(field, "=")
+				if !ok {
+					return nil, fmt.Errorf("invalid RLE header %q", line)
+				}
+				var err error
+				switch strings.TrimSpace(key) {
+				case "x":
+					width, err = strconv.Atoi(strings.TrimSpace(value))
+				case "y":
+					height, err = strconv.Atoi(strings.TrimSpace(value))
+				}
+				if err != nil {
+					return nil, fmt.Errorf("invalid RLE header %q: %w", line, err)
+				}
+			}
+			headerFound = true
+		default:
+			body.WriteString(line)
+		}
+	}
+	if !headerFound {
+		return nil, fmt.Errorf("missing RLE header")
+	}
+	if width < 0 || height < 0 {
+		return nil, fmt.Errorf("invalid RLE dimensions %dx%d", width, height)
+	}
+
+	board := make(Board, height)
+	for i := range board {
+		board[i] = make([]bool, width)
+	}
+
+	row, col, count := 0, 0, 0
+	for _, ch := range body.String() {
+		switch {
+		case ch >= '0' && ch <= '9':
+			count = count*10 + int(ch-'0')
+			continue
+		case ch == '!':
+			return board, nil
+		}
+
+		n := max(count, 1)
+		count = 0
+		switch ch {
+		case 'b', '.':
+			col += n
+		case 'o', 'A':
+			for range n {
+				if row >= height || col >= width {
+					return nil, fmt.Errorf("RLE pattern exceeds its %dx%d header", width, height)
+				}
+				board[row][col] = true
+				col++
+			}
+		case '$':
+			row += n
+			col = 0
+		default:
+			return nil, fmt.Errorf("invalid RLE character %q", ch)
+		}
+	}
+	return nil, fmt.Errorf("RLE pattern is missing its ! terminator")
+}
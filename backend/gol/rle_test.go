@@ -0,0 +1,90 @@
+package gol
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// boundingBox crops the board to its live cells, like EncodeRLE trims them
+func boundingBox(board Board) Board {
+	cells := board.LiveCells()
+	if len(cells) == 0 {
+		return Board{}
+	}
+	minRow, minCol, maxRow, maxCol := cells[0][0], cells[0][1], cells[0][0], cells[0][1]
+	for _, cell := range cells {
+		minRow, maxRow = min(minRow, cell[0]), max(maxRow, cell[0])
+		minCol, maxCol = min(minCol, cell[1]), max(maxCol, cell[1])
+	}
+	cropped := emptyBoard(maxRow-minRow+1, maxCol-minCol+1)
+	for _, cell := range cells {
+		cropped[cell[0]-minRow][cell[1]-minCol] = true
+	}
+	return cropped
+}
+
+func TestRLERoundTrip(t *testing.T) {
+	boards := map[string]Board{
+		"empty": emptyBoard(6, 6),
+		"glider": parseBoard(
+			"......",
+			"..#...",
+			"...#..",
+			".###..",
+			"......",
+		),
+		// Blank rows in the middle come out as a run of $
+		"blank rows": parseBoard(
+			"#...#",
+			".....",
+			".....",
+			".#...",
+		),
+		// Wide enough for the body to be wrapped over several lines
+		"random": randomBoard(rand.New(rand.NewSource(13)), 40, 120, 0.4),
+	}
+	for name, board := range boards {
+		t.Run(name, func(t *testing.T) {
+			rle := EncodeRLE(board, Rule{})
+			for _, line := range strings.Split(rle, "\n") {
+				if len(line) > rleLineLength && !strings.HasPrefix(line, "x") {
+					t.Errorf("line %q is longer than %d", line, rleLineLength)
+				}
+			}
+
+			parsed, err := ParseRLE(rle)
+			if err != nil {
+				t.Fatalf("parsing\n%s: %v", rle, err)
+			}
+			if want := boundingBox(board); !equalBoards(parsed, want) {
+				t.Errorf("%s parsed to\n%vwant\n%v", rle, parsed, want)
+			}
+		})
+	}
+}
+
+func TestExportRLEQuery(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(14)), 20, 20, 0.35)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Rule: "B36/S23", MaxSteps: 4, TickTime: time.Millisecond})
+
+	var rle string
+	game.query(t, "exportRLE", &rle)
+	if !strings.Contains(rle, "rule = B36/S23\n") {
+		t.Errorf("header of %q doesn't carry the game's rule", rle)
+	}
+	parsed, err := ParseRLE(rle)
+	if err != nil {
+		t.Fatalf("parsing the export: %v", err)
+	}
+	rule := Rule{Birth: 1<<3 | 1<<6, Survival: ConwaySurvival}
+	want := seed
+	for range 4 {
+		want = NextGeneration(want, rule)
+	}
+	if want = boundingBox(want); !equalBoards(parsed, want) {
+		t.Errorf("exported\n%vwant\n%v", parsed, want)
+	}
+}
@@ -0,0 +1,116 @@
+package gol
+
+import (
+	"fmt"
+	"strings"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                    Rules                                   */
+/* -------------------------------------------------------------------------- */
+
+// Neighborhood is the set of cells counted as a cell's neighbors
+type Neighborhood int
+
+const (
+	Moore      Neighborhood = iota // The 8 surrounding cells (default)
+	VonNeumann                     // The 4 orthogonally adjacent cells
+)
+
+func (n Neighborhood) Validate() error {
+	switch n {
+	case Moore, VonNeumann:
+		return nil
+	}
+	return fmt.Errorf("unknown neighborhood %d", n)
+}
+
+// Rule controls how the next generation is computed from a board
+type Rule struct {
+	Neighborhood Neighborhood
+	Birth        NeighborCounts // Neighbor counts that bring a dead cell alive, with Survival zero means B3/S23
+	Survival     NeighborCounts // Neighbor counts that keep a live cell alive
+	Walls        Board          // Cells that never change (see walls.go), nil when there are none
+	WallsAlive   bool           // Walls count as live neighbors instead of blocking
+	Wrap         bool           // Edges wrap around to the opposite side (see wrap.go)
+}
+
+// Set of neighbor counts (0–8), bit n is set when n neighbors are in the set
+type NeighborCounts uint16
+
+// Counts of Conway's Life, B3/S23
+const (
+	ConwayBirth    NeighborCounts = 1 << 3
+	ConwaySurvival NeighborCounts = 1<<2 | 1<<3
+)
+
+// Has reports whether n neighbors are in the set
+func (c NeighborCounts) Has(n int) bool {
+	return c&(1<<n) != 0
+}
+
+func (c NeighborCounts) String() string {
+	var digits strings.Builder
+	for n := 0; n <= 8; n++ {
+		if c.Has(n) {
+			digits.WriteByte(byte('0' + n))
+		}
+	}
+	return digits.String()
+}
+
+// counts returns the rule's birth and survival counts with Conway's Life as the default
+func (r Rule) counts() (birth, survival NeighborCounts) {
+	if r.Birth == 0 && r.Survival == 0 {
+		return ConwayBirth, ConwaySurvival
+	}
+	return r.Birth, r.Survival
+}
+
+// Rulestring returns the birth and survival counts in B/S notation, ParseRule reads it back
+func (r Rule) Rulestring() string {
+	birth, survival := r.counts()
+	return "B" + birth.String() + "/S" + survival.String()
+}
+
+// next returns whether a cell is alive next generation
+func (r Rule) next(alive bool, neighbors int) bool {
+	birth, survival := r.counts()
+	if alive {
+		return survival.Has(neighbors)
+	}
+	return birth.Has(neighbors)
+}
+
+// ParseRule parses a rulestring in B/S notation like B3/S23 or B36/S23 (case insensitive)
+// Birth on 0 neighbors is rejected, it would bring the whole empty board alive at once
+func ParseRule(rulestring string) (birth, survival NeighborCounts, err error) {
+	b, s, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(rulestring)), "/")
+	if !ok || !strings.HasPrefix(b, "B") || !strings.HasPrefix(s, "S") {
+		return 0, 0, fmt.Errorf("rule %q must look like B3/S23", rulestring)
+	}
+	if birth, err = parseNeighborCounts(b[1:]); err != nil {
+		return 0, 0, fmt.Errorf("rule %q has invalid birth counts: %w", rulestring, err)
+	}
+	if survival, err = parseNeighborCounts(s[1:]); err != nil {
+		return 0, 0, fmt.Errorf("rule %q has invalid survival counts: %w", rulestring, err)
+	}
+	if birth.Has(0) {
+		return 0, 0, fmt.Errorf("rule %q can't give birth on 0 neighbors", rulestring)
+	}
+	if birth == 0 && survival == 0 {
+		return 0, 0, fmt.Errorf("rule %q never keeps a cell alive", rulestring)
+	}
+	return birth, survival, nil
+}
+
+func parseNeighborCounts(digits string) (NeighborCounts, error) {
+	var counts NeighborCounts
+	for _, digit := range digits {
+		if digit < '0' || digit > '8' {
+			return 0, fmt.Errorf("%q is not a neighbor count between 0 and 8", digit)
+		}
+		counts |= 1 << (digit - '0')
+	}
+	return counts, nil
+}
@@ -0,0 +1,98 @@
+package gol
+
+import (
+	"sync"
+	"time"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                 Scrollback                                 */
+/* -------------------------------------------------------------------------- */
+
+// The scrollback keeps the last frames delivered to this process for every game, so a client
+// joining mid-game can scrub back a few seconds. Diffs toggle cells, so applying the frames in
+// reverse onto the current board walks it back one generation at a time.
+
+const (
+	DefaultScrollbackSize = 100  // Frames kept per game unless main changes ScrollbackSize
+	MaxScrollbackSize     = 1000 // Most frames a game can keep, keyframes carry the whole board
+	maxScrollbackGames    = 1000 // Games with a scrollback, the least recently updated is dropped beyond it
+)
+
+// Frames kept per game, only change it before any frame is delivered
+var ScrollbackSize = DefaultScrollbackSize
+
+type Scrollback struct {
+	mu    sync.Mutex
+	games map[string]*frameRing
+}
+
+// Fixed size ring of frames, oldest at start
+type frameRing struct {
+	frames    []StateChange
+	start     int
+	updatedAt time.Time
+}
+
+func NewScrollback() *Scrollback {
+	return &Scrollback{games: make(map[string]*frameRing)}
+}
+
+// Add appends the frame to its game's scrollback, overwriting the oldest once full
+// A frame going back in steps (a restarted game) starts the game's scrollback over
+func (s *Scrollback) Add(state StateChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ring, ok := s.games[state.Id]
+	if ok && len(ring.frames) > 0 && state.Step < ring.newest().Step {
+		ok = false
+	}
+	if !ok {
+		if len(s.games) >= maxScrollbackGames {
+			s.evictOldest()
+		}
+		ring = &frameRing{frames: make([]StateChange, 0, min(max(ScrollbackSize, 1), MaxScrollbackSize))}
+		s.games[state.Id] = ring
+	}
+
+	if len(ring.frames) < cap(ring.frames) {
+		ring.frames = append(ring.frames, state)
+	} else {
+		ring.frames[ring.start] = state
+		ring.start = (ring.start + 1) % len(ring.frames)
+	}
+	ring.updatedAt = time.Now()
+}
+
+// Last returns up to n of the game's most recent frames, oldest first
+func (s *Scrollback) Last(id string, n int) []StateChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	frames := []StateChange{}
+	ring, ok := s.games[id]
+	if !ok {
+		return frames
+	}
+	count := min(n, len(ring.frames))
+	for i := len(ring.frames) - count; i < len(ring.frames); i++ {
+		frames = append(frames, ring.frames[(ring.start+i)%len(ring.frames)])
+	}
+	return frames
+}
+
+func (r *frameRing) newest() StateChange {
+	return r.frames[(r.start+len(r.frames)-1)%len(r.frames)]
+}
+
+func (s *Scrollback) evictOldest() {
+	var oldestId string
+	var oldest time.Time
+	for id, ring := range s.games {
+		if oldestId == "" || ring.updatedAt.Before(oldest) {
+			oldestId, oldest = id, ring.updatedAt
+		}
+	}
+	delete(s.games, oldestId)
+}
@@ -0,0 +1,40 @@
+package gol
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScrollbackKeepsTheLastFrames(t *testing.T) {
+	defer func(size int) { ScrollbackSize = size }(ScrollbackSize)
+	ScrollbackSize = 5
+
+	scrollback := NewScrollback()
+	for seq := 1; seq <= 8; seq++ {
+		scrollback.Add(StateChange{Id: "game", Seq: seq, Step: seq})
+	}
+	seqs := func(frames []StateChange) string {
+		var seqs []int
+		for _, frame := range frames {
+			seqs = append(seqs, frame.Seq)
+		}
+		return fmt.Sprint(seqs)
+	}
+
+	// At most ScrollbackSize frames, newest last
+	if got := seqs(scrollback.Last("game", 50)); got != "[4 5 6 7 8]" {
+		t.Errorf("kept frames %s, want [4 5 6 7 8]", got)
+	}
+	if got := seqs(scrollback.Last("game", 2)); got != "[7 8]" {
+		t.Errorf("last 2 frames are %s, want [7 8]", got)
+	}
+	if got := scrollback.Last("other", 5); got == nil || len(got) != 0 {
+		t.Errorf("unknown game has frames %v", got)
+	}
+
+	// A restarted game starts over
+	scrollback.Add(StateChange{Id: "game", Seq: 1, Step: 1})
+	if got := seqs(scrollback.Last("game", 50)); got != "[1]" {
+		t.Errorf("restarted game kept frames %s, want [1]", got)
+	}
+}
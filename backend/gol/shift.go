@@ -0,0 +1,78 @@
+package gol
+
+import "fmt"
+
+/* -------------------------------------------------------------------------- */
+/*                                    Shift                                   */
+/* -------------------------------------------------------------------------- */
+
+// The shift signal moves every cell by a number of rows and columns (a pan). Cells moved off
+// the board are lost unless the shift wraps them around to the other side, a wrapped board
+// always wraps. Cell states of Generations games and teams move along with the cells, walls
+// stay where they are and kill the cells moved onto them.
+
+// Moves every cell, positive rows move down and positive columns move right
+const ShiftSignalName = "shift"
+
+type ShiftSignal struct {
+	DRow int  `json:"dRow"`
+	DCol int  `json:"dCol"`
+	Wrap bool `json:"wrap,omitempty"` // Cells moved off one edge come back on the other instead of being lost
+}
+
+func (s ShiftSignal) Validate() error {
+	if s.DRow < -MaxBoardSide || s.DRow > MaxBoardSide || s.DCol < -MaxBoardSide || s.DCol > MaxBoardSide {
+		return fmt.Errorf("shift must be between -%d and %d, got (%d, %d)", MaxBoardSide, MaxBoardSide, s.DRow, s.DCol)
+	}
+	return nil
+}
+
+// shifted returns a copy of the layer with every cell moved, cells moved off the edge are dropped unless wrapped
+func shifted[T any](layer [][]T, dRow, dCol int, wrap bool) [][]T {
+	moved := make([][]T, len(layer))
+	for i := range moved {
+		moved[i] = make([]T, len(layer[i]))
+	}
+	for i, row := range layer {
+		for j, cell := range row {
+			ni, nj := i+dRow, j+dCol
+			if wrap {
+				ni, nj = wrapIndex(ni, len(layer)), wrapIndex(nj, len(row))
+			} else if ni < 0 || ni >= len(layer) || nj < 0 || nj >= len(row) {
+				continue
+			}
+			moved[ni][nj] = cell
+		}
+	}
+	return moved
+}
+
+// Shift moves every cell of the board by the signal's offset
+// Returns the cells that flipped in row-major order
+func (s *GolState) Shift(signal ShiftSignal) [][2]int {
+	wrap := signal.Wrap || s.Wrap
+	board := Board(shifted(s.Board, signal.DRow, signal.DCol, wrap))
+	if s.Ages != nil {
+		s.Ages = shifted(s.Ages, signal.DRow, signal.DCol, wrap)
+	}
+	if s.Teams != nil {
+		s.Teams = shifted(s.Teams, signal.DRow, signal.DCol, wrap)
+	}
+	for i := range s.Walls {
+		for j, wall := range s.Walls[i] {
+			if wall {
+				board[i][j] = false
+				if s.Ages != nil {
+					s.Ages[i][j] = 0
+				}
+			}
+		}
+	}
+
+	flipped := DiffFlipped(s.Board, board)
+	s.Board = board
+
+	// Cells that didn't flip can still have new neighbors
+	s.FullScan = true
+	return flipped
+}
@@ -0,0 +1,57 @@
+package gol
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestShiftMovesTheBlock(t *testing.T) {
+	block := parseBoard("##", "##")
+	tests := []struct {
+		name   string
+		at     int // Row and column of the block's top left corner
+		signal ShiftSignal
+		want   string
+	}{
+		{"within the board", 2, ShiftSignal{DRow: 1, DCol: 1}, "[[3 3] [3 4] [4 3] [4 4]]"},
+		{"back up", 2, ShiftSignal{DRow: -2, DCol: -1}, "[[0 1] [0 2] [1 1] [1 2]]"},
+		{"clipped at the edge", 4, ShiftSignal{DRow: 1, DCol: 1}, "[[5 5]]"},
+		{"wrapped around", 4, ShiftSignal{DRow: 1, DCol: 1, Wrap: true}, "[[0 0] [0 5] [5 0] [5 5]]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			board := emptyBoard(6, 6)
+			board.Stamp(block, test.at, test.at)
+			state := GolState{Board: board.Clone()}
+			flipped := state.Shift(test.signal)
+
+			if got := fmt.Sprint(state.Board.LiveCells()); got != test.want {
+				t.Errorf("block moved to %s, want %s", got, test.want)
+			}
+			if !equalBoards(applyFrame(t, board.Clone(), StateChange{Flipped: flipped}), state.Board) {
+				t.Errorf("flipped cells %v don't turn the board into the shifted one", flipped)
+			}
+		})
+	}
+}
+
+func TestShiftSignal(t *testing.T) {
+	seed := emptyBoard(10, 10)
+	seed.Stamp(parseBoard("##", "##"), 2, 2)
+	game := newTestGame(t)
+	game.signalAt(time.Millisecond, ShiftSignalName, ShiftSignal{DRow: 1, DCol: 1})
+	game.signalAt(2*time.Millisecond, SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: 1})
+	// Paused so nothing but the shift changes the board
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Paused: true, Step: 1, MaxSteps: 10, TickTime: time.Millisecond})
+
+	want := "[[3 3] [3 4] [4 3] [4 4]]"
+	if got := fmt.Sprint(game.replay(t, seed).LiveCells()); got != want {
+		t.Errorf("the frames moved the block to %s, want %s", got, want)
+	}
+	var board StateChange
+	game.query(t, "board", &board)
+	if got := fmt.Sprint(board.Flipped); got != want {
+		t.Errorf("the game's block is at %s, want %s", got, want)
+	}
+}
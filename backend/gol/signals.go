@@ -0,0 +1,369 @@
+package gol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                   Signals                                  */
+/* -------------------------------------------------------------------------- */
+
+const SplatterSignalName = "splatter"
+
+// X is the column (left to right) and Y the row (top to bottom), like the pixel it was clicked at
+type SplatterSignal struct {
+	X       int     `json:"x"`
+	Y       int     `json:"y"`
+	Size    int     `json:"size"`              // Radius of the splat zone
+	Density float64 `json:"density,omitempty"` // Fraction of the zone brought alive, 0 uses the default (0.6)
+	Team    uint8   `json:"team,omitempty"`    // Team of the cells brought alive in a team game
+}
+
+// Biggest splatter radius accepted from a client
+const MaxSplatterSize = 64
+
+func (s SplatterSignal) Validate() error {
+	if s.X < 0 || s.Y < 0 {
+		return fmt.Errorf("splatter position (%d, %d) must not be negative", s.X, s.Y)
+	}
+	if s.Size < 0 || s.Size > MaxSplatterSize {
+		return fmt.Errorf("splatter size must be between 0 and %d, got %d", MaxSplatterSize, s.Size)
+	}
+	if s.Density < 0 || s.Density > 1 {
+		return fmt.Errorf("splatter density must be between 0 and 1, got %v", s.Density)
+	}
+	return ValidateTeam(s.Team)
+}
+
+const ToggleStatusSignal = "toggleStatus"
+
+// Sets the pause state instead of flipping it, so sending it twice is harmless
+const SetPausedSignalName = "setPaused"
+
+type SetPausedSignal struct {
+	Paused bool `json:"paused"`
+}
+
+// Recording captures a full board snapshot every generation into a bounded buffer
+// The buffer lives in the workflow and continue-as-new carries what fits (see MaxCarriedHistoryBytes)
+const StartRecordingSignal = "startRecording"
+const StopRecordingSignal = "stopRecording"
+
+// Reseeds the board with a fresh random one without restarting the workflow
+const ResetSignalName = "reset"
+
+// Places a built-in pattern (see patterns.go) on the board
+const PlacePatternSignalName = "placePattern"
+
+type PlacePatternSignal struct {
+	Name string `json:"name"`
+	Row  int    `json:"row"` // Top left corner of the pattern, the part outside the board is dropped (wrapped on a wrapped board)
+	Col  int    `json:"col"`
+
+	// Mirrors the pattern to travel towards se (as drawn), sw, ne or nw (see wrap.go)
+	Heading string `json:"heading,omitempty"`
+	Team    uint8  `json:"team,omitempty"` // Team of the pattern's cells in a team game
+}
+
+func (s PlacePatternSignal) Validate() error {
+	if _, ok := patternGrids[s.Name]; !ok {
+		return fmt.Errorf("unknown pattern %q, valid patterns are: %s", s.Name, patternList())
+	}
+	if err := ValidateTeam(s.Team); err != nil {
+		return err
+	}
+	return validateHeading(s.Heading)
+}
+
+// Adds or removes walls (see walls.go), cells outside the board are ignored
+const SetWallSignalName = "setWall"
+
+type SetWallSignal struct {
+	Cells [][2]int `json:"cells"` // [row, col] pairs
+	Wall  bool     `json:"wall"`  // true adds walls, false removes them
+}
+
+// Biggest number of cells a single setWall signal can change
+const MaxWallCells = 4096
+
+func (s SetWallSignal) Validate() error {
+	if len(s.Cells) == 0 || len(s.Cells) > MaxWallCells {
+		return fmt.Errorf("wall cells must be between 1 and %d, got %d", MaxWallCells, len(s.Cells))
+	}
+	return nil
+}
+
+// Switches the birth and survival counts of a running game, e.g. to B36/S23 (HighLife)
+const SetRuleSignalName = "setRule"
+
+type SetRuleSignal struct {
+	Rule string `json:"rule"` // Rulestring in B/S notation
+}
+
+func (s SetRuleSignal) Validate() error {
+	_, _, err := ParseRule(s.Rule)
+	return err
+}
+
+// Moves the step the game ends at, a max at or below the current step ends the game
+const SetMaxStepsSignalName = "setMaxSteps"
+
+type SetMaxStepsSignal struct {
+	MaxSteps int `json:"maxSteps"`
+}
+
+func (s SetMaxStepsSignal) Validate() error {
+	if s.MaxSteps <= 0 {
+		return fmt.Errorf("max steps must be positive, got %d", s.MaxSteps)
+	}
+	return nil
+}
+
+// Toggles many cells at once (e.g. painting a region) and publishes a single diff
+const BatchToggleSignalName = "batchToggle"
+
+type BatchToggleSignal struct {
+	Cells [][2]int `json:"cells"`          // [row, col] pairs, duplicates are toggled once and cells outside the board are ignored
+	Team  uint8    `json:"team,omitempty"` // Team of the cells toggled alive in a team game
+}
+
+// Biggest number of cells a single batchToggle signal can toggle
+const MaxBatchToggleCells = 4096
+
+func (s BatchToggleSignal) Validate() error {
+	if len(s.Cells) == 0 || len(s.Cells) > MaxBatchToggleCells {
+		return fmt.Errorf("batch cells must be between 1 and %d, got %d", MaxBatchToggleCells, len(s.Cells))
+	}
+	return ValidateTeam(s.Team)
+}
+
+/* ----------------------------- Signal Registry ---------------------------- */
+
+// Describes a signal handled by the workflow and the payload it expects
+type SignalSpec struct {
+	Name        string
+	Description string
+	Payload     func() any    // Returns a pointer to an empty payload, nil when the signal takes no payload
+	Handler     SignalHandler // Applies the signal to the game in the workflow (see handlers.go)
+}
+
+// Payloads that can check their own values once decoded
+type validator interface {
+	Validate() error
+}
+
+// Every signal the workflow handles, keyed by name
+var Signals = map[string]SignalSpec{
+	SplatterSignalName: {
+		Name:        SplatterSignalName,
+		Description: "Brings a random share of the cells within a radius alive",
+		Payload:     func() any { return &SplatterSignal{} },
+		Handler:     handleSplatter,
+	},
+	ToggleStatusSignal: {
+		Name:        ToggleStatusSignal,
+		Description: "Pauses a running game or resumes a paused one",
+		Handler:     handleToggleStatus,
+	},
+	SetPausedSignalName: {
+		Name:        SetPausedSignalName,
+		Description: "Pauses or resumes the game, sending it twice is harmless",
+		Payload:     func() any { return &SetPausedSignal{} },
+		Handler:     handleSetPaused,
+	},
+	StartRecordingSignal: {
+		Name:        StartRecordingSignal,
+		Description: "Starts recording a snapshot of the board every generation",
+		Handler:     handleStartRecording,
+	},
+	StopRecordingSignal: {
+		Name:        StopRecordingSignal,
+		Description: "Stops recording, the recorded snapshots are kept",
+		Handler:     handleStopRecording,
+	},
+	ResetSignalName: {
+		Name:        ResetSignalName,
+		Description: "Reseeds the board with a fresh random one",
+		Handler:     handleReset,
+	},
+	PlacePatternSignalName: {
+		Name:        PlacePatternSignalName,
+		Description: "Places a built-in pattern with its top left corner at a cell",
+		Payload:     func() any { return &PlacePatternSignal{} },
+		Handler:     handlePlacePattern,
+	},
+	SetWallSignalName: {
+		Name:        SetWallSignalName,
+		Description: "Adds or removes walls on cells",
+		Payload:     func() any { return &SetWallSignal{} },
+		Handler:     handleSetWall,
+	},
+	BatchToggleSignalName: {
+		Name:        BatchToggleSignalName,
+		Description: "Toggles many cells at once",
+		Payload:     func() any { return &BatchToggleSignal{} },
+		Handler:     handleBatchToggle,
+	},
+	SetRuleSignalName: {
+		Name:        SetRuleSignalName,
+		Description: "Switches the birth and survival counts of the game",
+		Payload:     func() any { return &SetRuleSignal{} },
+		Handler:     handleSetRule,
+	},
+	ShiftSignalName: {
+		Name:        ShiftSignalName,
+		Description: "Moves every cell by a number of rows and columns",
+		Payload:     func() any { return &ShiftSignal{} },
+		Handler:     handleShift,
+	},
+	SetMaxStepsSignalName: {
+		Name:        SetMaxStepsSignalName,
+		Description: "Moves the step the game ends at",
+		Payload:     func() any { return &SetMaxStepsSignal{} },
+		Handler:     handleSetMaxSteps,
+	},
+}
+
+// Order the workflow handles signals that are pending together in, whatever order they arrived in
+// A reset goes first so the edits sent along with it land on the new board, and walls and shifts
+// go before the cells drawn next to them. The pause and recording state go last so they cover the edits.
+var signalOrder = []string{
+	ResetSignalName,
+	SetRuleSignalName,
+	SetWallSignalName,
+	ShiftSignalName,
+	SplatterSignalName,
+	PlacePatternSignalName,
+	BatchToggleSignalName,
+	SetMaxStepsSignalName,
+	ToggleStatusSignal,
+	SetPausedSignalName,
+	StartRecordingSignal,
+	StopRecordingSignal,
+}
+
+// SignalNames returns the names of every registered signal in sorted order
+func SignalNames() []string {
+	names := make([]string, 0, len(Signals))
+	for name := range Signals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DecodePayload decodes and validates a signal payload into the signal's concrete type
+// Returns nil for signals that take no payload
+func (s SignalSpec) DecodePayload(body io.Reader) (any, error) {
+	if s.Payload == nil {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("signal %s requires a payload", s.Name)
+	}
+
+	payload := s.Payload()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(payload); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", s.Name, err)
+	}
+	if decoder.More() {
+		return nil, errors.New("payload must be a single JSON object")
+	}
+
+	if v, ok := payload.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", s.Name, err)
+		}
+	}
+	return payload, nil
+}
+
+/* --------------------------- Signal Descriptors --------------------------- */
+
+// Describes a signal to clients discovering the signals (see /signals)
+type SignalDescriptor struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Fields      []FieldDescriptor `json:"fields"` // Empty when the signal takes no payload
+}
+
+// Describes a field of a signal's payload
+type FieldDescriptor struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // JSON type like integer or array of [row, col] pairs
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// SignalDescriptors describes every registered signal in sorted order
+// They are derived from the registry, so they list exactly what DecodePayload accepts
+func SignalDescriptors() []SignalDescriptor {
+	descriptors := make([]SignalDescriptor, 0, len(Signals))
+	for _, name := range SignalNames() {
+		descriptors = append(descriptors, Signals[name].Describe())
+	}
+	return descriptors
+}
+
+// Describe describes the signal and the fields of its payload
+func (s SignalSpec) Describe() SignalDescriptor {
+	descriptor := SignalDescriptor{Name: s.Name, Description: s.Description, Fields: []FieldDescriptor{}}
+	if s.Payload == nil {
+		return descriptor
+	}
+
+	payload := reflect.TypeOf(s.Payload()).Elem()
+	for i := range payload.NumField() {
+		field := payload.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		descriptor.Fields = append(descriptor.Fields, FieldDescriptor{
+			Name:     name,
+			Type:     jsonType(field.Type),
+			Optional: strings.Contains(options, "omitempty"),
+		})
+	}
+	return descriptor
+}
+
+// jsonType names the JSON type a Go type is encoded as
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		// Cells are [row, col] pairs
+		if t.Elem() == reflect.TypeOf([2]int{}) {
+			return "array of [row, col] pairs"
+		}
+		return "array of " + jsonType(t.Elem())
+	case reflect.Array:
+		return "array"
+	}
+	return "object"
+}
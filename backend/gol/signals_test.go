@@ -0,0 +1,370 @@
+package gol
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Payloads of every registered signal, malformed ones don't decode into the signal's type
+// and invalid ones decode but fail its validation
+var signalPayloads = map[string]struct {
+	valid     string
+	want      any
+	malformed []string
+	invalid   []string
+}{
+	SplatterSignalName: {
+		valid:     `{"x": 3, "y": 4, "size": 5, "density": 0.5}`,
+		want:      &SplatterSignal{X: 3, Y: 4, Size: 5, Density: 0.5},
+		malformed: []string{`{"x": "3", "y": 4, "size": 5}`, `{"x": 3, "radius": 5}`, `[3, 4]`},
+		invalid:   []string{`{"x": -1, "y": 4, "size": 5}`, `{"x": 3, "y": 4, "size": 65}`, `{"x": 3, "y": 4, "size": 5, "density": 2}`},
+	},
+	ToggleStatusSignal:   {},
+	SetPausedSignalName:  {valid: `{"paused": true}`, want: &SetPausedSignal{Paused: true}, malformed: []string{`{"paused": "yes"}`}},
+	StartRecordingSignal: {},
+	StopRecordingSignal:  {},
+	ResetSignalName:      {},
+	PlacePatternSignalName: {
+		valid:     `{"name": "glider", "row": 1, "col": 2, "heading": "nw"}`,
+		want:      &PlacePatternSignal{Name: "glider", Row: 1, Col: 2, Heading: "nw"},
+		malformed: []string{`{"name": 7}`, `{"name": "glider", "row": 1.5}`},
+		invalid:   []string{`{"name": "spaceship"}`, `{"name": "glider", "heading": "up"}`},
+	},
+	SetWallSignalName: {
+		valid:     `{"cells": [[1, 2], [3, 4]], "wall": true}`,
+		want:      &SetWallSignal{Cells: [][2]int{{1, 2}, {3, 4}}, Wall: true},
+		malformed: []string{`{"cells": [[1, "2"]], "wall": true}`, `{"cells": "1,2"}`},
+		invalid:   []string{`{"cells": [], "wall": true}`},
+	},
+	BatchToggleSignalName: {
+		valid:     `{"cells": [[0, 0], [5, 6]]}`,
+		want:      &BatchToggleSignal{Cells: [][2]int{{0, 0}, {5, 6}}},
+		malformed: []string{`{"cells": [["a", 0]]}`, `{"cells": [[0, 0]]} {}`},
+		invalid:   []string{`{"cells": []}`, `{"cells": [[0, 0]], "team": 9}`},
+	},
+	SetRuleSignalName: {
+		valid:     `{"rule": "B36/S23"}`,
+		want:      &SetRuleSignal{Rule: "B36/S23"},
+		malformed: []string{`{"rule": 3623}`},
+		invalid:   []string{`{"rule": "conway"}`, `{"rule": "B0/S23"}`},
+	},
+	ShiftSignalName: {
+		valid:     `{"dRow": 1, "dCol": -2, "wrap": true}`,
+		want:      &ShiftSignal{DRow: 1, DCol: -2, Wrap: true},
+		malformed: []string{`{"dRow": "1"}`, `{"rows": 1}`},
+		invalid:   []string{`{"dRow": 100000}`},
+	},
+	SetMaxStepsSignalName: {
+		valid:     `{"maxSteps": 500}`,
+		want:      &SetMaxStepsSignal{MaxSteps: 500},
+		malformed: []string{`{"maxSteps": "500"}`, `{"steps": 500}`},
+		invalid:   []string{`{"maxSteps": 0}`},
+	},
+}
+
+func TestDecodePayload(t *testing.T) {
+	for _, name := range SignalNames() {
+		payloads, ok := signalPayloads[name]
+		if !ok {
+			t.Errorf("signal %s has no test payloads", name)
+			continue
+		}
+		spec := Signals[name]
+
+		t.Run(name, func(t *testing.T) {
+			payload, err := spec.DecodePayload(strings.NewReader(payloads.valid))
+			if err != nil {
+				t.Fatalf("valid payload %s: %v", payloads.valid, err)
+			}
+			if !reflect.DeepEqual(payload, payloads.want) {
+				t.Errorf("valid payload %s decoded to %#v, want %#v", payloads.valid, payload, payloads.want)
+			}
+
+			if spec.Payload != nil {
+				if _, err := spec.DecodePayload(strings.NewReader("")); err == nil {
+					t.Error("missing payload was accepted")
+				}
+			}
+			for _, body := range append(payloads.malformed, payloads.invalid...) {
+				if _, err := spec.DecodePayload(strings.NewReader(body)); err == nil {
+					t.Errorf("payload %s was accepted", body)
+				}
+			}
+		})
+	}
+}
+
+func TestSetPausedTwiceStaysPaused(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(64)), 16, 16, 0.35)
+	game := newTestGame(t)
+	var debug DebugState
+	for _, at := range []time.Duration{15, 25} {
+		game.env.RegisterDelayedCallback(func() {
+			game.env.SignalWorkflow(SetPausedSignalName, SetPausedSignal{Paused: true})
+		}, at*time.Millisecond)
+	}
+	game.env.RegisterDelayedCallback(func() {
+		game.query(t, "debug", &debug)
+		game.env.SignalWorkflow(SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: debug.Step})
+	}, time.Second)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 1000, TickTime: 10 * time.Millisecond})
+
+	// Paused by the first signal, the second changed nothing
+	if !debug.Paused || debug.Step > 2 {
+		t.Errorf("a second in the game is at step %d with paused %v, want paused by step 2", debug.Step, debug.Paused)
+	}
+}
+
+func TestBatchToggleIsOneDiff(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(66)), 32, 32, 0.35)
+
+	// 100 edits painting a 9x10 rectangle, with a few cells painted twice and strokes off the board
+	var cells [][2]int
+	want := seed.Clone()
+	for row := 4; row < 13; row++ {
+		for col := 20; col < 30; col++ {
+			cells = append(cells, [2]int{row, col})
+			want[row][col] = !want[row][col]
+		}
+	}
+	cells = append(cells, [2]int{4, 20}, [2]int{12, 29}, [2]int{8, 25}, [2]int{5, 21}, [2]int{6, 22})
+	cells = append(cells, [2]int{-1, 3}, [2]int{32, 0}, [2]int{0, 32}, [2]int{40, 40}, [2]int{3, -7})
+	if len(cells) != 100 {
+		t.Fatalf("the batch has %d edits", len(cells))
+	}
+
+	game := newTestGame(t)
+	var published int
+	game.env.RegisterDelayedCallback(func() {
+		published = len(game.frames)
+		game.env.SignalWorkflow(BatchToggleSignalName, BatchToggleSignal{Cells: cells})
+	}, 10*time.Millisecond)
+	// The paused game never ticks, it ends once it is at its max steps
+	game.env.RegisterDelayedCallback(func() {
+		game.env.SignalWorkflow(SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: 1})
+	}, time.Second)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Step: 1, Paused: true, TickTime: 10 * time.Millisecond})
+
+	var diffs []StateChange
+	for _, frame := range game.frames[published:] {
+		if frame.Ended == "" {
+			diffs = append(diffs, frame)
+		}
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("the batch published %d frames, want 1", len(diffs))
+	}
+	if want := DiffFlipped(seed, want); fmt.Sprint(diffs[0].Flipped) != fmt.Sprint(want) {
+		t.Errorf("the batch flipped %v, want the 90 painted cells %v", diffs[0].Flipped, want)
+	}
+
+	var board StateChange
+	game.query(t, "board", &board)
+	if got := applyFrame(t, emptyBoard(32, 32), board); !equalBoards(got, want) {
+		t.Errorf("board after the batch is\n%vwant\n%v", got, want)
+	}
+}
+
+func TestSetRuleMidRun(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(77)), 24, 24, 0.4)
+	game := newTestGame(t)
+	// The invalid rule is ignored, the valid one takes over from the generation after it
+	game.signalAt(35*time.Millisecond, SetRuleSignalName, SetRuleSignal{Rule: "B0/S23"})
+	game.signalAt(55*time.Millisecond, SetRuleSignalName, SetRuleSignal{Rule: "B36/S23"})
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 12, TickTime: 10 * time.Millisecond})
+
+	highLife, _, _ := ParseRule("B36/S23")
+	board, switched := seed, 0
+	for _, frame := range game.frames {
+		if frame.Full || frame.Ended != "" {
+			continue
+		}
+		next := applyFrame(t, board.Clone(), frame)
+		conway := NextGeneration(board, Rule{})
+		highlife := NextGeneration(board, Rule{Birth: highLife, Survival: ConwaySurvival})
+		switch {
+		case switched == 0 && equalBoards(next, conway):
+		case equalBoards(next, highlife):
+			if switched == 0 {
+				switched = frame.Step
+			}
+		default:
+			t.Fatalf("step %d follows neither rule (switched at %d)", frame.Step, switched)
+		}
+		board = next
+	}
+	// Generations that have no birth on 6 neighbors follow both rules, so the switch shows a little late at most
+	if switched < 3 || switched > 8 {
+		t.Errorf("the rule switched at step %d, want around the signal 55ms in", switched)
+	}
+
+	var rle string
+	game.query(t, "exportRLE", &rle)
+	if !strings.Contains(rle, "B36/S23") {
+		t.Errorf("the game's RLE doesn't carry the new rule:\n%s", rle)
+	}
+}
+
+func TestSetRuleSurvivesContinueAsNew(t *testing.T) {
+	game := newTestGame(t)
+	game.signalAt(15*time.Millisecond, SetRuleSignalName, SetRuleSignal{Rule: "B36/S23"})
+	next := game.runToContinueAsNew(t, GameOfLifeInput{Board: randomBoard(rand.New(rand.NewSource(77)), 16, 16, 0.4).Pack(), StoreInterval: 5, MaxSteps: 100, TickTime: 10 * time.Millisecond})
+	if next.Rule != "B36/S23" {
+		t.Errorf("continued with rule %q, want B36/S23", next.Rule)
+	}
+}
+
+func TestSetMaxStepsMovesTheEnd(t *testing.T) {
+	glider, _ := Pattern("glider")
+	seed := emptyBoard(16, 16)
+	seed.Stamp(glider, 2, 2)
+	tests := []struct {
+		name     string
+		maxSteps int
+		at       time.Duration
+		signal   int
+		wantLast func(step int) bool
+	}{
+		{"raised", 10, 55, 20, func(step int) bool { return step == 20 }},
+		// Already past the new max, the game ends before the next generation
+		{"lowered below the step", 100, 55, 2, func(step int) bool { return step >= 4 && step <= 7 }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := newTestGame(t)
+			game.signalAt(test.at*time.Millisecond, SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: test.signal})
+			game.run(t, GameOfLifeInput{Board: seed.Pack(), Wrap: true, MaxSteps: test.maxSteps, TickTime: 10 * time.Millisecond})
+
+			last := game.frames[len(game.frames)-1]
+			if last.Ended != EndedMaxSteps || !test.wantLast(last.Step) {
+				t.Errorf("game ended %q at step %d", last.Ended, last.Step)
+			}
+		})
+	}
+
+	// The new max carries over to the next run
+	game := newTestGame(t)
+	game.signalAt(15*time.Millisecond, SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: 30})
+	next := game.runToContinueAsNew(t, GameOfLifeInput{Board: seed.Pack(), Wrap: true, StoreInterval: 5, MaxSteps: 10, TickTime: 10 * time.Millisecond})
+	if next.MaxSteps != 30 {
+		t.Errorf("continued with max steps %d, want 30", next.MaxSteps)
+	}
+}
+
+func TestPausingPublishesAFrameRightAway(t *testing.T) {
+	glider, _ := Pattern("glider")
+	seed := emptyBoard(16, 16)
+	seed.Stamp(glider, 2, 2)
+	game := newTestGame(t)
+	game.signalAt(25*time.Millisecond, ToggleStatusSignal, nil)
+	game.signalAt(time.Second, SetPausedSignalName, SetPausedSignal{Paused: false})
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Wrap: true, MaxSteps: 5, TickTime: 10 * time.Millisecond})
+
+	// A paused game doesn't tick, so the pause frame can only have come from the signal
+	var status []StateChange
+	for _, frame := range game.frames {
+		if frame.Ended == "" && !frame.Full && len(frame.Flipped) == 0 {
+			status = append(status, frame)
+		}
+	}
+	if len(status) != 2 || !status[0].Paused || status[1].Paused || status[0].Step != status[1].Step || status[1].Seq != status[0].Seq+1 {
+		t.Fatalf("status frames are %+v, want paused then resumed with nothing in between", status)
+	}
+	for _, frame := range game.frames {
+		if frame.Step > status[0].Step && frame.Paused {
+			t.Errorf("frame of step %d after resuming is paused", frame.Step)
+		}
+	}
+}
+
+func TestSignalsInOneWindowApplyInAFixedOrder(t *testing.T) {
+	glider, _ := Pattern("glider")
+	seed := emptyBoard(16, 16)
+	seed.Stamp(glider, 8, 8)
+	signals := map[string]any{
+		// Placing a blinker and toggling its middle cell don't commute
+		PlacePatternSignalName: PlacePatternSignal{Name: "blinker", Row: 2, Col: 1},
+		BatchToggleSignalName:  BatchToggleSignal{Cells: [][2]int{{2, 2}, {12, 3}}},
+		SetRuleSignalName:      SetRuleSignal{Rule: "B36/S23"},
+	}
+
+	// play sends the signals in the given order while the splatter's activity runs and returns the board
+	play := func(order []string) Board {
+		game := newTestGame(t)
+		game.env.OnActivity(AmInstance.Splatter, mock.Anything, mock.Anything).Return(
+			func(context.Context, SplatterInput) ([][2]int, error) { return [][2]int{{14, 14}}, nil })
+		game.env.RegisterDelayedCallback(func() {
+			game.env.SignalWorkflow(SplatterSignalName, SplatterSignal{X: 14, Y: 14})
+			for _, name := range order {
+				game.env.SignalWorkflow(name, signals[name])
+			}
+		}, 25*time.Millisecond)
+		// Paused throughout, so the board is the seed with the edits
+		game.signalAt(time.Second, SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: 1})
+		game.run(t, GameOfLifeInput{Board: seed.Pack(), Paused: true, Step: 1, MaxSteps: 100, TickTime: 10 * time.Millisecond})
+
+		var board StateChange
+		game.query(t, "board", &board)
+		if replayed := game.replay(t, seed); !equalBoards(replayed, applyFrame(t, nil, board)) {
+			t.Errorf("%v: the frames replay to\n%vnot the game's board", order, replayed)
+		}
+		return applyFrame(t, nil, board)
+	}
+
+	want := play([]string{PlacePatternSignalName, BatchToggleSignalName, SetRuleSignalName})
+	for _, order := range [][]string{
+		{PlacePatternSignalName, BatchToggleSignalName, SetRuleSignalName},
+		{BatchToggleSignalName, PlacePatternSignalName, SetRuleSignalName},
+		{SetRuleSignalName, BatchToggleSignalName, PlacePatternSignalName},
+	} {
+		if got := play(order); !equalBoards(got, want) {
+			t.Errorf("delivered as %v the game ended on\n%vwant\n%v", order, got, want)
+		}
+	}
+
+	// signalOrder places the blinker before toggling, so its middle cell is dead
+	if !want[2][1] || want[2][2] || !want[2][3] || !want[12][3] || !want[14][14] {
+		t.Errorf("the signals weren't applied in signalOrder:\n%v", want)
+	}
+}
+
+func TestSignalDescriptorsListEverySignal(t *testing.T) {
+	descriptors := SignalDescriptors()
+	var names []string
+	for _, descriptor := range descriptors {
+		names = append(names, descriptor.Name)
+		spec := Signals[descriptor.Name]
+		if descriptor.Description == "" {
+			t.Errorf("signal %s has no description", descriptor.Name)
+		}
+		if (spec.Payload == nil) != (len(descriptor.Fields) == 0) {
+			t.Errorf("signal %s is described with fields %v", descriptor.Name, descriptor.Fields)
+		}
+	}
+	if fmt.Sprint(names) != fmt.Sprint(SignalNames()) {
+		t.Errorf("descriptors list %v, want every registered signal %v", names, SignalNames())
+	}
+
+	// Fields carry their JSON names and types
+	splatter := Signals[SplatterSignalName].Describe()
+	want := []FieldDescriptor{
+		{Name: "x", Type: "integer"},
+		{Name: "y", Type: "integer"},
+		{Name: "size", Type: "integer"},
+		{Name: "density", Type: "number", Optional: true},
+		{Name: "team", Type: "integer", Optional: true},
+	}
+	if fmt.Sprint(splatter.Fields) != fmt.Sprint(want) {
+		t.Errorf("splatter is described with %v, want %v", splatter.Fields, want)
+	}
+	if toggle := Signals[BatchToggleSignalName].Describe(); toggle.Fields[0].Type != "array of [row, col] pairs" {
+		t.Errorf("batch toggle's cells are described as %q", toggle.Fields[0].Type)
+	}
+}
@@ -0,0 +1,134 @@
+package gol
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                  Snapshots                                 */
+/* -------------------------------------------------------------------------- */
+
+// A snapshot is everything a game needs to carry on from where it is, like the input
+// continue-as-new passes to the next run. Restoring one starts a new workflow with it, which
+// picks up at the snapshot's step with the same board, rule and tick.
+
+// Game state served by the snapshot query
+type GameSnapshot struct {
+	Id            string        `json:"id"`
+	Step          int           `json:"step"`
+	MaxSteps      int           `json:"maxSteps"`
+	Paused        bool          `json:"paused"`
+	TickTime      time.Duration `json:"tickTime"`
+	TicksPerFrame int           `json:"ticksPerFrame,omitempty"`
+	Rule          string        `json:"rule"` // Rulestring like B3/S23
+	Neighborhood  Neighborhood  `json:"neighborhood"`
+	Generations   int           `json:"generations,omitempty"`
+	Ages          []uint8       `json:"ages,omitempty"` // Row-major cell states, Generations only
+	Wrap          bool          `json:"wrap,omitempty"`
+	Checksums     bool          `json:"checksums,omitempty"`
+	AutoRestart   bool          `json:"autoRestart,omitempty"`
+	RestartAfter  int           `json:"restartAfter,omitempty"`
+	Teams         []uint8       `json:"teams,omitempty"` // Row-major cell teams, team games only
+	SplitFlips    bool          `json:"splitFlips,omitempty"`
+	Range         int           `json:"range,omitempty"`
+	RangeBirth    CountRange    `json:"rangeBirth"`
+	RangeSurvival CountRange    `json:"rangeSurvival"`
+	Board         *PackedBoard  `json:"board"`
+	Walls         *PackedBoard  `json:"walls,omitempty"`
+	WallsAlive    bool          `json:"wallsAlive,omitempty"`
+}
+
+// Snapshot captures the game's current state
+func (s GolState) Snapshot() GameSnapshot {
+	return GameSnapshot{
+		Id:            s.Id,
+		Step:          s.Step,
+		MaxSteps:      s.MaxSteps,
+		Paused:        s.Paused,
+		TickTime:      s.TickTime,
+		TicksPerFrame: s.TicksPerFrame,
+		Rule:          s.Rule().Rulestring(),
+		Neighborhood:  s.Neighborhood,
+		Generations:   s.Generations,
+		Ages:          PackAges(s.Ages),
+		Teams:         PackAges(s.Teams),
+		Wrap:          s.Wrap,
+		Checksums:     s.Checksums,
+		AutoRestart:   s.AutoRestart,
+		RestartAfter:  s.RestartAfter,
+		SplitFlips:    s.SplitFlips,
+		Range:         s.Range,
+		RangeBirth:    s.RangeBirth,
+		RangeSurvival: s.RangeSurvival,
+		Board:         s.Board.Pack(),
+		Walls:         packWalls(s.Walls),
+		WallsAlive:    s.WallsAlive,
+	}
+}
+
+// Validate checks what Init would only find out once the workflow started
+func (s GameSnapshot) Validate() error {
+	if s.Board == nil {
+		return errors.New("snapshot has no board")
+	}
+	if err := s.Board.Validate(); err != nil {
+		return err
+	}
+	if s.Step < 0 {
+		return fmt.Errorf("snapshot step must not be negative, got %d", s.Step)
+	}
+	if s.MaxSteps < 0 || (s.MaxSteps > 0 && s.MaxSteps <= s.Step) {
+		return fmt.Errorf("snapshot max steps must be after its step %d, got %d", s.Step, s.MaxSteps)
+	}
+	if s.TickTime < 0 {
+		return fmt.Errorf("snapshot tick time must not be negative, got %v", s.TickTime)
+	}
+	if s.RestartAfter < 0 {
+		return fmt.Errorf("snapshot restart after must not be negative, got %d", s.RestartAfter)
+	}
+	if err := ValidateTicksPerFrame(s.TicksPerFrame); err != nil {
+		return err
+	}
+	if err := s.Neighborhood.Validate(); err != nil {
+		return err
+	}
+	if s.Rule != "" {
+		if _, _, err := ParseRule(s.Rule); err != nil {
+			return err
+		}
+	}
+	if err := ValidateRange(s.Range, s.RangeBirth, s.RangeSurvival); err != nil {
+		return err
+	}
+	return ValidateGenerations(s.Generations)
+}
+
+// Input returns the workflow input that restores the snapshot, Init checks the rest of it
+func (s GameSnapshot) Input() GameOfLifeInput {
+	return GameOfLifeInput{
+		MaxSteps:      s.MaxSteps,
+		Step:          s.Step,
+		TickTime:      s.TickTime,
+		TicksPerFrame: s.TicksPerFrame,
+		Board:         s.Board,
+		Paused:        s.Paused,
+		Neighborhood:  s.Neighborhood,
+		Rule:          s.Rule,
+		Generations:   s.Generations,
+		Ages:          s.Ages,
+		TeamGame:      s.Teams != nil,
+		Teams:         s.Teams,
+		Walls:         s.Walls,
+		WallsAlive:    s.WallsAlive,
+		Wrap:          s.Wrap,
+		Checksums:     s.Checksums,
+		AutoRestart:   s.AutoRestart,
+		RestartAfter:  s.RestartAfter,
+		SplitFlips:    s.SplitFlips,
+		Range:         s.Range,
+		RangeBirth:    s.RangeBirth,
+		RangeSurvival: s.RangeSurvival,
+	}
+}
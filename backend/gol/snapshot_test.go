@@ -0,0 +1,66 @@
+package gol
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestoreRoundTrip(t *testing.T) {
+	seed := randomBoard(rand.New(rand.NewSource(93)), 24, 24, 0.35)
+	highLife, _, _ := ParseRule("B36/S23")
+	rule := Rule{Birth: highLife, Survival: ConwaySurvival, Wrap: true}
+	// Snapshot the running game, then end it
+	original := newTestGame(t)
+	var snapshot GameSnapshot
+	original.env.RegisterDelayedCallback(func() {
+		original.query(t, "snapshot", &snapshot)
+		original.env.SignalWorkflow(SetMaxStepsSignalName, SetMaxStepsSignal{MaxSteps: snapshot.Step})
+	}, 20*time.Millisecond)
+	original.run(t, GameOfLifeInput{Board: seed.Pack(), Rule: "B36/S23", Wrap: true, MaxSteps: 100, TickTime: 3 * time.Millisecond})
+	if snapshot.Step == 0 || snapshot.Rule != "B36/S23" {
+		t.Fatalf("snapshot is at step %d with rule %s", snapshot.Step, snapshot.Rule)
+	}
+	var ended StateChange
+	original.query(t, "board", &ended)
+	if !equalBoards(snapshot.Board.Unpack(), applyFrame(t, nil, ended)) {
+		t.Error("the snapshot's board isn't the board the game was at")
+	}
+
+	// The snapshot goes through JSON like it does between /snapshot and /restore
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restoredSnapshot GameSnapshot
+	if err := json.Unmarshal(data, &restoredSnapshot); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoredSnapshot.Validate(); err != nil {
+		t.Fatalf("snapshot is invalid: %v", err)
+	}
+
+	const more = 5
+	input := restoredSnapshot.Input()
+	input.MaxSteps = snapshot.Step + more
+	restored := newTestGame(t)
+	restored.run(t, input)
+
+	// The restored game picks up at the snapshot's step with its rule and tick
+	var debug DebugState
+	restored.query(t, "debug", &debug)
+	if debug.Step != snapshot.Step+more || debug.TickTime != 3*time.Millisecond {
+		t.Errorf("restored game is at step %d ticking every %v, want step %d every 3ms", debug.Step, debug.TickTime, snapshot.Step+more)
+	}
+	if first := restored.frames[0]; first.Step != snapshot.Step+1 {
+		t.Errorf("restored game's first frame is step %d, want %d", first.Step, snapshot.Step+1)
+	}
+	want := seed
+	for range snapshot.Step + more {
+		want = NextGeneration(want, rule)
+	}
+	if got := restored.replay(t, restoredSnapshot.Board.Unpack()); !equalBoards(got, want) {
+		t.Errorf("restored game ended on\n%vwant the original's board %d generations in\n%v", got, snapshot.Step+more, want)
+	}
+}
@@ -0,0 +1,52 @@
+package gol
+
+import "sort"
+
+/* -------------------------------------------------------------------------- */
+/*                                  Symmetry                                  */
+/* -------------------------------------------------------------------------- */
+
+// A symmetric board is made by mirroring one half of the random fill onto the other, so
+// the board keeps the density of the fill. On odd dimensions the middle row or column is
+// its own mirror and is left as the fill made it.
+
+const (
+	SymmetryHorizontal = "horizontal" // Left half mirrored onto the right half
+	SymmetryVertical   = "vertical"   // Top half mirrored onto the bottom half
+	SymmetryFourFold   = "4fold"      // Top left quarter mirrored onto the other three
+)
+
+var symmetries = map[string]func(Board){
+	"":                 func(Board) {},
+	SymmetryHorizontal: mirrorHorizontal,
+	SymmetryVertical:   mirrorVertical,
+	SymmetryFourFold:   func(board Board) { mirrorVertical(board); mirrorHorizontal(board) },
+}
+
+// SymmetryNames returns the names of the symmetries in sorted order
+func SymmetryNames() []string {
+	names := make([]string, 0, len(symmetries))
+	for name := range symmetries {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mirrorHorizontal copies the left half of every row onto its right half
+func mirrorHorizontal(board Board) {
+	for _, row := range board {
+		for j := range len(row) / 2 {
+			row[len(row)-1-j] = row[j]
+		}
+	}
+}
+
+// mirrorVertical copies the top half of the rows onto the bottom half
+func mirrorVertical(board Board) {
+	for i := range len(board) / 2 {
+		copy(board[len(board)-1-i], board[i])
+	}
+}
@@ -0,0 +1,63 @@
+package gol
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// mirrored reports whether the board equals its mirror image, across the vertical axis for
+// horizontal symmetry and the horizontal axis for vertical symmetry
+func mirrored(board Board, horizontal bool) bool {
+	rows, cols := len(board), len(board[0])
+	for i := range rows {
+		for j := range cols {
+			mirror := board[rows-1-i][j]
+			if horizontal {
+				mirror = board[i][cols-1-j]
+			}
+			if board[i][j] != mirror {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestSymmetricRandomBoard(t *testing.T) {
+	tests := []struct {
+		symmetry             string
+		horizontal, vertical bool
+	}{
+		{SymmetryHorizontal, true, false},
+		{SymmetryVertical, false, true},
+		{SymmetryFourFold, true, true},
+	}
+	// Odd dimensions have a middle row and column that mirror onto themselves
+	for _, size := range [][2]int{{40, 60}, {41, 61}} {
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("%s %dx%d", test.symmetry, size[0], size[1]), func(t *testing.T) {
+				board, err := AmInstance.GetRandomBoard(context.Background(), GetRandomBoardInput{
+					Length:             size[0],
+					Width:              size[1],
+					RandomBoardOptions: RandomBoardOptions{Fill: FillUniform, Density: 0.3, Symmetry: test.symmetry, Seed: 87},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if mirrored(board, true) != test.horizontal || mirrored(board, false) != test.vertical {
+					t.Errorf("board mirrored left to right %v and top to bottom %v, want %v and %v",
+						mirrored(board, true), mirrored(board, false), test.horizontal, test.vertical)
+				}
+				// Mirroring keeps the fill's density rather than doubling it
+				if density := float64(board.Population()) / float64(size[0]*size[1]); density < 0.25 || density > 0.35 {
+					t.Errorf("density is %.3f, want about the fill's 0.3", density)
+				}
+			})
+		}
+	}
+
+	if err := (RandomBoardOptions{Symmetry: "radial"}).Validate(); err == nil {
+		t.Error("unknown symmetry is valid")
+	}
+}
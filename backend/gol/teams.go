@@ -0,0 +1,139 @@
+package gol
+
+import "fmt"
+
+/* -------------------------------------------------------------------------- */
+/*                                    Teams                                   */
+/* -------------------------------------------------------------------------- */
+
+// A team game gives every cell an owner, team 0 or 1 (e.g. two players sharing a board). A cell
+// that is born joins the team most of its live neighbors were on the generation before, so a
+// glider keeps its team while it travels, and a tie leaves the cell on the team it last had.
+// A random board starts with the left half on team 0 and the right half on team 1, cells
+// placed by a signal join the signal's team. Frames carry the team of each flipped cell.
+
+// Number of teams of a team game
+const NumTeams = 2
+
+// ValidateTeam checks a team sent by a client
+func ValidateTeam(team uint8) error {
+	if team >= NumTeams {
+		return fmt.Errorf("team must be below %d, got %d", NumTeams, team)
+	}
+	return nil
+}
+
+// NewTeams returns the teams of a fresh board, the left half is team 0 and the right half team 1
+func NewTeams(rows, cols int) [][]uint8 {
+	teams := make([][]uint8, rows)
+	for i := range teams {
+		teams[i] = make([]uint8, cols)
+		for j := cols / 2; j < cols; j++ {
+			teams[i][j] = 1
+		}
+	}
+	return teams
+}
+
+// AssignTeams gives each cell born by the flips the majority team of its live neighbors
+// The board already has the flips applied, so a neighbor was alive before them unless it flipped
+func AssignTeams(board Board, teams [][]uint8, rule Rule, flipped [][2]int) {
+	wasFlipped := make(map[[2]int]bool, len(flipped))
+	for _, cell := range flipped {
+		wasFlipped[cell] = true
+	}
+	rows, cols := len(board), len(board[0])
+
+	// Every vote is counted before any team changes, a born cell wasn't alive to vote anyway
+	born := make(map[[2]int]uint8)
+	for _, cell := range flipped {
+		if !board[cell[0]][cell[1]] {
+			continue
+		}
+		var votes [NumTeams]int
+		for x := -1; x <= 1; x++ {
+			for y := -1; y <= 1; y++ {
+				if x == 0 && y == 0 {
+					continue
+				}
+				if rule.Neighborhood == VonNeumann && x != 0 && y != 0 {
+					continue
+				}
+				nx, ny, ok := rule.neighborCell(rows, cols, cell[0]+x, cell[1]+y)
+				if !ok || rule.isWall(nx, ny) {
+					continue
+				}
+				if board[nx][ny] != wasFlipped[[2]int{nx, ny}] {
+					votes[teams[nx][ny]]++
+				}
+			}
+		}
+		switch {
+		case votes[0] > votes[1]:
+			born[cell] = 0
+		case votes[1] > votes[0]:
+			born[cell] = 1
+		}
+	}
+	for cell, team := range born {
+		teams[cell[0]][cell[1]] = team
+	}
+}
+
+// claimCells puts the live cells among the flipped ones on the team
+func (s *GolState) claimCells(flipped [][2]int, team uint8) {
+	if s.Teams == nil {
+		return
+	}
+	for _, cell := range flipped {
+		if s.Board[cell[0]][cell[1]] {
+			s.Teams[cell[0]][cell[1]] = team
+		}
+	}
+}
+
+// cellTeams returns the team of each of the cells, nil for games without teams
+func (s GolState) cellTeams(cells [][2]int) []uint8 {
+	if s.Teams == nil {
+		return nil
+	}
+	teams := make([]uint8, len(cells))
+	for i, cell := range cells {
+		teams[i] = s.Teams[cell[0]][cell[1]]
+	}
+	return teams
+}
+
+// mergeTeams returns the teams of the merged flipped cells, the newer frame's team wins
+func mergeTeams(older, newer StateChange, flipped [][2]int) []uint8 {
+	byCell := make(map[[2]int]uint8, len(older.Flipped)+len(newer.Flipped))
+	for _, change := range []StateChange{older, newer} {
+		for i, cell := range change.Flipped {
+			if i < len(change.Teams) {
+				byCell[cell] = change.Teams[i]
+			}
+		}
+	}
+
+	teams := make([]uint8, len(flipped))
+	for i, cell := range flipped {
+		teams[i] = byCell[cell]
+	}
+	return teams
+}
+
+// TeamPopulations counts the live cells of each team, nil for games without teams
+func TeamPopulations(board Board, teams [][]uint8) []int {
+	if teams == nil {
+		return nil
+	}
+	populations := make([]int, NumTeams)
+	for i := range board {
+		for j, alive := range board[i] {
+			if alive {
+				populations[teams[i][j]]++
+			}
+		}
+	}
+	return populations
+}
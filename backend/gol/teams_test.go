@@ -0,0 +1,67 @@
+package gol
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestContestedBirthJoinsTheMajority(t *testing.T) {
+	// (1, 1) is born from two neighbors of team 1 and one of team 0
+	board := parseBoard(
+		"#.#..",
+		".....",
+		".#...",
+	)
+	teams := NewTeams(3, 5)
+	teams[0][0], teams[0][2], teams[2][1] = 1, 1, 0
+	next := NextGeneration(board, Rule{})
+	AssignTeams(next, teams, Rule{}, DiffFlipped(board, next))
+	if !next[1][1] || teams[1][1] != 1 {
+		t.Errorf("contested cell is alive %v on team %d, want born on team 1", next[1][1], teams[1][1])
+	}
+
+	// The other way around it joins team 0
+	teams = NewTeams(3, 5)
+	teams[0][0], teams[0][2], teams[2][1] = 0, 0, 1
+	AssignTeams(next, teams, Rule{}, DiffFlipped(board, next))
+	if teams[1][1] != 0 {
+		t.Errorf("contested cell joined team %d, want team 0", teams[1][1])
+	}
+}
+
+func TestGliderKeepsItsTeam(t *testing.T) {
+	// The glider starts on the left half, team 0, and travels well into the right half
+	seed := emptyBoard(20, 20)
+	glider, _ := Pattern("glider")
+	seed.Stamp(glider, 1, 6)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), TeamGame: true, MaxSteps: 40, StoreInterval: 100, TickTime: time.Millisecond})
+
+	// Frames carry the team of each flipped cell
+	teams := NewTeams(20, 20)
+	board := seed.Clone()
+	for _, frame := range game.frames {
+		if len(frame.Teams) != len(frame.Flipped) {
+			t.Fatalf("step %d has %d teams for %d flipped cells", frame.Step, len(frame.Teams), len(frame.Flipped))
+		}
+		for i, cell := range frame.Flipped {
+			teams[cell[0]][cell[1]] = frame.Teams[i]
+		}
+		board = applyFrame(t, board, frame)
+	}
+	if !equalBoards(board, evolve(seed, 40)) {
+		t.Fatalf("team game ended on\n%vwant\n%v", board, evolve(seed, 40))
+	}
+	for _, cell := range board.LiveCells() {
+		if cell[1] < 10 {
+			t.Errorf("glider cell %v is still on the left half", cell)
+		}
+		if teams[cell[0]][cell[1]] != 0 {
+			t.Errorf("glider cell %v is on team %d", cell, teams[cell[0]][cell[1]])
+		}
+	}
+	if last := game.frames[len(game.frames)-1]; fmt.Sprint(last.TeamPopulations) != "[5 0]" {
+		t.Errorf("last frame has team populations %v, want [5 0]", last.TeamPopulations)
+	}
+}
@@ -0,0 +1,98 @@
+package gol
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                   Tracing                                  */
+/* -------------------------------------------------------------------------- */
+
+// A trace id follows a game from the request that started it through every run and activity
+// of its workflow into the frames it streams, so a frame can be matched with the request and
+// the activity logs behind it. TracePropagator carries the id in temporal's headers, which go
+// along with workflow starts, continue-as-new and every activity DoActivity executes. A game
+// started without one traces with the run id of its first run.
+
+// Header the trace id is carried in, both in HTTP requests and temporal headers
+const TraceHeader = "X-Trace-Id"
+
+type traceKey struct{}
+
+// WithTraceId returns a context carrying the trace id, workflows started with it inherit it
+func WithTraceId(ctx context.Context, traceId string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceId)
+}
+
+// TraceId returns the trace id the context carries, "" when it has none
+func TraceId(ctx context.Context) string {
+	traceId, _ := ctx.Value(traceKey{}).(string)
+	return traceId
+}
+
+// workflowTraceId returns the trace id the workflow context carries, "" when it has none
+func workflowTraceId(ctx workflow.Context) string {
+	traceId, _ := ctx.Value(traceKey{}).(string)
+	return traceId
+}
+
+// withWorkflowTrace makes sure the workflow context carries a trace id, the run id when the start had none
+func withWorkflowTrace(ctx workflow.Context) workflow.Context {
+	if workflowTraceId(ctx) != "" {
+		return ctx
+	}
+	return workflow.WithValue(ctx, traceKey{}, workflow.GetInfo(ctx).WorkflowExecution.RunID)
+}
+
+// Carries the trace id through temporal's headers, set in the client options of both the
+// client starting games and the worker running them
+type TracePropagator struct{}
+
+func (TracePropagator) Inject(ctx context.Context, writer workflow.HeaderWriter) error {
+	return injectTrace(TraceId(ctx), writer)
+}
+
+func (TracePropagator) InjectFromWorkflow(ctx workflow.Context, writer workflow.HeaderWriter) error {
+	return injectTrace(workflowTraceId(ctx), writer)
+}
+
+func (TracePropagator) Extract(ctx context.Context, reader workflow.HeaderReader) (context.Context, error) {
+	traceId, err := extractTrace(reader)
+	if err != nil || traceId == "" {
+		return ctx, err
+	}
+	return WithTraceId(ctx, traceId), nil
+}
+
+func (TracePropagator) ExtractToWorkflow(ctx workflow.Context, reader workflow.HeaderReader) (workflow.Context, error) {
+	traceId, err := extractTrace(reader)
+	if err != nil || traceId == "" {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, traceKey{}, traceId), nil
+}
+
+func injectTrace(traceId string, writer workflow.HeaderWriter) error {
+	if traceId == "" {
+		return nil
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(traceId)
+	if err != nil {
+		return err
+	}
+	writer.Set(TraceHeader, payload)
+	return nil
+}
+
+func extractTrace(reader workflow.HeaderReader) (string, error) {
+	payload, ok := reader.Get(TraceHeader)
+	if !ok {
+		return "", nil
+	}
+	var traceId string
+	err := converter.GetDefaultDataConverter().FromPayload(payload, &traceId)
+	return traceId, err
+}
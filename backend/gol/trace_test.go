@@ -0,0 +1,81 @@
+package gol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// runTraced runs a short game started with the trace id, "" for none, and returns the trace id
+// each SendState activity ran with
+func runTraced(t *testing.T, traceId string) []string {
+	t.Helper()
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(GameOfLife)
+	env.RegisterActivity(AmInstance)
+	game := &testGame{env: env}
+	game.env.SetContextPropagators([]workflow.ContextPropagator{TracePropagator{}})
+	if traceId != "" {
+		payload, err := converter.GetDefaultDataConverter().ToPayload(traceId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.env.SetHeader(&commonpb.Header{Fields: map[string]*commonpb.Payload{TraceHeader: payload}})
+	}
+	var traced []string
+	game.env.OnActivity(AmInstance.SendState, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, state StateChange) (SendStateResult, error) {
+			traced = append(traced, TraceId(ctx))
+			return SendStateResult{}, nil
+		})
+	glider, _ := Pattern("glider")
+	board := emptyBoard(10, 10)
+	board.Stamp(glider, 1, 1)
+	game.run(t, GameOfLifeInput{Board: board.Pack(), MaxSteps: 3, TickTime: time.Millisecond})
+	return traced
+}
+
+func TestTraceIdReachesEveryFrame(t *testing.T) {
+	for _, test := range []struct {
+		name, traceId, want string
+	}{
+		{"from the start request", "request-1234", "request-1234"},
+		{"defaults to the run id", "", "default-test-run-id"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			traced := runTraced(t, test.traceId)
+			if len(traced) == 0 {
+				t.Fatal("no frame was published")
+			}
+			for i, traceId := range traced {
+				if traceId != test.want {
+					t.Errorf("frame %d was published with trace id %q, want %q", i+1, traceId, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSendStateStampsTheTraceId(t *testing.T) {
+	states, unsubscribe := StateStream.Subscribe("traced")
+	defer unsubscribe()
+	ctx := WithTraceId(context.Background(), "request-1234")
+	if _, err := AmInstance.SendState(ctx, StateChange{Id: "traced", Seq: 1, Step: 1}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case state := <-states:
+		if state.TraceId != "request-1234" {
+			t.Errorf("frame was streamed with trace id %q", state.TraceId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no frame was streamed")
+	}
+}
@@ -0,0 +1,72 @@
+package gol
+
+/* -------------------------------------------------------------------------- */
+/*                                    Walls                                   */
+/* -------------------------------------------------------------------------- */
+
+// Walls are permanent cells that are neither alive nor dead. A wall cell never changes state
+// and (unless Rule.WallsAlive is set) doesn't count as a live neighbor, so patterns can't
+// grow through it. The walls are a layer next to the board, nil until the first wall is set.
+
+// isWall reports whether the cell is a wall under the rule
+func (r Rule) isWall(i, j int) bool {
+	return r.Walls != nil && r.Walls[i][j]
+}
+
+// SetWalls adds (or removes) walls on the cells, cells outside the board are ignored
+// A live cell turned into a wall dies, the returned killed cells are flipped on the board
+// Returns the cells whose wall changed and the cells that died
+func (s *GolState) SetWalls(cells [][2]int, wall bool) (changed [][2]int, killed [][2]int) {
+	if s.Walls == nil {
+		if !wall {
+			return nil, nil
+		}
+		s.Walls = make(Board, len(s.Board))
+		for i := range s.Walls {
+			s.Walls[i] = make([]bool, len(s.Board[i]))
+		}
+	}
+
+	for _, cell := range cells {
+		i, j := cell[0], cell[1]
+		if i < 0 || i >= len(s.Walls) || j < 0 || j >= len(s.Walls[i]) || s.Walls[i][j] == wall {
+			continue
+		}
+		s.Walls[i][j] = wall
+		changed = append(changed, cell)
+
+		if wall && s.Board[i][j] {
+			s.Board[i][j] = false
+			killed = append(killed, cell)
+		}
+		if wall && s.Ages != nil {
+			s.Ages[i][j] = 0
+		}
+	}
+	return changed, killed
+}
+
+// packWalls packs the wall layer for continue-as-new, nil stays nil
+func packWalls(walls Board) *PackedBoard {
+	if walls == nil {
+		return nil
+	}
+	return walls.Pack()
+}
+
+// keepOffWalls undoes the flips that landed on walls and returns the rest
+// Edits that don't know about walls (splatters, patterns) go through it so walls stay empty
+func (s *GolState) keepOffWalls(flipped [][2]int) [][2]int {
+	if s.Walls == nil {
+		return flipped
+	}
+	kept := flipped[:0]
+	for _, cell := range flipped {
+		if s.Walls[cell[0]][cell[1]] {
+			s.Board[cell[0]][cell[1]] = !s.Board[cell[0]][cell[1]]
+			continue
+		}
+		kept = append(kept, cell)
+	}
+	return kept
+}
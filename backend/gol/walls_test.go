@@ -0,0 +1,104 @@
+package gol
+
+import (
+	"testing"
+	"time"
+)
+
+// gliderTowardsWall returns a glider heading down and right on a board with the given width
+// and the cells of a wall across the column it's heading to
+func gliderTowardsWall(width int, wallCol int) (Board, [][2]int) {
+	board := emptyBoard(width, width)
+	glider, _ := Pattern("glider")
+	board.Stamp(glider, 1, 1)
+	wall := make([][2]int, width)
+	for row := range wall {
+		wall[row] = [2]int{row, wallCol}
+	}
+	return board, wall
+}
+
+// pastColumn returns the live cells at or right of the column
+func pastColumn(board Board, col int) [][2]int {
+	var past [][2]int
+	for _, cell := range board.LiveCells() {
+		if cell[1] >= col {
+			past = append(past, cell)
+		}
+	}
+	return past
+}
+
+func TestWallBlocksPropagation(t *testing.T) {
+	const width, wallCol, generations = 24, 10, 80
+	seed, cells := gliderTowardsWall(width, wallCol)
+	walls := emptyBoard(width, width)
+	walls.Toggle(cells)
+
+	// Without the wall the glider crosses the column
+	board := seed.Clone()
+	for range generations / 2 {
+		board = NextGeneration(board, Rule{})
+	}
+	if len(pastColumn(board, wallCol)) == 0 {
+		t.Fatalf("the glider never reached column %d:\n%v", wallCol, board)
+	}
+
+	// With it nothing ever gets past
+	board = seed.Clone()
+	for generation := 1; generation <= generations; generation++ {
+		board = NextGeneration(board, Rule{Walls: walls})
+		if past := pastColumn(board, wallCol); len(past) > 0 {
+			t.Fatalf("generation %d has live cells %v on or past the wall", generation, past)
+		}
+	}
+}
+
+func TestWallsAliveCountAsNeighbors(t *testing.T) {
+	// Three walls in a row give the cells next to the middle one three live neighbors
+	walls := parseBoard(
+		".....",
+		".###.",
+		".....",
+	)
+	board := NextGeneration(emptyBoard(3, 5), Rule{Walls: walls, WallsAlive: true})
+	if !board[0][2] || !board[2][2] {
+		t.Errorf("cells next to three live walls weren't born:\n%v", board)
+	}
+	if board[1][2] {
+		t.Error("a wall came alive")
+	}
+	if board = NextGeneration(emptyBoard(3, 5), Rule{Walls: walls}); len(board.LiveCells()) != 0 {
+		t.Errorf("walls that block counted as neighbors:\n%v", board)
+	}
+}
+
+func TestSetWallSignalBlocksTheGlider(t *testing.T) {
+	const width, wallCol = 24, 10
+	seed, cells := gliderTowardsWall(width, wallCol)
+
+	game := newTestGame(t)
+	game.env.RegisterDelayedCallback(func() {
+		game.env.SignalWorkflow(SetWallSignalName, SetWallSignal{Cells: cells, Wall: true})
+	}, 5*time.Millisecond)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), MaxSteps: 100, KeyframeInterval: 10, TickTime: 10 * time.Millisecond})
+
+	board := seed.Clone()
+	keyframes := 0
+	for _, frame := range game.frames {
+		board = applyFrame(t, board, frame)
+		if past := pastColumn(board, wallCol); len(past) > 0 {
+			t.Fatalf("step %d has live cells %v on or past the wall", frame.Step, past)
+		}
+		// Full frames carry the walls for clients that (re)connect
+		if frame.Full && frame.Step > 0 {
+			keyframes++
+			if len(frame.Walls) != len(cells) {
+				t.Errorf("keyframe of step %d has %d walls, want %d", frame.Step, len(frame.Walls), len(cells))
+			}
+		}
+	}
+	if keyframes == 0 {
+		t.Error("the game published no keyframe after the wall was set")
+	}
+}
@@ -0,0 +1,123 @@
+package gol
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Wrapped Edges                               */
+/* -------------------------------------------------------------------------- */
+
+// A wrapped board is a torus, the top edge neighbors the bottom edge and the left edge the
+// right one (so a corner neighbors the three other corners). A glider leaving the board
+// comes back in on the opposite side instead of dying against the edge. Placed patterns
+// wrap the same way, so a glider can be placed straddling an edge.
+
+// wrapIndex maps i onto [0, n), Go's % keeps the sign of i so -1 would stay -1
+func wrapIndex(i, n int) int {
+	return (i%n + n) % n
+}
+
+// neighborCell returns where the cell at (i, j) is on a rows x cols board
+// Off the edge it wraps around when the rule wraps, otherwise it reports false
+func (r Rule) neighborCell(rows, cols, i, j int) (int, int, bool) {
+	if r.Wrap {
+		return wrapIndex(i, rows), wrapIndex(j, cols), true
+	}
+	if i < 0 || i >= rows || j < 0 || j >= cols {
+		return 0, 0, false
+	}
+	return i, j, true
+}
+
+// StampWrapped is Stamp with the part falling outside the board wrapped around to the other side
+// Returns the cells that flipped in row-major order
+func (b Board) StampWrapped(pattern Board, row, col int) [][2]int {
+	if len(b) == 0 {
+		return nil
+	}
+	rows, cols := len(b), len(b[0])
+
+	// A pattern bigger than the board would overlap itself, the last cell stamped wins
+	cells := make(map[[2]int]bool)
+	for i, line := range pattern {
+		for j, alive := range line {
+			cells[[2]int{wrapIndex(row+i, rows), wrapIndex(col+j, cols)}] = alive
+		}
+	}
+
+	var flipped [][2]int
+	for cell, alive := range cells {
+		if b[cell[0]][cell[1]] != alive {
+			b[cell[0]][cell[1]] = alive
+			flipped = append(flipped, cell)
+		}
+	}
+	sort.Slice(flipped, func(a, b int) bool {
+		if flipped[a][0] != flipped[b][0] {
+			return flipped[a][0] < flipped[b][0]
+		}
+		return flipped[a][1] < flipped[b][1]
+	})
+	return flipped
+}
+
+/* -------------------------------- Headings -------------------------------- */
+
+// The built-in patterns are drawn heading down and to the right (a glider moves one cell
+// diagonally towards se every 4 generations), mirroring them points them the other ways
+const (
+	HeadingSE = "se"
+	HeadingSW = "sw"
+	HeadingNE = "ne"
+	HeadingNW = "nw"
+)
+
+// Whether a heading mirrors the pattern's columns and rows, "" keeps it as drawn
+var headings = map[string][2]bool{
+	"":        {false, false},
+	HeadingSE: {false, false},
+	HeadingSW: {true, false},
+	HeadingNE: {false, true},
+	HeadingNW: {true, true},
+}
+
+// HeadingNames returns the names of the headings in sorted order
+func HeadingNames() []string {
+	names := make([]string, 0, len(headings))
+	for name := range headings {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateHeading(heading string) error {
+	if _, ok := headings[heading]; !ok {
+		return fmt.Errorf("unknown heading %q, valid headings are: %s", heading, strings.Join(HeadingNames(), ", "))
+	}
+	return nil
+}
+
+// Headed returns a copy of the pattern mirrored to travel towards the heading
+func Headed(pattern Board, heading string) Board {
+	mirror := headings[heading]
+	headed := make(Board, len(pattern))
+	for i, line := range pattern {
+		if mirror[1] {
+			line = pattern[len(pattern)-1-i]
+		}
+		headed[i] = make([]bool, len(line))
+		for j, alive := range line {
+			if mirror[0] {
+				j = len(line) - 1 - j
+			}
+			headed[i][j] = alive
+		}
+	}
+	return headed
+}
@@ -0,0 +1,101 @@
+package gol
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// shiftWrapped returns the board moved by (dRow, dCol), with what leaves one side coming back on the other
+func shiftWrapped(board Board, dRow, dCol int) Board {
+	rows, cols := len(board), len(board[0])
+	shifted := emptyBoard(rows, cols)
+	for _, cell := range board.LiveCells() {
+		shifted[wrapIndex(cell[0]+dRow, rows)][wrapIndex(cell[1]+dCol, cols)] = true
+	}
+	return shifted
+}
+
+// gliderBoard returns a rows x cols board with a glider heading the given way at (row, col)
+// and the direction it travels in, one cell diagonally every 4 generations
+func gliderBoard(t *testing.T, rows, cols, row, col int, heading string) (Board, [2]int) {
+	t.Helper()
+	glider, ok := Pattern("glider")
+	if !ok {
+		t.Fatal("no glider pattern")
+	}
+	board := emptyBoard(rows, cols)
+	board.StampWrapped(Headed(glider, heading), row, col)
+	mirror := headings[heading]
+	direction := [2]int{1, 1}
+	if mirror[0] {
+		direction[1] = -1
+	}
+	if mirror[1] {
+		direction[0] = -1
+	}
+	return board, direction
+}
+
+func TestGliderWrapsAcrossEveryEdge(t *testing.T) {
+	const rows, cols, periods = 10, 12, 6
+	// Each glider starts next to the corner it heads for, in 6 periods it crosses both of its edges
+	starts := map[string][2]int{
+		HeadingSE: {6, 8},
+		HeadingSW: {6, 1},
+		HeadingNE: {1, 8},
+		HeadingNW: {1, 1},
+	}
+	for heading, start := range starts {
+		t.Run(heading, func(t *testing.T) {
+			board, direction := gliderBoard(t, rows, cols, start[0], start[1], heading)
+			seed := board
+			for period := 1; period <= periods; period++ {
+				for range 4 {
+					board = NextGeneration(board, Rule{Wrap: true})
+				}
+				if want := shiftWrapped(seed, period*direction[0], period*direction[1]); !equalBoards(board, want) {
+					t.Fatalf("after %d generations the board is\n%vwant\n%v", 4*period, board, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapIndex(t *testing.T) {
+	for _, test := range []struct{ i, n, want int }{
+		{0, 5, 0}, {4, 5, 4}, {5, 5, 0}, {-1, 5, 4}, {-5, 5, 0}, {-6, 5, 4}, {11, 5, 1},
+	} {
+		if got := wrapIndex(test.i, test.n); got != test.want {
+			t.Errorf("wrapIndex(%d, %d) = %d, want %d", test.i, test.n, got, test.want)
+		}
+	}
+}
+
+func TestCornersNeighborEachOther(t *testing.T) {
+	// A block split over the four corners is a still life on a wrapped board and dies on a bounded one
+	board := emptyBoard(6, 6)
+	board.Toggle([][2]int{{0, 0}, {0, 5}, {5, 0}, {5, 5}})
+	if next := NextGeneration(board, Rule{Wrap: true}); !equalBoards(next, board) {
+		t.Errorf("wrapped corner block became\n%v", next)
+	}
+	if next := NextGeneration(board, Rule{}); next.Population() != 0 {
+		t.Errorf("bounded corner cells became\n%v", next)
+	}
+}
+
+func TestWrappedGameMatchesTheShiftedGlider(t *testing.T) {
+	seed, direction := gliderBoard(t, 10, 12, 1, 1, HeadingNW)
+	game := newTestGame(t)
+	game.run(t, GameOfLifeInput{Board: seed.Pack(), Wrap: true, MaxSteps: 24, TickTime: time.Millisecond})
+
+	want := shiftWrapped(seed, 6*direction[0], 6*direction[1])
+	if got := game.replay(t, seed); !equalBoards(got, want) {
+		t.Errorf("the game's frames replay to\n%vwant\n%v", got, want)
+	}
+	var board StateChange
+	game.query(t, "board", &board)
+	if got := applyFrame(t, nil, board); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("the board query returned\n%vwant\n%v", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"backend/gol"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Games History                               */
+/* -------------------------------------------------------------------------- */
+
+// Finished games are listed from temporal's visibility, which keeps them until the namespace's
+// retention removes them. A game that continued as new closes a run per store interval, only
+// its last run (the one that actually ended) is listed.
+
+// Statuses a finished game can have, keyed by their name in /games/history
+var closedStatuses = map[string]enums.WorkflowExecutionStatus{
+	"completed":  enums.WORKFLOW_EXECUTION_STATUS_COMPLETED,
+	"terminated": enums.WORKFLOW_EXECUTION_STATUS_TERMINATED,
+	"failed":     enums.WORKFLOW_EXECUTION_STATUS_FAILED,
+	"timedOut":   enums.WORKFLOW_EXECUTION_STATUS_TIMED_OUT,
+	"canceled":   enums.WORKFLOW_EXECUTION_STATUS_CANCELED,
+}
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// Query parameters of /games/history
+type HistoryRequest struct {
+	Status string    // One of closedStatuses, empty lists every finished game
+	Since  time.Time // Games closed at or after, zero for no bound
+	Until  time.Time // Games closed before, zero for no bound
+	Limit  int
+	Page   []byte // NextPageToken of the previous page
+}
+
+// A finished game listed by /games/history
+type FinishedGame struct {
+	Id        string    `json:"id"`
+	RunId     string    `json:"runId"`
+	Status    string    `json:"status"`
+	Steps     int       `json:"steps,omitempty"` // Steps the game ran, from the archive, 0 once it was evicted
+	StartTime time.Time `json:"startTime"`       // Start of the last run
+	CloseTime time.Time `json:"closeTime"`
+}
+
+// A page of /games/history
+type GamesHistory struct {
+	Games    []FinishedGame `json:"games"`
+	NextPage string         `json:"nextPage,omitempty"` // Passed as ?page= for the next page, empty on the last one
+}
+
+// parseHistoryRequest reads the query parameters of /games/history
+func parseHistoryRequest(r *http.Request) (HistoryRequest, error) {
+	query := r.URL.Query()
+	request := HistoryRequest{Status: query.Get("status"), Limit: defaultHistoryLimit}
+
+	if _, ok := closedStatuses[request.Status]; !ok && request.Status != "" {
+		return HistoryRequest{}, fmt.Errorf("unknown status %q, valid statuses are: %s", request.Status, strings.Join(closedStatusNames(), ", "))
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if request.Limit, err = strconv.Atoi(limit); err != nil || request.Limit < 1 || request.Limit > maxHistoryLimit {
+			return HistoryRequest{}, fmt.Errorf("limit must be between 1 and %d, got %q", maxHistoryLimit, limit)
+		}
+	}
+	for name, bound := range map[string]*time.Time{"since": &request.Since, "until": &request.Until} {
+		if value := query.Get(name); value != "" {
+			var err error
+			if *bound, err = time.Parse(time.RFC3339, value); err != nil {
+				return HistoryRequest{}, fmt.Errorf("%s must be an RFC 3339 time: %w", name, err)
+			}
+		}
+	}
+	if page := query.Get("page"); page != "" {
+		var err error
+		if request.Page, err = base64.URLEncoding.DecodeString(page); err != nil {
+			return HistoryRequest{}, fmt.Errorf("invalid page: %w", err)
+		}
+	}
+	return request, nil
+}
+
+// Query returns the visibility query listing the requested games
+// Not every visibility store supports ORDER BY, they all list the most recent games first
+func (h HistoryRequest) Query() string {
+	conditions := []string{"WorkflowType = 'GameOfLife'"}
+	if h.Status != "" {
+		// Visibility names statuses like the enum's String, e.g. TimedOut
+		conditions = append(conditions, fmt.Sprintf("ExecutionStatus = '%s'", closedStatuses[h.Status]))
+	} else {
+		conditions = append(conditions, "ExecutionStatus != 'Running'", "ExecutionStatus != 'ContinuedAsNew'")
+	}
+	if !h.Since.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("CloseTime >= '%s'", h.Since.UTC().Format(time.RFC3339)))
+	}
+	if !h.Until.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("CloseTime < '%s'", h.Until.UTC().Format(time.RFC3339)))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// statusName returns the /games/history name of the status
+func statusName(status enums.WorkflowExecutionStatus) string {
+	for name, closed := range closedStatuses {
+		if closed == status {
+			return name
+		}
+	}
+	return strings.ToLower(status.String())
+}
+
+// closedStatusNames returns the names of the statuses in sorted order
+func closedStatusNames() []string {
+	names := make([]string, 0, len(closedStatuses))
+	for name := range closedStatuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GamesHistory lists finished games, most recent first
+// Url is like /games/history?status=completed&limit=20&since=...&until=...&page=...
+// status is one of completed, terminated, failed, timedOut or canceled and defaults to all of them,
+// since and until bound the close time (RFC 3339) and page is the nextPage of the previous response
+func (c *TemporalClient) GamesHistory(w http.ResponseWriter, r *http.Request) {
+	request, err := parseHistoryRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := c.ListWorkflow(r.Context(), &workflowservice.ListWorkflowExecutionsRequest{
+		PageSize:      int32(request.Limit),
+		NextPageToken: request.Page,
+		Query:         request.Query(),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	history := GamesHistory{Games: make([]FinishedGame, 0, len(response.Executions))}
+	for _, execution := range response.Executions {
+		game := FinishedGame{
+			Id:        execution.Execution.WorkflowId,
+			RunId:     execution.Execution.RunId,
+			Status:    statusName(execution.Status),
+			StartTime: execution.StartTime.AsTime(),
+			CloseTime: execution.CloseTime.AsTime(),
+		}
+
+		// The archive is keyed by id, an entry from before this run is another game's
+		if archived, ok, err := gol.Archive.Get(game.Id); err == nil && ok && !archived.ArchivedAt.Before(game.StartTime) {
+			game.Steps = archived.Step
+		}
+		history.Games = append(history.Games, game)
+	}
+	if len(response.NextPageToken) > 0 {
+		history.NextPage = base64.URLEncoding.EncodeToString(response.NextPageToken)
+	}
+	writeJSON(w, http.StatusOK, history)
+}
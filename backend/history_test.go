@@ -0,0 +1,129 @@
+package main
+
+import (
+	"backend/gol"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestHistoryQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"every finished game", "/games/history", "WorkflowType = 'GameOfLife' AND ExecutionStatus != 'Running' AND ExecutionStatus != 'ContinuedAsNew'"},
+		{"completed", "/games/history?status=completed", "WorkflowType = 'GameOfLife' AND ExecutionStatus = 'Completed'"},
+		{"terminated", "/games/history?status=terminated", "WorkflowType = 'GameOfLife' AND ExecutionStatus = 'Terminated'"},
+		{"failed", "/games/history?status=failed", "WorkflowType = 'GameOfLife' AND ExecutionStatus = 'Failed'"},
+		{"timed out", "/games/history?status=timedOut", "WorkflowType = 'GameOfLife' AND ExecutionStatus = 'TimedOut'"},
+		{
+			"time range",
+			"/games/history?status=completed&since=2024-10-18T12:00:00Z&until=2024-10-19T14:00:00%2B02:00",
+			"WorkflowType = 'GameOfLife' AND ExecutionStatus = 'Completed' AND CloseTime >= '2024-10-18T12:00:00Z' AND CloseTime < '2024-10-19T12:00:00Z'",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := parseHistoryRequest(httptest.NewRequest(http.MethodGet, test.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query := request.Query(); query != test.want {
+				t.Errorf("query = %q, want %q", query, test.want)
+			}
+		})
+	}
+}
+
+func TestGamesHistory(t *testing.T) {
+	started := time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC)
+	closed := started.Add(time.Hour)
+	execution := func(id string, status enums.WorkflowExecutionStatus) *workflowpb.WorkflowExecutionInfo {
+		return &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: id, RunId: "run-" + id},
+			Status:    status,
+			StartTime: timestamppb.New(started),
+			CloseTime: timestamppb.New(closed),
+		}
+	}
+	// Only the completed game's archive entry is from its own run
+	gol.Archive.Put(gol.ArchivedGame{Id: "history-completed", Step: 420, ArchivedAt: closed})
+	gol.Archive.Put(gol.ArchivedGame{Id: "history-failed", Step: 12, ArchivedAt: started.Add(-time.Hour)})
+
+	var listed *workflowservice.ListWorkflowExecutionsRequest
+	fake := &fakeTemporal{listExecutions: func(ctx context.Context, request *workflowservice.ListWorkflowExecutionsRequest) (*workflowservice.ListWorkflowExecutionsResponse, error) {
+		listed = request
+		return &workflowservice.ListWorkflowExecutionsResponse{
+			Executions: []*workflowpb.WorkflowExecutionInfo{
+				execution("history-completed", enums.WORKFLOW_EXECUTION_STATUS_COMPLETED),
+				execution("history-terminated", enums.WORKFLOW_EXECUTION_STATUS_TERMINATED),
+				execution("history-failed", enums.WORKFLOW_EXECUTION_STATUS_FAILED),
+			},
+			NextPageToken: []byte("page 3"),
+		}, nil
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+
+	page := base64.URLEncoding.EncodeToString([]byte("page 2"))
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/games/history?limit=3&page="+page, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("history responded %d: %s", recorder.Code, recorder.Body)
+	}
+	if listed.PageSize != 3 || string(listed.NextPageToken) != "page 2" {
+		t.Errorf("listed %d games from page %q, want 3 from the page token", listed.PageSize, listed.NextPageToken)
+	}
+
+	var history GamesHistory
+	if err := json.NewDecoder(recorder.Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+	want := []FinishedGame{
+		{Id: "history-completed", RunId: "run-history-completed", Status: "completed", Steps: 420, StartTime: started, CloseTime: closed},
+		{Id: "history-terminated", RunId: "run-history-terminated", Status: "terminated", StartTime: started, CloseTime: closed},
+		{Id: "history-failed", RunId: "run-history-failed", Status: "failed", StartTime: started, CloseTime: closed},
+	}
+	if len(history.Games) != len(want) {
+		t.Fatalf("listed %d games, want %d", len(history.Games), len(want))
+	}
+	for i, game := range history.Games {
+		if game.Id != want[i].Id || game.RunId != want[i].RunId || game.Status != want[i].Status || game.Steps != want[i].Steps ||
+			!game.StartTime.Equal(want[i].StartTime) || !game.CloseTime.Equal(want[i].CloseTime) {
+			t.Errorf("game %d is %+v, want %+v", i, game, want[i])
+		}
+	}
+	if next, _ := base64.URLEncoding.DecodeString(history.NextPage); string(next) != "page 3" {
+		t.Errorf("next page is %q, want the token of page 3", history.NextPage)
+	}
+}
+
+func TestGamesHistoryRejectsBadParameters(t *testing.T) {
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(&fakeTemporal{}), mux)
+	for _, path := range []string{
+		"/games/history?status=running",
+		"/games/history?limit=0",
+		"/games/history?limit=101",
+		"/games/history?since=yesterday",
+		"/games/history?page=not*base64",
+	} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s responded %d, want 400", path, recorder.Code)
+		}
+	}
+}
@@ -1,19 +1,54 @@
 package main
 
 import (
+	"backend/gol"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
 var (
-	temporalPort = "7233"
-	taskQueue    = "gol"
+	config          = DefaultConfig()
+	shutdownTimeout = 10 * time.Second
 )
 
 func main() {
+	var err error
+	config, err = ParseConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		// ParseConfig already printed the error
+		os.Exit(2)
+	}
+
+	// Set before anything is published
+	gol.ScrollbackSize = config.ScrollbackSize
+
+	if config.RedisAddr != "" {
+		publisher := gol.NewRedisPublisher(config.RedisAddr)
+		defer publisher.Close()
+		// Only redis can fail, the in-memory publisher is used as is
+		gol.StateStream = gol.NewBreakerPublisher(publisher, config.BreakerFailures, config.BreakerCooldown)
+	}
+
+	// Cancelled on SIGINT/SIGTERM to start a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Connect to the temporal server
-	temporalClient, err := NewTemporalClient("localhost:"+temporalPort, taskQueue)
+	temporalClient, err := NewTemporalClient(config.TemporalHost, config.TaskQueue, config.MaxActivities)
 	if err != nil {
 		log.Fatalf("Failed to create temporal client: %v", err)
 	}
@@ -28,19 +63,120 @@ func main() {
 	// Handle endpoints from the front end
 	log.Println("Handling endpoints")
 	handleEndpoints(temporalClient, mux)
-	select {}
+
+	server := newHTTPServer(ctx, config.HTTPAddr, mux)
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to serve http: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shutdown http server: %v", err)
+	}
+}
+
+// newHTTPServer returns the server for the endpoints, cancelling ctx ends its in-flight requests
+func newHTTPServer(ctx context.Context, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:    addr,
+		Handler: handler,
+		// Requests inherit the shutdown context so long-lived SSE streams see ctx.Done()
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
 }
 
 func handleEndpoints(temporalClient TemporalClientInterface, mux *http.ServeMux) {
+	// Only signals are limited, they are what grows a game's history, over HTTP and WebSockets alike
+	signalLimiter := NewRateLimiter(config.SignalRate, config.SignalBurst)
+
+	mux.HandleFunc("/health", WrapHandler(temporalClient.Health))
 	mux.HandleFunc("/start", WrapHandler(temporalClient.StartGameOfLife))
 	mux.HandleFunc("/state", WrapHandler(temporalClient.GetState))
-	mux.HandleFunc("/signal/", WrapHandler(temporalClient.SendSignal))
-	http.ListenAndServe(":8080", mux)
+	mux.HandleFunc("/state/{id}", WrapHandler(temporalClient.GetState))
+	mux.HandleFunc("/signal/{name}", WrapHandler(signalLimiter.Limit(temporalClient.SendSignal)))
+	mux.HandleFunc("/signal/{id}/{name}", WrapHandler(signalLimiter.Limit(temporalClient.SendSignal)))
+	mux.HandleFunc("/signals", WrapHandler(temporalClient.ListSignals))
+	mux.HandleFunc("/games/history", WrapHandler(temporalClient.GamesHistory))
+	mux.HandleFunc("/game/{id}", WrapHandler(temporalClient.DescribeGame))
+	mux.HandleFunc("/pause/{id}", WrapHandler(temporalClient.Pause))
+	mux.HandleFunc("/resume/{id}", WrapHandler(temporalClient.Resume))
+	mux.HandleFunc("/stats/{id}", WrapHandler(temporalClient.GetStats))
+	mux.HandleFunc("/history/{id}", WrapHandler(temporalClient.HistorySize))
+	mux.HandleFunc("/metrics", WrapHandler(temporalClient.Metrics))
+	mux.HandleFunc("/ws/{id}", WrapHandler(temporalClient.WebSocket(signalLimiter)))
+	mux.HandleFunc("/render/{file}", WrapHandler(temporalClient.Render))
+	mux.HandleFunc("/scrollback/{id}", WrapHandler(temporalClient.Scrollback))
+	mux.HandleFunc("/archive/{id}", WrapHandler(temporalClient.GetArchive))
+	mux.HandleFunc("/snapshot/{id}", WrapHandler(temporalClient.Snapshot))
+	mux.HandleFunc("/debug/{id}", WrapHandler(temporalClient.Debug))
+	mux.HandleFunc("/resync/{id}", WrapHandler(temporalClient.Resync))
+	mux.HandleFunc("/restore", WrapHandler(temporalClient.Restore))
+	mux.HandleFunc("/scenario", WrapHandler(temporalClient.Scenario))
+	mux.HandleFunc("/record", WrapHandler(temporalClient.Record))
 }
 
+// WrapHandler applies CORS to the handler, answering preflight requests without calling it
 func WrapHandler(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		origin := r.Header.Get("Origin")
+		allowed, ok := allowOrigin(origin)
+		if !ok {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("origin %s is not allowed", origin))
+			return
+		}
+		if allowed != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+			if allowed != "*" {
+				w.Header().Add("Vary", "Origin")
+			}
+		}
+
+		// Preflight for non-simple requests (e.g. custom headers)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
 		http.HandlerFunc(handler).ServeHTTP(w, r)
 	}
 }
+
+// allowOrigin returns the Access-Control-Allow-Origin value for the request origin
+// and false when the origin isn't allowed. Same origin requests (no Origin header) are always allowed.
+func allowOrigin(origin string) (string, bool) {
+	for _, allowed := range config.AllowedOrigins {
+		if allowed == "*" {
+			return "*", true
+		}
+		if origin != "" && strings.TrimSpace(allowed) == origin {
+			return origin, true
+		}
+	}
+	return "", origin == ""
+}
+
+// writeJSON writes the payload as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// writeError writes a JSON error envelope like {"error": "..."} with the given status
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"backend/gol"
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownEndsStateStreams(t *testing.T) {
+	// A fresh full frame lets /state serve the game from the snapshot cache without temporal
+	gol.StateStream.Publish("shutdown", gol.StateChange{Id: "shutdown", Full: true, Rows: 2, Cols: 2, Flipped: [][2]int{{0, 0}}})
+
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(&fakeTemporal{}), mux)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newHTTPServer(ctx, "127.0.0.1:0", mux)
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	response, err := http.Get("http://" + listener.Addr().String() + "/state/shutdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	// Wait for the initial frame so the stream is in flight
+	reader := bufio.NewReader(response.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended before the initial frame: %v", err)
+		}
+		if strings.HasPrefix(line, "data: ") {
+			break
+		}
+	}
+
+	cancel()
+	ended := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, reader)
+		ended <- err
+	}()
+	select {
+	case err := <-ended:
+		if err != nil {
+			t.Fatalf("stream ended with %v, want a clean end", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream still open after the shutdown context was cancelled")
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Second)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("serve returned %v, want %v", err, http.ErrServerClosed)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	withConfig(t, func(config *Config) {
+		config.AllowedOrigins = []string{"https://gol.example", "http://localhost:5173"}
+	})
+	called := 0
+	handler := WrapHandler(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name         string
+		method       string
+		origin       string
+		preflight    bool
+		status       int
+		allowOrigin  string
+		handlerCalls int
+	}{
+		{"preflight", http.MethodOptions, "https://gol.example", true, http.StatusNoContent, "https://gol.example", 0},
+		{"allowed origin", http.MethodPost, "http://localhost:5173", false, http.StatusOK, "http://localhost:5173", 1},
+		{"same origin", http.MethodGet, "", false, http.StatusOK, "", 1},
+		{"disallowed origin", http.MethodPost, "https://evil.example", false, http.StatusForbidden, "", 0},
+		{"disallowed preflight", http.MethodOptions, "https://evil.example", true, http.StatusForbidden, "", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			called = 0
+			request := httptest.NewRequest(test.method, "/signal", nil)
+			if test.origin != "" {
+				request.Header.Set("Origin", test.origin)
+			}
+			if test.preflight {
+				request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				request.Header.Set("Access-Control-Request-Headers", "Content-Type, X-Trace-Id")
+			}
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Errorf("status = %d, want %d", recorder.Code, test.status)
+			}
+			if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != test.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, test.allowOrigin)
+			}
+			if called != test.handlerCalls {
+				t.Errorf("handler called %d times, want %d", called, test.handlerCalls)
+			}
+			if test.status == http.StatusNoContent {
+				if got := recorder.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, http.MethodPost) {
+					t.Errorf("Access-Control-Allow-Methods = %q, want it to allow POST", got)
+				}
+				if got := recorder.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, X-Trace-Id" {
+					t.Errorf("Access-Control-Allow-Headers = %q", got)
+				}
+				if got := recorder.Header().Get("Vary"); got != "Origin" {
+					t.Errorf("Vary = %q, want Origin", got)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSWildcardByDefault(t *testing.T) {
+	withConfig(t, func(config *Config) { config.AllowedOrigins = DefaultConfig().AllowedOrigins })
+	handler := WrapHandler(func(w http.ResponseWriter, r *http.Request) {})
+
+	request := httptest.NewRequest(http.MethodGet, "/state", nil)
+	request.Header.Set("Origin", "http://anywhere.example")
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+	if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+}
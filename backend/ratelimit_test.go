@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+func TestSignalRateLimit(t *testing.T) {
+	withConfig(t, func(config *Config) {
+		config.SignalRate = 0.001
+		config.SignalBurst = 3
+	})
+	signals := map[string]int{}
+	fake := &fakeTemporal{
+		signalWorkflow: func(ctx context.Context, id string, signalName string, arg any) error {
+			signals[id]++
+			return nil
+		},
+		listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+			return nil, nil
+		},
+		executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+			return nil, nil
+		},
+	}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	serve := func(method, path, remoteAddr string) *httptest.ResponseRecorder {
+		body := ""
+		if strings.HasPrefix(path, "/signal/") {
+			body = `{"x": 1, "y": 2, "size": 3}`
+		}
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// The burst goes through, the signals after it are turned away
+	for i := range 6 {
+		response := serve(http.MethodPost, "/signal/spammed/splatter", "192.0.2.1:1234")
+		want := http.StatusOK
+		if i >= 3 {
+			want = http.StatusTooManyRequests
+		}
+		if response.Code != want {
+			t.Errorf("signal %d responded %d, want %d", i+1, response.Code, want)
+		}
+		if response.Code == http.StatusTooManyRequests && response.Header().Get("Retry-After") == "" {
+			t.Error("429 without a Retry-After")
+		}
+	}
+	if signals["spammed"] != 3 {
+		t.Errorf("%d signals reached the game, want the burst of 3", signals["spammed"])
+	}
+
+	// Other games, other clients and the other endpoints have their own budget
+	for _, request := range []struct{ method, path, remoteAddr string }{
+		{http.MethodPost, "/signal/other/splatter", "192.0.2.1:1234"},
+		{http.MethodPost, "/signal/spammed/splatter", "192.0.2.2:1234"},
+		{http.MethodPost, "/start?wait=false&reuse=terminate&id=spammed", "192.0.2.1:1234"},
+		{http.MethodGet, "/signals", "192.0.2.1:1234"},
+	} {
+		if response := serve(request.method, request.path, request.remoteAddr); response.Code != http.StatusOK {
+			t.Errorf("%s %s from %s responded %d: %s", request.method, request.path, request.remoteAddr, response.Code, response.Body)
+		}
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	limiter := NewRateLimiter(1000, 1)
+	if !limiter.Allow("key") || limiter.Allow("key") {
+		t.Fatal("a burst of 1 didn't allow exactly one request")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if !limiter.Allow("key") {
+		t.Error("the bucket didn't refill")
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"backend/gol"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.temporal.io/sdk/client"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                 Record Run                                 */
+/* -------------------------------------------------------------------------- */
+
+// A recording computes a run's generations back to back on a worker (see gol.RecordRun) and
+// responds with their diffs, for exporting a run offline. A response with truncated set
+// stopped early to stay within temporal's payload limit, posting its final board as the next
+// request's board records the rest.
+
+// Body of /record, the seed board is packed like the final board of the response
+type RecordRequest struct {
+	Board        *gol.PackedBoard `json:"board"`
+	Steps        int              `json:"steps"`
+	Rule         string           `json:"rule,omitempty"` // Rulestring like B36/S23
+	Neighborhood gol.Neighborhood `json:"neighborhood,omitempty"`
+	Wrap         bool             `json:"wrap,omitempty"`
+}
+
+// Record records a run from the posted seed and responds with a gol.RecordOutput
+// Url is /record
+func (c *TemporalClient) Record(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "record with a POST")
+		return
+	}
+
+	var request RecordRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxStartBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid record request: %v", err))
+		return
+	}
+	input := gol.RecordInput{
+		Board:        request.Board,
+		Steps:        request.Steps,
+		Rule:         request.Rule,
+		Neighborhood: request.Neighborhood,
+		Wrap:         request.Wrap,
+	}
+	if err := input.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Temporal picks the id, a recording isn't a game
+	run, err := c.ExecuteWorkflow(r.Context(), client.StartWorkflowOptions{TaskQueue: c.taskQueue}, gol.RecordRunWorkflow, input)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var output gol.RecordOutput
+	if err := run.Get(r.Context(), &output); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
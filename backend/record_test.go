@@ -0,0 +1,87 @@
+package main
+
+import (
+	"backend/gol"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.temporal.io/sdk/client"
+)
+
+func TestRecordEndpoint(t *testing.T) {
+	glider := gol.Board{
+		{false, false, false, false, false, false},
+		{false, false, true, false, false, false},
+		{false, false, false, true, false, false},
+		{false, true, true, true, false, false},
+		{false, false, false, false, false, false},
+		{false, false, false, false, false, false},
+	}
+
+	// The fake worker records the run for real
+	var recordings int
+	fake := &fakeTemporal{executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+		recordings++
+		if options.ID != "" || options.TaskQueue != "test" {
+			t.Errorf("recording started with %+v", options)
+		}
+		return gol.AmInstance.RecordRun(ctx, args[0].(gol.RecordInput))
+	}}
+	temporalClient := newTestClient(fake)
+	record := func(method string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		temporalClient.Record(recorder, httptest.NewRequest(method, "/record", strings.NewReader(body)))
+		return recorder
+	}
+
+	board, err := json.Marshal(glider.Pack())
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := record(http.MethodPost, fmt.Sprintf(`{"board": %s, "steps": 4, "wrap": true}`, board))
+	if response.Code != http.StatusOK {
+		t.Fatalf("recording responded %d: %s", response.Code, response.Body)
+	}
+	var output gol.RecordOutput
+	if err := json.Unmarshal(response.Body.Bytes(), &output); err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Frames) != 4 || output.Truncated {
+		t.Fatalf("recorded %d frames (truncated %v), want 4", len(output.Frames), output.Truncated)
+	}
+	// Four generations on the glider is the same glider a cell down and right
+	final := output.Final.Unpack()
+	for row := range glider {
+		for col := range glider[row] {
+			if final[(row+1)%6][(col+1)%6] != glider[row][col] {
+				t.Fatalf("final board\n%v isn't the glider moved diagonally", final)
+			}
+		}
+	}
+
+	// Bad requests never reach temporal
+	for _, bad := range []struct {
+		method string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "not json", http.StatusBadRequest},
+		{http.MethodPost, fmt.Sprintf(`{"board": %s, "steps": 4, "speed": 2}`, board), http.StatusBadRequest},
+		{http.MethodPost, fmt.Sprintf(`{"board": %s, "steps": 0}`, board), http.StatusBadRequest},
+		{http.MethodPost, fmt.Sprintf(`{"board": %s, "steps": 4, "rule": "conway"}`, board), http.StatusBadRequest},
+		{http.MethodPost, `{"steps": 4}`, http.StatusBadRequest},
+	} {
+		if response := record(bad.method, bad.body); response.Code != bad.status {
+			t.Errorf("%s %q responded %d, want %d", bad.method, bad.body, response.Code, bad.status)
+		}
+	}
+	if recordings != 1 {
+		t.Errorf("started %d recordings, want 1", recordings)
+	}
+}
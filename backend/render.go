@@ -0,0 +1,94 @@
+package main
+
+import (
+	"backend/gol"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.temporal.io/api/serviceerror"
+)
+
+// Biggest side of a rendered image in pixels, a 512x512 board renders up to scale 8
+const maxRenderSize = 4096
+
+// Render serves the game's current board as a PNG, live cells white and dead cells black
+// Url is like /render/{id}.png?scale=N where each cell becomes an NxN square (defaults to 1)
+func (c *TemporalClient) Render(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutSuffix(r.PathValue("file"), ".png")
+	if !ok || id == "" {
+		writeError(w, http.StatusNotFound, "render urls look like /render/{id}.png")
+		return
+	}
+
+	scale := 1
+	if value := r.URL.Query().Get("scale"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("scale must be a positive integer, got %q", value))
+			return
+		}
+		scale = parsed
+	}
+
+	boardEnvelope, err := c.queryAcrossRollover(r.Context(), id, "fullBoard")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var fullBoard gol.FullBoard
+	if err := boardEnvelope.Get(&fullBoard); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if fullBoard.Board == nil {
+		writeError(w, http.StatusInternalServerError, "game returned no board")
+		return
+	}
+	board := fullBoard.Board
+
+	if board.Rows*scale > maxRenderSize || board.Cols*scale > maxRenderSize {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("a %dx%d board at scale %d is bigger than %d pixels", board.Rows, board.Cols, scale, maxRenderSize))
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := png.Encode(w, RenderBoard(board.Unpack(), scale)); err != nil {
+		log.Printf("Error encoding render of %s: %v", id, err)
+	}
+}
+
+// RenderBoard draws the board with each cell as a scale x scale square, rows top to bottom
+func RenderBoard(board gol.Board, scale int) *image.Gray {
+	rows, cols := len(board), 0
+	if rows > 0 {
+		cols = len(board[0])
+	}
+
+	img := image.NewGray(image.Rect(0, 0, cols*scale, rows*scale))
+	for i, row := range board {
+		for j, alive := range row {
+			if !alive {
+				continue
+			}
+			for y := i * scale; y < (i+1)*scale; y++ {
+				for x := j * scale; x < (j+1)*scale; x++ {
+					img.SetGray(x, y, color.Gray{Y: 255})
+				}
+			}
+		}
+	}
+	return img
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"backend/gol"
+	"context"
+	"fmt"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRenderPNG(t *testing.T) {
+	board := make(gol.Board, 4)
+	for i := range board {
+		board[i] = make([]bool, 6)
+	}
+	board.Toggle([][2]int{{1, 4}})
+	fake := &fakeTemporal{queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+		if queryType != "fullBoard" {
+			return nil, fmt.Errorf("unexpected %s query", queryType)
+		}
+		return gol.FullBoard{Id: id, Board: board.Pack()}, nil
+	}}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+
+	for _, scale := range []int{1, 3} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/render/game.png?scale=%d", scale), nil))
+		if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "image/png" {
+			t.Fatalf("scale %d responded %d with %s: %s", scale, recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body)
+		}
+		img, err := png.Decode(recorder.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size := img.Bounds().Size(); size.X != 6*scale || size.Y != 4*scale {
+			t.Errorf("scale %d rendered %v, want %dx%d", scale, size, 6*scale, 4*scale)
+		}
+
+		// Every pixel of the live cell at row 1, column 4 is white, the ones next to it black
+		white := color.GrayModel.Convert(color.White)
+		for y := scale; y < 2*scale; y++ {
+			for x := 4 * scale; x < 5*scale; x++ {
+				if got := color.GrayModel.Convert(img.At(x, y)); got != white {
+					t.Errorf("scale %d: live pixel (%d, %d) is %v", scale, x, y, got)
+				}
+			}
+		}
+		if got := color.GrayModel.Convert(img.At(3*scale, scale)); got == white {
+			t.Errorf("scale %d: the dead cell next to it is white", scale)
+		}
+	}
+
+	for _, path := range []string{"/render/game.png?scale=0", "/render/game.png?scale=1000"} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s responded %d, want 400", path, recorder.Code)
+		}
+	}
+}
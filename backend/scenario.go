@@ -0,0 +1,140 @@
+package main
+
+import (
+	"backend/gol"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                  Scenarios                                 */
+/* -------------------------------------------------------------------------- */
+
+// A scenario replaces a game with a fresh one in a single call (the frontend's "new scenario"
+// button). The old game is terminated before the new one's stream is subscribed to, so a frame
+// it was still publishing isn't taken for the new game's first frame, and the response only
+// comes once the new game is streaming.
+
+// Body of /scenario, zero values keep the defaults of a new game
+type ScenarioRequest struct {
+	Rule          string                 `json:"rule,omitempty"` // Rulestring like B36/S23
+	Neighborhood  gol.Neighborhood       `json:"neighborhood,omitempty"`
+	Rows          int                    `json:"rows,omitempty"` // Size of the random board, both or neither have to be set
+	Cols          int                    `json:"cols,omitempty"`
+	Wrap          bool                   `json:"wrap,omitempty"`
+	Checksums     bool                   `json:"checksums,omitempty"`    // Frames carry a checksum of the board
+	AutoRestart   bool                   `json:"autoRestart,omitempty"`  // Reseed the board when it dies out instead of ending the game
+	RestartAfter  int                    `json:"restartAfter,omitempty"` // Steps it stays empty first
+	TeamGame      bool                   `json:"teamGame,omitempty"`     // Two teams, the left and right half of the board
+	SplitFlips    bool                   `json:"splitFlips,omitempty"`   // Frames also split the flipped cells into born and died
+	RevealSteps   int                    `json:"revealSteps,omitempty"`  // Frames uncovering the board before it starts running
+	Range         int                    `json:"range,omitempty"`        // Larger than Life radius, replaces the rule's counts with the ranges below
+	RangeBirth    gol.CountRange         `json:"rangeBirth"`
+	RangeSurvival gol.CountRange         `json:"rangeSurvival"`
+	TickTime      time.Duration          `json:"tickTime,omitempty"`
+	TicksPerFrame int                    `json:"ticksPerFrame,omitempty"` // Generations per streamed frame, for fast games
+	RandomBoard   gol.RandomBoardOptions `json:"randomBoard"`             // Fill, density and seed of the board
+}
+
+// Validate checks everything the workflow would otherwise only reject once started
+func (s ScenarioRequest) Validate() error {
+	if s.Rule != "" {
+		if _, _, err := gol.ParseRule(s.Rule); err != nil {
+			return err
+		}
+	}
+	if err := s.Neighborhood.Validate(); err != nil {
+		return err
+	}
+	if s.Rows != 0 || s.Cols != 0 {
+		if err := gol.ValidateBoardSize(s.Rows, s.Cols); err != nil {
+			return err
+		}
+	}
+	if err := gol.ValidateRange(s.Range, s.RangeBirth, s.RangeSurvival); err != nil {
+		return err
+	}
+	if s.TeamGame && s.Range > 0 {
+		return fmt.Errorf("team games don't work with range")
+	}
+	if err := gol.ValidateRevealSteps(s.RevealSteps); err != nil {
+		return err
+	}
+	if err := gol.ValidateTicksPerFrame(s.TicksPerFrame); err != nil {
+		return err
+	}
+	if s.RestartAfter < 0 {
+		return fmt.Errorf("restart after must not be negative, got %d", s.RestartAfter)
+	}
+	if s.TickTime < 0 {
+		return fmt.Errorf("tick time must not be negative, got %v", s.TickTime)
+	}
+	return s.RandomBoard.Validate()
+}
+
+// Input returns the workflow input of the scenario's game
+func (s ScenarioRequest) Input() gol.GameOfLifeInput {
+	return gol.GameOfLifeInput{
+		Rule:          s.Rule,
+		Neighborhood:  s.Neighborhood,
+		Rows:          s.Rows,
+		Cols:          s.Cols,
+		Wrap:          s.Wrap,
+		Checksums:     s.Checksums,
+		AutoRestart:   s.AutoRestart,
+		RestartAfter:  s.RestartAfter,
+		TeamGame:      s.TeamGame,
+		SplitFlips:    s.SplitFlips,
+		RevealSteps:   s.RevealSteps,
+		Range:         s.Range,
+		RangeBirth:    s.RangeBirth,
+		RangeSurvival: s.RangeSurvival,
+		TickTime:      s.TickTime,
+		TicksPerFrame: s.TicksPerFrame,
+		RandomBoard:   s.RandomBoard,
+	}
+}
+
+// Scenario replaces the game with a new one started from the ScenarioRequest in the body
+// Url is like /scenario?id={id}, the id defaults to the shared game
+// Responds like /start once the new game published its first frame
+func (c *TemporalClient) Scenario(w http.ResponseWriter, r *http.Request) {
+	id := GameOfLifeId
+	if queryId := r.URL.Query().Get("id"); queryId != "" {
+		id = queryId
+	}
+
+	var request ScenarioRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxStartBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid scenario: %v", err))
+		return
+	}
+	if err := request.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid scenario: %v", err))
+		return
+	}
+
+	// Only a running game can be terminated, there is nothing to replace otherwise
+	err := c.TerminateWorkflow(r.Context(), id, "", "replaced by a new scenario")
+	var notFound *serviceerror.NotFound
+	if err != nil && !errors.As(err, &notFound) {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// A game started in between is terminated by the reuse policy
+	c.startAndWait(w, r, client.StartWorkflowOptions{
+		ID:                    id,
+		TaskQueue:             c.taskQueue,
+		WorkflowIDReusePolicy: enums.WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING,
+	}, request.Input())
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"backend/gol"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+func TestScenarioReplacesTheGame(t *testing.T) {
+	var calls []string
+	var options client.StartWorkflowOptions
+	var input gol.GameOfLifeInput
+	fake := &fakeTemporal{
+		terminateWorkflow: func(ctx context.Context, id string, reason string) error {
+			calls = append(calls, "terminate "+id)
+			return nil
+		},
+		listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+			return nil, nil
+		},
+		executeWorkflow: func(ctx context.Context, startOptions client.StartWorkflowOptions, args []any) (any, error) {
+			calls = append(calls, "start "+startOptions.ID)
+			options, input = startOptions, args[0].(gol.GameOfLifeInput)
+			gol.StateStream.Publish(startOptions.ID, gol.StateChange{Id: startOptions.ID, Seq: 1, Full: true})
+			return nil, nil
+		},
+	}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+
+	body := `{"rule": "B36/S23", "neighborhood": 1, "rows": 64, "cols": 96, "wrap": true, "tickTime": 50000000, "randomBoard": {"seed": 7, "fill": "uniform"}}`
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/scenario?id=demo", strings.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("scenario responded %d: %s", recorder.Code, recorder.Body)
+	}
+	if started := decodeBody(t, recorder); started["id"] != "demo" {
+		t.Errorf("scenario started %v", started)
+	}
+
+	// The old game goes first, then the new one starts with the scenario's config
+	if strings.Join(calls, ", ") != "terminate demo, start demo" {
+		t.Errorf("calls were %v", calls)
+	}
+	if options.WorkflowIDReusePolicy != enums.WORKFLOW_ID_REUSE_POLICY_TERMINATE_IF_RUNNING {
+		t.Errorf("started with reuse policy %v", options.WorkflowIDReusePolicy)
+	}
+	if input.Rule != "B36/S23" || input.Neighborhood != gol.VonNeumann || input.Rows != 64 || input.Cols != 96 || !input.Wrap ||
+		input.TickTime != 50*time.Millisecond || input.RandomBoard.Seed != 7 || input.RandomBoard.Fill != gol.FillUniform {
+		t.Errorf("new game's input is %+v", input)
+	}
+}
+
+func TestScenarioWithoutARunningGame(t *testing.T) {
+	started := false
+	fake := &fakeTemporal{
+		terminateWorkflow: func(ctx context.Context, id string, reason string) error {
+			return serviceerror.NewNotFound("no running game")
+		},
+		listWorkflow: func(ctx context.Context, query string) ([]string, error) {
+			return nil, nil
+		},
+		executeWorkflow: func(ctx context.Context, options client.StartWorkflowOptions, args []any) (any, error) {
+			started = true
+			return nil, nil
+		},
+	}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+
+	for body, want := range map[string]int{
+		`{"rule": "conway"}`:          http.StatusBadRequest,
+		`{"rows": 100000, "cols": 1}`: http.StatusBadRequest,
+		`{"tickTime": 1}`:             http.StatusOK,
+	} {
+		started = false
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/scenario?id=fresh&wait=false", strings.NewReader(body)))
+		if recorder.Code != want || started != (want == http.StatusOK) {
+			t.Errorf("scenario %s responded %d and started %v, want %d", body, recorder.Code, started, want)
+		}
+	}
+}
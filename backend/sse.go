@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// sseStream writes server-sent events with a deadline on each one, so a client that stopped
+// reading (e.g. a dead connection behind a proxy) fails the write instead of blocking forever
+// In NDJSON mode every frame is a bare JSON line instead, for consumers that don't speak SSE
+type sseStream struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	ndjson     bool
+}
+
+// newSSEStream sets the stream headers, only call it once nothing can fail with a JSON error
+// The returned function clears the deadline again once the handler is done
+func newSSEStream(w http.ResponseWriter, ndjson bool) (*sseStream, func()) {
+	if ndjson {
+		w.Header().Set("Content-Type", ndjsonContentType)
+	} else {
+		w.Header().Set("Content-Type", "text/event-stream")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	controller := http.NewResponseController(w)
+	return &sseStream{w: w, controller: controller, ndjson: ndjson}, func() {
+		controller.SetWriteDeadline(time.Time{})
+	}
+}
+
+// acceptsNDJSON reports whether the request asked for NDJSON instead of SSE
+func acceptsNDJSON(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && mediaType == ndjsonContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Frame sends a frame tagged with its id (the Seq of state frames, the step of stats frames),
+// event names the SSE event and is empty for plain frames
+func (s *sseStream) Frame(event string, id int, data []byte) error {
+	switch {
+	case s.ndjson:
+		return s.Send("%s\n", data)
+	case event != "":
+		return s.Send("event: %s\nid: %d\ndata: %s\n\n", event, id, data)
+	}
+	return s.Send("id: %d\ndata: %s\n\n", id, data)
+}
+
+// Event sends an event without data (e.g. a ping), NDJSON streams only carry frames so it is skipped
+func (s *sseStream) Event(name string) error {
+	if s.ndjson {
+		return nil
+	}
+	return s.Send("event: %s\n\n", name)
+}
+
+// Send writes and flushes an event, failing once the client is gone or too slow to take it
+func (s *sseStream) Send(format string, args ...any) error {
+	// Not every writer supports deadlines (e.g. in tests), those just write without one
+	s.controller.SetWriteDeadline(time.Now().Add(config.SSEWriteTimeout))
+	if _, err := fmt.Fprintf(s.w, format, args...); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
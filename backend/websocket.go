@@ -0,0 +1,149 @@
+package main
+
+import (
+	"backend/gol"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+	"golang.org/x/net/websocket"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                 WebSocket                                  */
+/* -------------------------------------------------------------------------- */
+
+// The WebSocket transport streams the same frames as the SSE one and also takes signals
+// from the client, so a frontend only needs a single connection. Every outbound message
+// is either a gol.StateChange or an error envelope like {"error": "..."} answering a bad
+// inbound message. Inbound messages take their tokens from the same limiter as the /signal
+// endpoints, so neither more sockets nor reconnecting raise a client's signal rate.
+
+// How long a single outbound message may take before the client counts as gone
+const webSocketWriteTimeout = 10 * time.Second
+
+// Inbound message sending the named signal (see gol.Signals) to the game
+type WebSocketMessage struct {
+	Signal  string          `json:"signal"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// WebSocket streams the game's state over a WebSocket and forwards inbound signals to it
+// Signals are limited by the limiter shared with the /signal endpoints
+// Url is like /ws/{id}
+func (c *TemporalClient) WebSocket(limiter *RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c.serveWebSocket(w, r, limiter)
+	}
+}
+
+func (c *TemporalClient) serveWebSocket(w http.ResponseWriter, r *http.Request, limiter *RateLimiter) {
+	id := r.PathValue("id")
+
+	// Fail with a JSON error before upgrading when the game doesn't exist
+	stateChange, states, unsubscribe, err := c.subscribeToGame(r.Context(), id)
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("game %s not found", id))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer unsubscribe()
+
+	server := websocket.Server{
+		// WrapHandler already checked the origin
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			send := func(message any) error {
+				ws.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+				return websocket.JSON.Send(ws, message)
+			}
+
+			// The reader ends the stream once the client disconnects
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				c.receiveSignals(ws, id, send, limiter)
+			}()
+
+			// Send the initial state because on initial connection we need the full object
+			if err := send(stateChange); err != nil {
+				return
+			}
+			for {
+				select {
+				case <-done:
+					return
+				case state := <-states:
+					// Skip what the client's board already includes
+					state, ok, err := c.frameAfter(r.Context(), id, stateChange.Seq, state)
+					if err != nil {
+						log.Printf("Error querying board of game %s: %v", id, err)
+						return
+					}
+					if !ok {
+						continue
+					}
+					stateChange = state
+					sent := time.Now()
+					if err := send(state); err != nil {
+						return
+					}
+					gol.StateStream.ObserveSend(id, states, time.Since(sent))
+				}
+			}
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// receiveSignals forwards the client's messages to the game until the connection closes
+// Bad messages are answered with an error and don't close the connection
+func (c *TemporalClient) receiveSignals(ws *websocket.Conn, id string, send func(any) error, limiter *RateLimiter) {
+	for {
+		var message WebSocketMessage
+		if err := websocket.JSON.Receive(ws, &message); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				send(map[string]string{"error": err.Error()})
+				continue
+			}
+			return
+		}
+
+		if err := c.forwardSignal(ws.Request(), id, message, limiter); err != nil {
+			if send(map[string]string{"error": err.Error()}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// forwardSignal validates the message like SendSignal does and signals the game
+func (c *TemporalClient) forwardSignal(r *http.Request, id string, message WebSocketMessage, limiter *RateLimiter) error {
+	if !limiter.Allow(signalKey(r)) {
+		return errors.New("too many signals, slow down")
+	}
+
+	spec, ok := gol.Signals[message.Signal]
+	if !ok {
+		return fmt.Errorf("unknown signal %q, valid signals are: %s", message.Signal, strings.Join(gol.SignalNames(), ", "))
+	}
+	payload, err := spec.DecodePayload(bytes.NewReader(message.Payload))
+	if err != nil {
+		return err
+	}
+	return c.SignalWorkflow(r.Context(), id, "", message.Signal, payload)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"backend/gol"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketToggleRoundTrip(t *testing.T) {
+	game := newScriptedGame("socket", 1)
+	fake := &fakeTemporal{
+		queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+			if queryType != "board" {
+				return nil, fmt.Errorf("unexpected %s query", queryType)
+			}
+			return game.board(1), nil
+		},
+		// The game answers a toggle with the diff flipping the cell
+		signalWorkflow: func(ctx context.Context, id string, signalName string, arg any) error {
+			toggle, ok := arg.(*gol.BatchToggleSignal)
+			if signalName != gol.BatchToggleSignalName || !ok {
+				return fmt.Errorf("unexpected %s signal %v", signalName, arg)
+			}
+			gol.StateStream.Publish(id, gol.StateChange{Id: id, Seq: 2, Step: 1, Flipped: toggle.Cells})
+			return nil
+		},
+	}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/socket", "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var state gol.StateChange
+	if err := websocket.JSON.Receive(ws, &state); err != nil {
+		t.Fatal(err)
+	}
+	if !state.Full || state.Seq != 1 {
+		t.Fatalf("initial frame is %+v, want the full board as of frame 1", state)
+	}
+
+	// A bad message is answered with an error and leaves the connection open
+	if err := websocket.JSON.Send(ws, WebSocketMessage{Signal: "clear"}); err != nil {
+		t.Fatal(err)
+	}
+	var reply map[string]string
+	if err := websocket.JSON.Receive(ws, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reply["error"], "unknown signal") {
+		t.Errorf("unknown signal answered %v", reply)
+	}
+
+	if err := websocket.JSON.Send(ws, WebSocketMessage{Signal: gol.BatchToggleSignalName, Payload: []byte(`{"cells": [[5, 7]]}`)}); err != nil {
+		t.Fatal(err)
+	}
+	var diff gol.StateChange
+	if err := websocket.JSON.Receive(ws, &diff); err != nil {
+		t.Fatal(err)
+	}
+	if diff.Full || diff.Seq != 2 || fmt.Sprint(diff.Flipped) != "[[5 7]]" {
+		t.Errorf("toggle came back as %+v, want the diff flipping [5 7]", diff)
+	}
+}
+
+func TestWebSocketSignalsShareTheSignalRateLimit(t *testing.T) {
+	withConfig(t, func(config *Config) {
+		config.SignalRate = 0.001
+		config.SignalBurst = 3
+	})
+	game := newScriptedGame("limited socket", 1)
+	var signals atomic.Int32
+	fake := &fakeTemporal{
+		queryWorkflow: func(ctx context.Context, id string, runId string, queryType string) (any, error) {
+			return game.board(1), nil
+		},
+		// The game answers a toggle with its diff, so every signal that got through has a reply
+		signalWorkflow: func(ctx context.Context, id string, signalName string, arg any) error {
+			seq := signals.Add(1) + 1
+			if toggle, ok := arg.(*gol.BatchToggleSignal); ok {
+				gol.StateStream.Publish(id, gol.StateChange{Id: id, Seq: int(seq), Step: 1, Flipped: toggle.Cells})
+			}
+			return nil
+		},
+	}
+	mux := http.NewServeMux()
+	handleEndpoints(newTestClient(fake), mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// toggle opens a socket, sends a toggle on it and returns the socket with its reply
+	toggle := func() (*websocket.Conn, map[string]any) {
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/limited%20socket", "", server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var initial, reply map[string]any
+		if err := websocket.JSON.Receive(ws, &initial); err != nil {
+			t.Fatal(err)
+		}
+		if err := websocket.JSON.Send(ws, WebSocketMessage{Signal: gol.BatchToggleSignalName, Payload: []byte(`{"cells": [[1, 1]]}`)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := websocket.JSON.Receive(ws, &reply); err != nil {
+			t.Fatal(err)
+		}
+		return ws, reply
+	}
+
+	// One signal over HTTP and one on a socket that stays open
+	response, err := http.Post(server.URL+"/signal/limited%20socket/splatter", "application/json", strings.NewReader(`{"x": 1, "y": 2, "size": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("HTTP signal responded %d", response.StatusCode)
+	}
+	open, reply := toggle()
+	defer open.Close()
+	if reply["error"] != nil {
+		t.Fatalf("first socket's toggle answered %v", reply)
+	}
+
+	// A second socket takes the last token, reconnecting after it doesn't bring a new budget
+	second, reply := toggle()
+	second.Close()
+	if reply["error"] != nil {
+		t.Fatalf("second socket's toggle answered %v", reply)
+	}
+	reconnected, reply := toggle()
+	defer reconnected.Close()
+	if message, _ := reply["error"].(string); !strings.Contains(message, "too many signals") {
+		t.Errorf("signal past the burst answered %v, want it turned away", reply)
+	}
+	if got := signals.Load(); got != 3 {
+		t.Errorf("%d signals reached the game, want the burst of 3", got)
+	}
+}
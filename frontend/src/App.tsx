@@ -30,9 +30,13 @@ import {
  * Start a new workflow (game) on the backend
  */
 const startWorkflow = async () => {
-  const response = await fetch(`${import.meta.env.VITE_BACKEND}/start`, {
-    method: "POST",
-  });
+  // Starting over replaces the running game
+  const response = await fetch(
+    `${import.meta.env.VITE_BACKEND}/start?reuse=terminate`,
+    {
+      method: "POST",
+    },
+  );
 
   const data = await response.text();
 
@@ -76,6 +80,7 @@ export default function App() {
   const tick = useRef(false);
 
   const board = useRef<Uint8Array>(null);
+  const walls = useRef<Uint8Array>(new Uint8Array(TOTAL));
   const canvas = useRef<HTMLCanvasElement | null>(null);
   const eventSource = useRef<EventSource | null>(null);
 
@@ -97,6 +102,13 @@ export default function App() {
     // Fast pixel buffer fill
     let i = 0;
     for (let j = 0; j < TOTAL; j++) {
+      if (walls.current[j]) {
+        data[i++] = 60; // R
+        data[i++] = 58; // G
+        data[i++] = 70; // B
+        data[i++] = 255; // A
+        continue;
+      }
       data[i++] = board.current[j] ? 142 : 255; // R
       data[i++] = board.current[j] ? 140 : 255; // G
       data[i++] = board.current[j] ? 153 : 255; // B
@@ -153,8 +165,8 @@ export default function App() {
       setPaused(false);
     });
 
-    // Listen for state updates
-    eventSource.current.addEventListener("message", (event) => {
+    // Apply a state update to the local board
+    const handleState = (event: MessageEvent) => {
       if (!board.current) return;
 
       tick.current = false;
@@ -163,6 +175,8 @@ export default function App() {
         step: number;
         flipped: [number, number][] | null;
         paused: boolean;
+        full?: boolean;
+        walls?: [number, number][];
       };
 
       if (data.paused !== previousPaused.current) {
@@ -171,18 +185,48 @@ export default function App() {
         setToggling(false);
       }
 
-      if (!data.flipped) return;
+      if (!data.flipped && !data.full) return;
 
       setTime(data.step);
 
-      for (const [x, y] of data.flipped) {
-        const index = y * SIZE + x;
+      // Keyframes carry every live cell, so start from an empty board
+      if (data.full) {
+        board.current.fill(0);
+
+        // Walls only come with keyframes
+        walls.current.fill(0);
+        for (const [row, col] of data.walls ?? []) {
+          walls.current[row * SIZE + col] = 1;
+        }
+      }
+
+      // Cells are [row, col], rows run top to bottom like the canvas
+      for (const [row, col] of data.flipped ?? []) {
+        const index = row * SIZE + col;
         board.current[index] = board.current[index] ? 0 : 1;
       }
 
       setPopulation(board.current.reduce((a, b) => a + b, 0));
 
       paint();
+    };
+
+    // Listen for state updates
+    eventSource.current.addEventListener("message", handleState);
+
+    // Sent on reconnect when frames were missed, the board resets to the snapshot
+    eventSource.current.addEventListener("snapshot", (event) => {
+      board.current = new Uint8Array(TOTAL);
+      handleState(event);
+    });
+
+    // The game is over, close before the server does so it isn't treated as an error
+    eventSource.current.addEventListener("game_ended", () => {
+      eventSource.current?.close();
+
+      setRunning(false);
+      setLoading(false);
+      setToggling(false);
     });
 
     eventSource.current.addEventListener("open", () => {